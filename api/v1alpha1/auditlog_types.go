@@ -0,0 +1,34 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// AuditLogSpec writes the `FSNamesystem.audit` events of the namenodes as JSON lines
+// into a dedicated rolling file, which the vector agent ships as a separate source.
+type AuditLogSpec struct {
+	// MaxFileSize is the size of the audit log file before it is rolled, e.g. `10MB`.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^[1-9][0-9]*(KB|MB|GB)$`
+	// +kubebuilder:default:="10MB"
+	MaxFileSize string `json:"maxFileSize,omitempty"`
+
+	// MaxBackupIndex is the number of rolled audit log files kept next to the current one.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:default:=5
+	MaxBackupIndex int32 `json:"maxBackupIndex,omitempty"`
+}
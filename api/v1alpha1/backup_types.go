@@ -0,0 +1,84 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// LatestBackup restores from the newest backup found in the backup location.
+const LatestBackup = "latest"
+
+// BackupSpec defines a scheduled fsimage backup of the namenode metadata.
+// Exactly one of s3 or pvc must be set.
+// +kubebuilder:validation:XValidation:rule="has(self.s3) != has(self.pvc)",message="exactly one of s3 or pvc must be set"
+type BackupSpec struct {
+	// Schedule in cron format.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="0 2 * * *"
+	Schedule string `json:"schedule,omitempty"`
+
+	// Retention is the number of backups kept in the backup location.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default:=7
+	Retention int32 `json:"retention,omitempty"`
+
+	// Suspend the backup schedule, e.g. while restoring.
+	// +kubebuilder:validation:Optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	S3 *S3BackupSpec `json:"s3,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Pvc *PvcBackupSpec `json:"pvc,omitempty"`
+
+	// Restore makes unformatted namenodes bootstrap from a backup instead of `namenode -format`.
+	// +kubebuilder:validation:Optional
+	Restore *RestoreSpec `json:"restore,omitempty"`
+}
+
+type S3BackupSpec struct {
+	// Bucket is the name of a S3Bucket in the namespace of the cluster.
+	// +kubebuilder:validation:Required
+	Bucket string `json:"bucket"`
+
+	// Prefix is the key prefix in the bucket under which backups are stored.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="hdfs-backup"
+	Prefix string `json:"prefix,omitempty"`
+}
+
+type PvcBackupSpec struct {
+	// ClaimName is the name of an existing PersistentVolumeClaim.
+	// It is mounted by the backup job and, when restoring, by the namenode pods,
+	// so it should support ReadWriteMany or ReadOnlyMany access.
+	// +kubebuilder:validation:Required
+	ClaimName string `json:"claimName"`
+}
+
+type RestoreSpec struct {
+	// Backup is the name of the backup to restore, e.g. `20240101-020000`.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="latest"
+	Backup string `json:"backup,omitempty"`
+
+	// ClusterID overrides the cluster id recorded in the backup.
+	// +kubebuilder:validation:Optional
+	ClusterID string `json:"clusterId,omitempty"`
+
+	// BlockPoolID overrides the block pool id recorded in the backup.
+	// +kubebuilder:validation:Optional
+	BlockPoolID string `json:"blockPoolId,omitempty"`
+}
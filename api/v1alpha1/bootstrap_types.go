@@ -0,0 +1,66 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+type BootstrapSpec struct {
+	// FromImage bootstraps the first namenode from the metadata of an existing cluster
+	// instead of `namenode -format`, e.g. to migrate a bare-metal cluster into Kubernetes.
+	// +kubebuilder:validation:Optional
+	FromImage *FromImageSpec `json:"fromImage,omitempty"`
+}
+
+// FromImageSpec is the location of an existing namenode `current/` directory,
+// containing the `VERSION` file, the fsimage and the edits.
+// Exactly one of pvc, configMap or s3 must be set.
+// +kubebuilder:validation:XValidation:rule="[has(self.pvc), has(self.configMap), has(self.s3)].filter(x, x).size() == 1",message="exactly one of pvc, configMap or s3 must be set"
+type FromImageSpec struct {
+	// +kubebuilder:validation:Optional
+	Pvc *FsImagePvcSource `json:"pvc,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	ConfigMap *FsImageConfigMapSource `json:"configMap,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	S3 *FsImageS3Source `json:"s3,omitempty"`
+}
+
+type FsImagePvcSource struct {
+	// ClaimName is the name of an existing PersistentVolumeClaim.
+	// +kubebuilder:validation:Required
+	ClaimName string `json:"claimName"`
+
+	// Path is the directory in the volume which contains `current/`.
+	// +kubebuilder:validation:Optional
+	Path string `json:"path,omitempty"`
+}
+
+type FsImageConfigMapSource struct {
+	// Name of a ConfigMap whose keys are the files of the `current/` directory,
+	// binary files such as the fsimage are stored in binaryData.
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+}
+
+type FsImageS3Source struct {
+	// Bucket is the name of a S3Bucket in the namespace of the cluster.
+	// +kubebuilder:validation:Required
+	Bucket string `json:"bucket"`
+
+	// Path is the key prefix in the bucket which contains `current/`.
+	// +kubebuilder:validation:Optional
+	Path string `json:"path,omitempty"`
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DiskBalancerPhase is the phase of a disk balancer run on a single datanode pod.
+type DiskBalancerPhase string
+
+const (
+	DiskBalancerPhasePending   DiskBalancerPhase = "Pending"
+	DiskBalancerPhaseRunning   DiskBalancerPhase = "Running"
+	DiskBalancerPhaseSucceeded DiskBalancerPhase = "Succeeded"
+	DiskBalancerPhaseFailed    DiskBalancerPhase = "Failed"
+)
+
+// DiskBalancerSpec defines an intra-node disk balancer operation for dataNode pods.
+// see: https://hadoop.apache.org/docs/stable/hadoop-project-dist/hadoop-hdfs/HDFSDiskbalancer.html
+type DiskBalancerSpec struct {
+	// Enabled sets `dfs.disk.balancer.enabled` in the datanode hdfs-site.xml.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=true
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// RunID identifies a disk balancer run. Changing it starts a new plan/execute/query
+	// cycle against the selected pods. No run is started while it is empty.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxLength=20
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	RunID string `json:"runId,omitempty"`
+
+	// Pods is the list of dataNode pod names to balance.
+	// If both pods and roleGroups are empty, all dataNode pods are balanced.
+	// +kubebuilder:validation:Optional
+	Pods []string `json:"pods,omitempty"`
+
+	// RoleGroups is the list of dataNode role groups whose pods are balanced.
+	// +kubebuilder:validation:Optional
+	RoleGroups []string `json:"roleGroups,omitempty"`
+
+	// ThresholdPercentage is the percentage of data skew tolerated on each disk.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:default:=10
+	ThresholdPercentage int32 `json:"thresholdPercentage,omitempty"`
+
+	// Bandwidth is the maximum disk bandwidth in MB/s used while moving data.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default:=10
+	Bandwidth int32 `json:"bandwidth,omitempty"`
+
+	// QueryIntervalSeconds is the interval between two `-query` calls while a plan is executing.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default:=30
+	QueryIntervalSeconds int32 `json:"queryIntervalSeconds,omitempty"`
+}
+
+// DiskBalancerStatus tracks the progress of the current disk balancer run.
+type DiskBalancerStatus struct {
+	// +kubebuilder:validation:Optional
+	RunID string `json:"runId,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Pods []DiskBalancerPodStatus `json:"pods,omitempty"`
+}
+
+// DiskBalancerPodStatus is the plan progress of a single dataNode pod.
+type DiskBalancerPodStatus struct {
+	// +kubebuilder:validation:Required
+	Pod string `json:"pod"`
+
+	// +kubebuilder:validation:Optional
+	JobName string `json:"jobName,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Phase DiskBalancerPhase `json:"phase,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
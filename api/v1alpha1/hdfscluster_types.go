@@ -20,6 +20,7 @@ import (
 	"github.com/zncdatadev/operator-go/pkg/constants"
 	"github.com/zncdatadev/operator-go/pkg/status"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
@@ -36,7 +37,9 @@ const (
 	SecurityFileName = "security.properties"
 	// HadoopPolicyFileName see: https://hadoop.apache.org/docs/stable/hadoop-project-dist/hadoop-common/ServiceLevelAuth.html
 	HadoopPolicyFileName = "hadoop-policy.xml"
-	Log4jFileName        = "log4j.properties"
+	// ProxyUsersFileName contains the proxy users of the namenodes, it is included by core-site.xml
+	ProxyUsersFileName = "proxy-users.xml"
+	Log4jFileName      = "log4j.properties"
 )
 
 // volume name
@@ -116,8 +119,184 @@ type HdfsCluster struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec   HdfsClusterSpec `json:"spec,omitempty"`
-	Status status.Status   `json:"status,omitempty"`
+	Spec   HdfsClusterSpec   `json:"spec,omitempty"`
+	Status HdfsClusterStatus `json:"status,omitempty"`
+}
+
+// HdfsClusterStatus defines the observed state of HdfsCluster
+type HdfsClusterStatus struct {
+	status.Status `json:",inline"`
+
+	// +kubebuilder:validation:Optional
+	DiskBalancer *DiskBalancerStatus `json:"diskBalancer,omitempty"`
+
+	// ServiceAuthorization records the service ACLs loaded by the namenodes.
+	// +kubebuilder:validation:Optional
+	ServiceAuthorization *ConfigRefreshStatus `json:"serviceAuthorization,omitempty"`
+
+	// ProxyUsers records the proxy user configuration loaded by the namenodes.
+	// +kubebuilder:validation:Optional
+	ProxyUsers *ConfigRefreshStatus `json:"proxyUsers,omitempty"`
+
+	// Health is the health of the cluster read by the operator from the namenodes,
+	// it is only recorded when the operator runs with the cluster health probe.
+	// +kubebuilder:validation:Optional
+	Health *HealthStatus `json:"health,omitempty"`
+
+	// BringUp records the ordered initial start of the roles.
+	// +kubebuilder:validation:Optional
+	BringUp *BringUpStatus `json:"bringUp,omitempty"`
+
+	// Stop records the graceful stop of the cluster requested with `clusterOperation.stopped`.
+	// +kubebuilder:validation:Optional
+	Stop *StopStatus `json:"stop,omitempty"`
+
+	// Restarts records the rolling restarts of the role groups, keyed by `<role>/<roleGroup>`.
+	// +kubebuilder:validation:Optional
+	Restarts map[string]RestartStatus `json:"restarts,omitempty"`
+}
+
+// BringUpPhase is the role the initial start of a cluster waits for.
+type BringUpPhase string
+
+const (
+	// BringUpPhaseJournalNodes waits for the journalnodes, the namenodes are not created yet.
+	BringUpPhaseJournalNodes BringUpPhase = "JournalNodes"
+	// BringUpPhaseNameNodes waits for an active namenode, the datanodes are not created yet.
+	BringUpPhaseNameNodes BringUpPhase = "NameNodes"
+	// BringUpPhaseDataNodes waits for the datanodes.
+	BringUpPhaseDataNodes BringUpPhase = "DataNodes"
+	// BringUpPhaseCompleted is reached once all roles were ready, the roles are not ordered anymore.
+	BringUpPhaseCompleted BringUpPhase = "Completed"
+)
+
+// BringUpStatus records the ordered initial start of a cluster: the journalnodes are ready before
+// the namenodes format, and a namenode is active before the datanodes start.
+type BringUpStatus struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=JournalNodes;NameNodes;DataNodes;Completed
+	Phase BringUpPhase `json:"phase,omitempty"`
+
+	// Message describes what the phase waits for.
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
+
+	// LastTransitionTime is the time the phase started.
+	// +kubebuilder:validation:Optional
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// StopPhase is the step of the graceful stop of a cluster.
+type StopPhase string
+
+const (
+	// StopPhaseSavingNamespace puts the namenodes in safe mode and saves the namespace, all roles are running.
+	StopPhaseSavingNamespace StopPhase = "SavingNamespace"
+	// StopPhaseDataNodes waits for the datanodes to stop.
+	StopPhaseDataNodes StopPhase = "StoppingDataNodes"
+	// StopPhaseNameNodes waits for the namenodes to stop, the datanodes are stopped.
+	StopPhaseNameNodes StopPhase = "StoppingNameNodes"
+	// StopPhaseJournalNodes waits for the journalnodes to stop, the datanodes and namenodes are stopped.
+	StopPhaseJournalNodes StopPhase = "StoppingJournalNodes"
+	// StopPhaseStopped is reached once all roles are stopped.
+	StopPhaseStopped StopPhase = "Stopped"
+	// StopPhaseLeavingSafeMode is reached when the stop is cancelled while the namenodes are running,
+	// the namenodes leave the safe mode entered for the stop.
+	StopPhaseLeavingSafeMode StopPhase = "LeavingSafeMode"
+)
+
+// StopStatus records the graceful stop of a cluster: the namenodes enter safe mode and save the namespace,
+// then the datanodes, the namenodes and the journalnodes are stopped in order.
+type StopStatus struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=SavingNamespace;StoppingDataNodes;StoppingNameNodes;StoppingJournalNodes;Stopped;LeavingSafeMode
+	Phase StopPhase `json:"phase,omitempty"`
+
+	// Message describes what the phase waits for.
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
+
+	// LastTransitionTime is the time the phase started.
+	// +kubebuilder:validation:Optional
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// RestartPhase is the state of the rolling restart of a role group.
+type RestartPhase string
+
+const (
+	// RestartPhaseRestarting restarts the pods of the role group one after another.
+	RestartPhaseRestarting RestartPhase = "Restarting"
+	// RestartPhaseRestarted is reached once all pods were created after the request.
+	RestartPhaseRestarted RestartPhase = "Restarted"
+	// RestartPhaseFailed is reached when the failover of the active namenode failed after the last attempt,
+	// the restart is retried with a new request.
+	RestartPhaseFailed RestartPhase = "Failed"
+)
+
+// RestartStatus records the rolling restart of a role group requested with `restartRequestedAt`.
+type RestartStatus struct {
+	// RequestedAt is the request handled by the restart.
+	// +kubebuilder:validation:Optional
+	RequestedAt *metav1.Time `json:"requestedAt,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Restarting;Restarted;Failed
+	Phase RestartPhase `json:"phase,omitempty"`
+
+	// Message describes what the restart waits for.
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
+
+	// LastRestartTime is the time the last restart of the role group finished.
+	// +kubebuilder:validation:Optional
+	LastRestartTime *metav1.Time `json:"lastRestartTime,omitempty"`
+}
+
+// HealthStatus is the health of the cluster read from the JMX servlet of the namenodes.
+type HealthStatus struct {
+	// ActiveNameNode is the pod of the active namenode.
+	// +kubebuilder:validation:Optional
+	ActiveNameNode string `json:"activeNameNode,omitempty"`
+
+	// NameNodes are the HA states of the namenode pods.
+	// +kubebuilder:validation:Optional
+	NameNodes map[string]string `json:"nameNodes,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	LiveDataNodes int32 `json:"liveDataNodes,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	DeadDataNodes int32 `json:"deadDataNodes,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	MissingBlocks int64 `json:"missingBlocks,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	UnderReplicatedBlocks int64 `json:"underReplicatedBlocks,omitempty"`
+
+	// CapacityUsedPercent is the DFS used space in percent of the configured capacity.
+	// +kubebuilder:validation:Optional
+	CapacityUsedPercent int32 `json:"capacityUsedPercent,omitempty"`
+
+	// Message is the reason the health could not be read.
+	// +kubebuilder:validation:Optional
+	Message string `json:"message,omitempty"`
+
+	// LastTransitionTime is the time the health last changed.
+	// +kubebuilder:validation:Optional
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// ConfigRefreshStatus records configuration the namenodes reload with `dfsadmin` without a restart.
+type ConfigRefreshStatus struct {
+	// Hash is the hash of the configuration loaded by the namenodes.
+	// +kubebuilder:validation:Optional
+	Hash string `json:"hash,omitempty"`
+
+	// LastRefreshTime is the time the namenodes last reloaded the configuration.
+	// +kubebuilder:validation:Optional
+	LastRefreshTime *metav1.Time `json:"lastRefreshTime,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -135,6 +314,16 @@ type HdfsClusterSpec struct {
 	// +default:value={"repo": "quay.io/zncdatadev", "pullPolicy": "IfNotPresent"}
 	Image *ImageSpec `json:"image,omitempty"`
 
+	// VectorImage overrides the image of the vector sidecar of the roles, which uses the image of its role by
+	// default.
+	// +kubebuilder:validation:Optional
+	VectorImage *ImageOverrideSpec `json:"vectorImage,omitempty"`
+
+	// Oauth2ProxyImage overrides the image of the oauth2-proxy sidecar of the roles, which uses the image of its
+	// role by default. The image runs `/kubedoop/oauth2-proxy/oauth2-proxy` like the product image.
+	// +kubebuilder:validation:Optional
+	Oauth2ProxyImage *ImageOverrideSpec `json:"oauth2ProxyImage,omitempty"`
+
 	// +kubebuilder:validation:Optional
 	ClusterOperationSpec *commonsv1alpha1.ClusterOperationSpec `json:"clusterOperation,omitempty"`
 
@@ -143,16 +332,27 @@ type HdfsClusterSpec struct {
 
 	// roles defined: nameNode, dataNode, journalNode
 	// +kubebuilder:validation:Required
-	NameNode *RoleSpec `json:"nameNode,omitempty"`
+	NameNode *NameNodeSpec `json:"nameNode,omitempty"`
 
 	// +kubebuilder:validation:Required
-	DataNode *RoleSpec `json:"dataNode,omitempty"`
+	DataNode *DataNodeSpec `json:"dataNode,omitempty"`
 
 	// +kubebuilder:validation:Required
 	JournalNode *RoleSpec `json:"journalNode,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	DiskBalancer *DiskBalancerSpec `json:"diskBalancer,omitempty"`
+
+	// RestartRequestedAt requests a rolling restart of all roles, see RoleSpec.RestartRequestedAt.
+	// +kubebuilder:validation:Optional
+	RestartRequestedAt *metav1.Time `json:"restartRequestedAt,omitempty"`
 }
 
 type RoleSpec struct {
+	// Image overrides the image of the cluster for the role, e.g. with a patched build.
+	// +kubebuilder:validation:Optional
+	Image *ImageOverrideSpec `json:"image,omitempty"`
+
 	// +kubebuilder:validation:Optional
 	Config *ConfigSpec `json:"config,omitempty"`
 
@@ -160,11 +360,77 @@ type RoleSpec struct {
 	RoleGroups map[string]RoleGroupSpec `json:"roleGroups,omitempty"`
 
 	// +kubebuilder:validation:Optional
-	RoleConfig *commonsv1alpha1.RoleConfigSpec `json:"roleConfig,omitempty"`
+	RoleConfig *RoleConfigSpec `json:"roleConfig,omitempty"`
+
+	// RestartRequestedAt requests a rolling restart of the role: the pods created before the time are restarted
+	// one after another. A time in the future schedules the restart.
+	// +kubebuilder:validation:Optional
+	RestartRequestedAt *metav1.Time `json:"restartRequestedAt,omitempty"`
 
 	*commonsv1alpha1.OverridesSpec `json:",inline"`
 }
 
+type NameNodeSpec struct {
+	RoleSpec `json:",inline"`
+
+	// Bootstrap configures how the first namenode is initialized when the cluster has no metadata yet.
+	// +kubebuilder:validation:Optional
+	Bootstrap *BootstrapSpec `json:"bootstrap,omitempty"`
+
+	// AuditLog enables the audit log of the namenodes.
+	// +kubebuilder:validation:Optional
+	AuditLog *AuditLogSpec `json:"auditLog,omitempty"`
+}
+
+type DataNodeSpec struct {
+	RoleSpec `json:",inline"`
+
+	// WaitForNameNodes configures how the datanodes wait for the namenodes before they start.
+	// +kubebuilder:validation:Optional
+	WaitForNameNodes *WaitForNameNodesSpec `json:"waitForNameNodes,omitempty"`
+
+	// DataVolumes are the volumes the datanodes store their blocks on, each datanode has a PersistentVolumeClaim
+	// per volume and a directory on each of them in `dfs.datanode.data.dir`. Without data volumes the blocks
+	// are stored on the `data` volume with the storage capacity of the role group.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MinItems=1
+	// +listType=map
+	// +listMapKey=name
+	DataVolumes []DataVolumeSpec `json:"dataVolumes,omitempty"`
+}
+
+// DataVolumeSpec is a volume the datanodes store their blocks on.
+type DataVolumeSpec struct {
+	// Name of the volume, the PersistentVolumeClaims of the datanodes are named `<name>-<pod>`.
+	// The name starts with `data`, the volume of the datanodes without data volumes is `data`.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MaxLength=30
+	// +kubebuilder:validation:Pattern=`^data(-[a-z0-9]+)*$`
+	Name string `json:"name"`
+
+	// Capacity of the volume, the storage capacity of the role group if it is not set.
+	// +kubebuilder:validation:Optional
+	Capacity *resource.Quantity `json:"capacity,omitempty"`
+
+	// StorageClass of the volume, the default storage class if it is not set.
+	// +kubebuilder:validation:Optional
+	StorageClass string `json:"storageClass,omitempty"`
+
+	// StorageType of the volume in `dfs.datanode.data.dir`, which the storage policies of HDFS select.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=DISK;SSD;ARCHIVE;RAM_DISK
+	// +kubebuilder:default:=DISK
+	StorageType string `json:"storageType,omitempty"`
+}
+
+// RoleConfigSpec extends the role config of operator-go with the settings shared by all role groups of a role.
+type RoleConfigSpec struct {
+	commonsv1alpha1.RoleConfigSpec `json:",inline"`
+
+	// +kubebuilder:validation:Optional
+	Metrics *MetricsSpec `json:"metrics,omitempty"`
+}
+
 type RoleGroupSpec struct {
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default=1
@@ -173,6 +439,10 @@ type RoleGroupSpec struct {
 	// +kubebuilder:validation:Optional
 	Config *ConfigSpec `json:"config,omitempty"`
 
+	// RestartRequestedAt requests a rolling restart of the role group, see RoleSpec.RestartRequestedAt.
+	// +kubebuilder:validation:Optional
+	RestartRequestedAt *metav1.Time `json:"restartRequestedAt,omitempty"`
+
 	*commonsv1alpha1.OverridesSpec `json:",inline"`
 }
 type ConfigSpec struct {
@@ -180,6 +450,52 @@ type ConfigSpec struct {
 	ListenerClass                        *string `json:"listenerClass,omitempty"`
 }
 
+// WaitForNameNodesAction is what the datanodes do when the namenodes are not ready within the timeout.
+type WaitForNameNodesAction string
+
+const (
+	// WaitForNameNodesBlock waits until the namenodes are ready, the timeout is not used.
+	WaitForNameNodesBlock WaitForNameNodesAction = "Block"
+	// WaitForNameNodesFail fails the init container, the pod is restarted with a backoff.
+	WaitForNameNodesFail WaitForNameNodesAction = "Fail"
+	// WaitForNameNodesProceed starts the datanode anyway.
+	WaitForNameNodesProceed WaitForNameNodesAction = "Proceed"
+)
+
+const (
+	DefaultWaitForNameNodesTimeoutSeconds  int32 = 60
+	DefaultWaitForNameNodesIntervalSeconds int32 = 5
+)
+
+// WaitForNameNodesSpec configures the wait-for-namenodes init container of the datanodes. The namenodes
+// are ready when one of them is active and not in a safe mode turned on manually.
+type WaitForNameNodesSpec struct {
+	// TimeoutSeconds is the time the datanodes wait for the namenodes.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default:=60
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+
+	// IntervalSeconds is the interval between two checks of the namenodes.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default:=5
+	IntervalSeconds int32 `json:"intervalSeconds,omitempty"`
+
+	// OnTimeout is what the datanodes do when the namenodes are not ready within the timeout.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Block;Fail;Proceed
+	// +kubebuilder:default:=Fail
+	OnTimeout WaitForNameNodesAction `json:"onTimeout,omitempty"`
+
+	// WaitForSafeModeExit waits until the active namenode left a safe mode turned on manually, e.g. for
+	// maintenance. The startup safe mode is not waited for, the namenode needs the block reports of the
+	// datanodes to leave it.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=true
+	WaitForSafeModeExit *bool `json:"waitForSafeModeExit,omitempty"`
+}
+
 type ClusterConfigSpec struct {
 	// +kubebuilder:validation:Optional
 	VectorAggregatorConfigMapName string `json:"vectorAggregatorConfigMapName,omitempty"`
@@ -194,6 +510,22 @@ type ClusterConfigSpec struct {
 	// +kubebuilder:validation:Optional
 	Authentication *AuthenticationSpec `json:"authentication,omitempty"`
 
+	// +kubebuilder:validation:Optional
+	Authorization *AuthorizationSpec `json:"authorization,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	ServiceAuthorization *ServiceAuthorizationSpec `json:"serviceAuthorization,omitempty"`
+
+	// GroupMapping resolves the groups of users HDFS permissions are evaluated against.
+	// +kubebuilder:validation:Optional
+	GroupMapping *GroupMappingSpec `json:"groupMapping,omitempty"`
+
+	// ProxyUsers allows services like Hive, Trino and Spark to impersonate other users.
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=user
+	ProxyUsers []ProxyUserSpec `json:"proxyUsers,omitempty"`
+
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default:="cluster.local"
 	ClusterDomain string `json:"clusterDomain,omitempty"`
@@ -204,6 +536,12 @@ type ClusterConfigSpec struct {
 
 	// +kubebuilder:validation:required
 	ZookeeperConfigMapName string `json:"zookeeperConfigMapName,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Backup *BackupSpec `json:"backup,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Monitoring *MonitoringSpec `json:"monitoring,omitempty"`
 }
 
 type AuthenticationSpec struct {
@@ -220,6 +558,174 @@ type AuthenticationSpec struct {
 	Kerberos *KerberosSpec `json:"kerberos,omitempty"`
 }
 
+type AuthorizationSpec struct {
+	// +kubebuilder:validation:Optional
+	Opa *OpaSpec `json:"opa,omitempty"`
+}
+
+// GroupMappingSpec configures `hadoop.security.group.mapping` of the namenodes.
+// It can not be combined with the groupMapperClass of OPA, which resolves the groups itself.
+type GroupMappingSpec struct {
+	// +kubebuilder:validation:Optional
+	Ldap *LdapGroupMappingSpec `json:"ldap,omitempty"`
+}
+
+// LdapGroupMappingSpec resolves groups with the LdapGroupsMapping from the LDAP server of an AuthenticationClass.
+type LdapGroupMappingSpec struct {
+	// AuthenticationClass is the name of an AuthenticationClass with a LDAP provider.
+	// Its bind credentials are used to search users and groups.
+	// +kubebuilder:validation:Required
+	AuthenticationClass string `json:"authenticationClass"`
+
+	// UserSearchBase is the base of the user search, defaults to the searchBase of the AuthenticationClass.
+	// +kubebuilder:validation:Optional
+	UserSearchBase string `json:"userSearchBase,omitempty"`
+
+	// GroupSearchBase is the base of the group search, defaults to the searchBase of the AuthenticationClass.
+	// +kubebuilder:validation:Optional
+	GroupSearchBase string `json:"groupSearchBase,omitempty"`
+
+	// UserFilter finds the entry of a user, `{0}` is replaced by the user name. Defaults to `(<uid>={0})`
+	// with the uid field of the AuthenticationClass, combined with its searchFilter.
+	// +kubebuilder:validation:Optional
+	UserFilter string `json:"userFilter,omitempty"`
+
+	// GroupFilter finds the groups.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="(objectClass=groupOfNames)"
+	GroupFilter string `json:"groupFilter,omitempty"`
+
+	// MemberAttribute is the attribute of a group containing its members.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="member"
+	MemberAttribute string `json:"memberAttribute,omitempty"`
+
+	// GroupNameAttribute is the attribute of a group containing its name.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="cn"
+	GroupNameAttribute string `json:"groupNameAttribute,omitempty"`
+}
+
+// OpaSpec configures the namenode to authorize requests and resolve groups with OPA.
+type OpaSpec struct {
+	// ConfigMapName is the name of the OPA discovery ConfigMap, containing the OPA url in the key `OPA`.
+	// +kubebuilder:validation:Required
+	ConfigMapName string `json:"configMapName"`
+
+	// Package is the rego package of the rules. The `allow` rule authorizes requests and
+	// the `groups` rule returns the groups of a user.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="hdfs"
+	Package string `json:"package,omitempty"`
+
+	// AuthorizerClass is the INodeAttributeProvider querying OPA, it must be available in the image.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	AuthorizerClass string `json:"authorizerClass"`
+
+	// GroupMapperClass is the GroupMappingServiceProvider querying OPA, it must be available in the image.
+	// The groups are only resolved by OPA if it is set.
+	// +kubebuilder:validation:Optional
+	GroupMapperClass string `json:"groupMapperClass,omitempty"`
+}
+
+// ServiceAuthorizationSpec enables service level authorization, the ACLs of the protocols are rendered
+// into hadoop-policy.xml. Protocols without an ACL use the default ACL, which allows everyone if it is not set.
+// The principals of the roles must be allowed to use the protocols between the roles, e.g. datanode and qjournal.
+type ServiceAuthorizationSpec struct {
+	// Default is `security.service.authorization.default.acl`.
+	// +kubebuilder:validation:Optional
+	Default *ServiceAclSpec `json:"default,omitempty"`
+
+	// Client is `security.client.protocol.acl`, used by clients to access the namenode.
+	// +kubebuilder:validation:Optional
+	Client *ServiceAclSpec `json:"client,omitempty"`
+
+	// ClientDatanode is `security.client.datanode.protocol.acl`, used by clients to access the datanodes.
+	// +kubebuilder:validation:Optional
+	ClientDatanode *ServiceAclSpec `json:"clientDatanode,omitempty"`
+
+	// Datanode is `security.datanode.protocol.acl`, used by datanodes to access the namenode.
+	// +kubebuilder:validation:Optional
+	Datanode *ServiceAclSpec `json:"datanode,omitempty"`
+
+	// InterDatanode is `security.inter.datanode.protocol.acl`, used between datanodes.
+	// +kubebuilder:validation:Optional
+	InterDatanode *ServiceAclSpec `json:"interDatanode,omitempty"`
+
+	// Namenode is `security.namenode.protocol.acl`, used by the standby namenode and the balancer.
+	// +kubebuilder:validation:Optional
+	Namenode *ServiceAclSpec `json:"namenode,omitempty"`
+
+	// HaAdmin is `security.ha.service.protocol.acl`, used by haadmin and zkfc to transition the namenodes.
+	// +kubebuilder:validation:Optional
+	HaAdmin *ServiceAclSpec `json:"haAdmin,omitempty"`
+
+	// Zkfc is `security.zkfc.protocol.acl`, used for graceful failover between the zkfcs.
+	// +kubebuilder:validation:Optional
+	Zkfc *ServiceAclSpec `json:"zkfc,omitempty"`
+
+	// Qjournal is `security.qjournal.service.protocol.acl`, used by the namenodes to access the journalnodes.
+	// +kubebuilder:validation:Optional
+	Qjournal *ServiceAclSpec `json:"qjournal,omitempty"`
+
+	// InterQjournal is `security.interqjournal.service.protocol.acl`, used between journalnodes.
+	// +kubebuilder:validation:Optional
+	InterQjournal *ServiceAclSpec `json:"interQjournal,omitempty"`
+
+	// RefreshPolicy is `security.refresh.policy.protocol.acl`, used by `dfsadmin -refreshServiceAcl`.
+	// +kubebuilder:validation:Optional
+	RefreshPolicy *ServiceAclSpec `json:"refreshPolicy,omitempty"`
+
+	// RefreshUserMappings is `security.refresh.user.mappings.protocol.acl`,
+	// used by `dfsadmin -refreshUserToGroupsMappings` and `-refreshSuperUserGroupsConfiguration`.
+	// +kubebuilder:validation:Optional
+	RefreshUserMappings *ServiceAclSpec `json:"refreshUserMappings,omitempty"`
+
+	// RefreshCallQueue is `security.refresh.callqueue.protocol.acl`, used by `dfsadmin -refreshCallQueue`.
+	// +kubebuilder:validation:Optional
+	RefreshCallQueue *ServiceAclSpec `json:"refreshCallQueue,omitempty"`
+
+	// GetUserMappings is `security.get.user.mappings.protocol.acl`, used by `hdfs groups`.
+	// +kubebuilder:validation:Optional
+	GetUserMappings *ServiceAclSpec `json:"getUserMappings,omitempty"`
+
+	// Reconfiguration is `security.reconfiguration.protocol.acl`, used by `dfsadmin -reconfig`.
+	// +kubebuilder:validation:Optional
+	Reconfiguration *ServiceAclSpec `json:"reconfiguration,omitempty"`
+}
+
+// ServiceAclSpec is the ACL of a protocol. Use `*` as user to allow everyone,
+// if neither users nor groups are set nobody is allowed.
+type ServiceAclSpec struct {
+	// +kubebuilder:validation:Optional
+	Users []string `json:"users,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Groups []string `json:"groups,omitempty"`
+}
+
+// ProxyUserSpec is rendered as `hadoop.proxyuser.<user>.hosts`, `.groups` and `.users`.
+// Use `*` to allow all hosts, groups or users, lists which are not set allow nothing.
+type ProxyUserSpec struct {
+	// User is the short name of the impersonating user, e.g. `hive`.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[^.\s]+$`
+	User string `json:"user"`
+
+	// Hosts the user may impersonate from, as host names, IP addresses or CIDRs.
+	// +kubebuilder:validation:Optional
+	Hosts []string `json:"hosts,omitempty"`
+
+	// Groups whose members the user may impersonate.
+	// +kubebuilder:validation:Optional
+	Groups []string `json:"groups,omitempty"`
+
+	// Users the user may impersonate.
+	// +kubebuilder:validation:Optional
+	Users []string `json:"users,omitempty"`
+}
+
 // OidcSpec defines the OIDC spec.
 type OidcSpec struct {
 	// OIDC client credentials secret. It must contain the following keys:
@@ -231,6 +737,35 @@ type OidcSpec struct {
 
 	// +kubebuilder:validation:Optional
 	ExtraScopes []string `json:"extraScopes,omitempty"`
+
+	// AllowedGroups restricts the access to members of these groups, read from the `groups` claim of the ID token.
+	// +kubebuilder:validation:Optional
+	AllowedGroups []string `json:"allowedGroups,omitempty"`
+
+	// AllowedEmails restricts the access to these email addresses, by default every authenticated user is allowed.
+	// If allowedGroups is also set, a user must match both.
+	// +kubebuilder:validation:Optional
+	AllowedEmails []string `json:"allowedEmails,omitempty"`
+
+	// SkipAuthPaths are regular expressions of request paths which are passed to the web UI without authentication,
+	// e.g. `^/jmx` to scrape the metrics.
+	// +kubebuilder:validation:Optional
+	SkipAuthPaths []string `json:"skipAuthPaths,omitempty"`
+
+	// CookieDomain is the domain of the session cookie, e.g. `.example.com` to share the session between the web UIs.
+	// +kubebuilder:validation:Optional
+	CookieDomain string `json:"cookieDomain,omitempty"`
+
+	// CookieLifetime is the lifetime of the session cookie, after which users have to log in again.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="168h"
+	CookieLifetime *metav1.Duration `json:"cookieLifetime,omitempty"`
+
+	// UpstreamTlsVerification verifies the certificate of the web UI with the CA of the TLS SecretClass
+	// if TLS is enabled. Disabling it skips the verification of the connection from the proxy to the web UI.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=true
+	UpstreamTlsVerification *bool `json:"upstreamTlsVerification,omitempty"`
 }
 
 type TlsSpec struct {
@@ -238,14 +773,101 @@ type TlsSpec struct {
 	// +kubebuilder:default:="tls"
 	SecretClass string `json:"secretClass,omitempty"`
 
+	// JksPassword is the password of the keystore and truststore.
+	// Deprecated: it is stored in plaintext in the spec and in the env of the pod templates,
+	// use jksPasswordSecretRef instead.
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default:="changeit"
 	JksPassword string `json:"jksPassword,omitempty"`
+
+	// JksPasswordSecretRef references a Secret key containing the password of the keystore and truststore,
+	// it takes precedence over jksPassword. The password is only passed to the containers by the Secret, the pods
+	// are restarted when it changes. The secret-operator can not read the password from a Secret, so the stores
+	// in the tls volume keep the password `changeit`, the containers use copies protected by the password of the Secret.
+	// +kubebuilder:validation:Optional
+	JksPasswordSecretRef *SecretKeyRefSpec `json:"jksPasswordSecretRef,omitempty"`
+}
+
+// SecretKeyRefSpec references a key of a Secret in the namespace of the cluster.
+type SecretKeyRefSpec struct {
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="password"
+	Key string `json:"key,omitempty"`
 }
 
 type KerberosSpec struct {
 	// +kubebuilder:validation:Optional
 	SecretClass string `json:"secretClass,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Principals *KerberosPrincipalsSpec `json:"principals,omitempty"`
+
+	// AdditionalTrustedRealms are realms whose users are mapped to their short name,
+	// e.g. users of an Active Directory realm trusted by the realm of the cluster.
+	// +kubebuilder:validation:Optional
+	AdditionalTrustedRealms []string `json:"additionalTrustedRealms,omitempty"`
+
+	// AuthToLocal rules are added to `hadoop.security.auth_to_local` before the rules of the
+	// additional trusted realms and the `DEFAULT` rule, e.g. `RULE:[1:$1@$0](.*@CORP\.EXAMPLE)s/@.*//`.
+	// +kubebuilder:validation:Optional
+	AuthToLocal []string `json:"authToLocal,omitempty"`
+
+	// RpcProtection is the quality of protection of the RPC connections, `hadoop.rpc.protection`.
+	// It is `privacy` if it is not set, setting it requires TLS.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=authentication;integrity;privacy
+	RpcProtection string `json:"rpcProtection,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	DataTransfer *DataTransferSpec `json:"dataTransfer,omitempty"`
+}
+
+// DataTransferSpec defines the SASL protection of the block transfers of the datanodes.
+type DataTransferSpec struct {
+	// Protection is the quality of protection of the block transfers, `dfs.data.transfer.protection`.
+	// With `privacy` the blocks are encrypted with the data encryption keys of the namenode.
+	// It is `privacy` if it is not set.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=authentication;integrity;privacy
+	Protection string `json:"protection,omitempty"`
+
+	// CipherSuite encrypts the blocks with AES instead of the 3DES or RC4 of the SASL handshake,
+	// it requires the `privacy` protection.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum="AES/CTR/NoPadding"
+	CipherSuite string `json:"cipherSuite,omitempty"`
+
+	// CipherKeyBitLength is the length of the AES key of the cipher suite.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=128;192;256
+	CipherKeyBitLength int32 `json:"cipherKeyBitLength,omitempty"`
+}
+
+// KerberosPrincipalsSpec defines the principal name templates of the services.
+// The placeholders `${instance}`, `${namespace}`, `${host}` and `${realm}` are replaced,
+// `${host}` is `<instance>.<namespace>.svc.<clusterDomain>`.
+// The service part of a principal, before the `/`, is requested from the SecretClass.
+// The host must be `${host}`, the keytabs are only issued for it.
+type KerberosPrincipalsSpec struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="nn/${host}@${realm}"
+	NameNode string `json:"nameNode,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="dn/${host}@${realm}"
+	DataNode string `json:"dataNode,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="jn/${host}@${realm}"
+	JournalNode string `json:"journalNode,omitempty"`
+
+	// Http is the SPNEGO principal of the web UIs.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="HTTP/${host}@${realm}"
+	Http string `json:"http,omitempty"`
 }
 
 type ConfigOverridesSpec struct {
@@ -0,0 +1,183 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReplicationMode is the DistCp mode used to copy the paths.
+// +kubebuilder:validation:Enum=Update;Diff
+type ReplicationMode string
+
+const (
+	// ReplicationModeUpdate copies files which are missing or differ in the target with `distcp -update`.
+	ReplicationModeUpdate ReplicationMode = "Update"
+	// ReplicationModeDiff copies the changes between the last two snapshots of the source with `distcp -update -diff`.
+	// The source and target paths must be snapshottable.
+	ReplicationModeDiff ReplicationMode = "Diff"
+)
+
+const (
+	ReplicationConditionReady     = "Ready"
+	ReplicationConditionSucceeded = "Succeeded"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Schedule",type=string,JSONPath=`.spec.schedule`
+// +kubebuilder:printcolumn:name="Last Success",type=date,JSONPath=`.status.lastSuccessTime`
+// +kubebuilder:printcolumn:name="Lag",type=integer,JSONPath=`.status.lagSeconds`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// HdfsReplication is the Schema for the hdfsreplications API
+type HdfsReplication struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   HdfsReplicationSpec   `json:"spec,omitempty"`
+	Status HdfsReplicationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// HdfsReplicationList contains a list of HdfsReplication
+type HdfsReplicationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HdfsReplication `json:"items"`
+}
+
+// HdfsReplicationSpec defines the desired state of HdfsReplication
+type HdfsReplicationSpec struct {
+	// +kubebuilder:validation:Optional
+	// +default:value={"repo": "quay.io/zncdatadev", "pullPolicy": "IfNotPresent"}
+	Image *ImageSpec `json:"image,omitempty"`
+
+	// +kubebuilder:validation:Required
+	Source ReplicationClusterSpec `json:"source"`
+
+	// +kubebuilder:validation:Required
+	Target ReplicationClusterSpec `json:"target"`
+
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	Paths []ReplicationPathSpec `json:"paths"`
+
+	// Schedule in cron format.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="0 * * * *"
+	Schedule string `json:"schedule,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=Update
+	Mode ReplicationMode `json:"mode,omitempty"`
+
+	// Delete files in the target which do not exist in the source, only used in Update mode.
+	// +kubebuilder:validation:Optional
+	Delete bool `json:"delete,omitempty"`
+
+	// Maps is the number of simultaneous copies.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default:=20
+	Maps int32 `json:"maps,omitempty"`
+
+	// Bandwidth is the bandwidth per map in MB/s.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	Bandwidth *int32 `json:"bandwidth,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Kerberos *ReplicationKerberosSpec `json:"kerberos,omitempty"`
+}
+
+// ReplicationClusterSpec references a HDFS cluster, exactly one of hdfsCluster or configMap should be set.
+type ReplicationClusterSpec struct {
+	// HdfsCluster is the name of a HdfsCluster in the namespace of the replication.
+	// +kubebuilder:validation:Optional
+	HdfsCluster string `json:"hdfsCluster,omitempty"`
+
+	// ConfigMap is the name of a discovery ConfigMap containing `core-site.xml` and `hdfs-site.xml`,
+	// e.g. copied from a cluster in another Kubernetes cluster.
+	// +kubebuilder:validation:Optional
+	ConfigMap string `json:"configMap,omitempty"`
+
+	// KerberosRealm of the cluster, required if it is not the realm of the replication job.
+	// +kubebuilder:validation:Optional
+	KerberosRealm string `json:"kerberosRealm,omitempty"`
+}
+
+type ReplicationPathSpec struct {
+	// Source path in the source cluster.
+	// +kubebuilder:validation:Required
+	Source string `json:"source"`
+
+	// Target path in the target cluster, defaults to the source path.
+	// +kubebuilder:validation:Optional
+	Target string `json:"target,omitempty"`
+}
+
+type ReplicationKerberosSpec struct {
+	// SecretClass providing the keytab of the replication job.
+	// The principal is `distcp/<name>.<namespace>.svc.<clusterDomain>@<realm>`.
+	// +kubebuilder:validation:Required
+	SecretClass string `json:"secretClass"`
+
+	// ClusterDomain of the Kubernetes cluster, the domain of the host of the principal.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="cluster.local"
+	ClusterDomain string `json:"clusterDomain,omitempty"`
+
+	// Krb5ConfigMap is the name of a ConfigMap with a `krb5.conf` key, replacing the krb5.conf
+	// provided by the SecretClass. It is required to trust the realms of both clusters.
+	// +kubebuilder:validation:Optional
+	Krb5ConfigMap string `json:"krb5ConfigMap,omitempty"`
+}
+
+// HdfsReplicationStatus defines the observed state of HdfsReplication
+type HdfsReplicationStatus struct {
+	// +kubebuilder:validation:Optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// LastScheduleTime is the start time of the last replication job.
+	// +kubebuilder:validation:Optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// LastSuccessTime is the completion time of the last successful replication job.
+	// +kubebuilder:validation:Optional
+	LastSuccessTime *metav1.Time `json:"lastSuccessTime,omitempty"`
+
+	// LastSyncedTime is the start time of the last successful replication job,
+	// the target contains the source state of this time.
+	// +kubebuilder:validation:Optional
+	LastSyncedTime *metav1.Time `json:"lastSyncedTime,omitempty"`
+
+	// LagSeconds is the number of seconds since lastSyncedTime, refreshed every minute.
+	// +kubebuilder:validation:Optional
+	LagSeconds *int64 `json:"lagSeconds,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	LastJobName string `json:"lastJobName,omitempty"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HdfsReplication{}, &HdfsReplicationList{})
+}
@@ -32,3 +32,28 @@ type ImageSpec struct {
 	// +kubebuilder:validation:Optional
 	PullSecretName string `json:"pullSecretName,omitempty"`
 }
+
+// ImageOverrideSpec overrides the image of the cluster, for a role or a sidecar. The fields which are not set are
+// taken from the image of the cluster. Setting the repo or a version replaces a custom image of the cluster.
+type ImageOverrideSpec struct {
+	// Custom is the full name of the image, e.g. `registry.example.com/hadoop:3.4.1-patched`.
+	// +kubebuilder:validation:Optional
+	Custom string `json:"custom,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Repo string `json:"repo,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	KubedoopVersion string `json:"kubedoopVersion,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	ProductVersion string `json:"productVersion,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	PullPolicy corev1.PullPolicy `json:"pullPolicy,omitempty"`
+
+	// PullSecretName is the Secret used to pull the image, it is added to the image pull secrets of the pods.
+	// +kubebuilder:validation:Optional
+	PullSecretName string `json:"pullSecretName,omitempty"`
+}
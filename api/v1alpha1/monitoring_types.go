@@ -0,0 +1,99 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// MonitoringSpec creates a ServiceMonitor for the metrics Service of every role group and a
+// PrometheusRule with the alerts of the cluster. It requires the CRDs of the Prometheus operator.
+type MonitoringSpec struct {
+	// Labels are added to the ServiceMonitors and the PrometheusRule,
+	// e.g. the labels selected by `serviceMonitorSelector` and `ruleSelector` of the Prometheus.
+	// +kubebuilder:validation:Optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// ScrapeInterval of the ServiceMonitors, e.g. `30s`. The interval of the Prometheus is used if it is empty.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^[0-9]+(ms|s|m|h)$`
+	ScrapeInterval string `json:"scrapeInterval,omitempty"`
+
+	// TlsCaSecretName is a Secret in the namespace of the cluster with the CA of the TLS SecretClass in `ca.crt`.
+	// If it is empty and TLS is enabled, the certificates of the web UIs are not verified by Prometheus.
+	// +kubebuilder:validation:Optional
+	TlsCaSecretName string `json:"tlsCaSecretName,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Alerts *AlertsSpec `json:"alerts,omitempty"`
+}
+
+// AlertsSpec defines the thresholds of the alerts of the PrometheusRule.
+type AlertsSpec struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=true
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// CapacityWarningPercent is the used capacity of the cluster raising a warning.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:default:=80
+	CapacityWarningPercent int32 `json:"capacityWarningPercent,omitempty"`
+
+	// CapacityCriticalPercent is the used capacity of the cluster raising a critical alert.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +kubebuilder:default:=90
+	CapacityCriticalPercent int32 `json:"capacityCriticalPercent,omitempty"`
+
+	// JournalLagTxns is the number of transactions a journalnode may lag behind the writer.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default:=1000
+	JournalLagTxns int32 `json:"journalLagTxns,omitempty"`
+}
+
+// MetricsSpec configures the JMX exporter javaagent, which serves the MBeans of the role on its metric port.
+type MetricsSpec struct {
+	// JmxExporterEnabled adds the JMX exporter javaagent to the role.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=true
+	JmxExporterEnabled *bool `json:"jmxExporterEnabled,omitempty"`
+
+	// JmxExporterRules replaces the JMX exporter config of the image, e.g. to export per-user RPC metrics.
+	// +kubebuilder:validation:Optional
+	JmxExporterRules *JmxExporterRulesSpec `json:"jmxExporterRules,omitempty"`
+}
+
+// JmxExporterRulesSpec is a complete JMX exporter config, including `rules`, either inline or from a ConfigMap.
+type JmxExporterRulesSpec struct {
+	// Inline is the YAML of the config.
+	// +kubebuilder:validation:Optional
+	Inline string `json:"inline,omitempty"`
+
+	// ConfigMap references a key of a ConfigMap in the namespace of the cluster with the YAML of the config.
+	// +kubebuilder:validation:Optional
+	ConfigMap *ConfigMapKeyRefSpec `json:"configMap,omitempty"`
+}
+
+// ConfigMapKeyRefSpec references a key of a ConfigMap in the namespace of the cluster.
+type ConfigMapKeyRefSpec struct {
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="config.yaml"
+	Key string `json:"key,omitempty"`
+}
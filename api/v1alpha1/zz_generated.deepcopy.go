@@ -22,9 +22,45 @@ package v1alpha1
 
 import (
 	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertsSpec) DeepCopyInto(out *AlertsSpec) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertsSpec.
+func (in *AlertsSpec) DeepCopy() *AlertsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditLogSpec) DeepCopyInto(out *AuditLogSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditLogSpec.
+func (in *AuditLogSpec) DeepCopy() *AuditLogSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditLogSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AuthenticationSpec) DeepCopyInto(out *AuthenticationSpec) {
 	*out = *in
@@ -36,12 +72,12 @@ func (in *AuthenticationSpec) DeepCopyInto(out *AuthenticationSpec) {
 	if in.Tls != nil {
 		in, out := &in.Tls, &out.Tls
 		*out = new(TlsSpec)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Kerberos != nil {
 		in, out := &in.Kerberos, &out.Kerberos
 		*out = new(KerberosSpec)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -55,6 +91,95 @@ func (in *AuthenticationSpec) DeepCopy() *AuthenticationSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuthorizationSpec) DeepCopyInto(out *AuthorizationSpec) {
+	*out = *in
+	if in.Opa != nil {
+		in, out := &in.Opa, &out.Opa
+		*out = new(OpaSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthorizationSpec.
+func (in *AuthorizationSpec) DeepCopy() *AuthorizationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AuthorizationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupSpec) DeepCopyInto(out *BackupSpec) {
+	*out = *in
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3BackupSpec)
+		**out = **in
+	}
+	if in.Pvc != nil {
+		in, out := &in.Pvc, &out.Pvc
+		*out = new(PvcBackupSpec)
+		**out = **in
+	}
+	if in.Restore != nil {
+		in, out := &in.Restore, &out.Restore
+		*out = new(RestoreSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupSpec.
+func (in *BackupSpec) DeepCopy() *BackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BootstrapSpec) DeepCopyInto(out *BootstrapSpec) {
+	*out = *in
+	if in.FromImage != nil {
+		in, out := &in.FromImage, &out.FromImage
+		*out = new(FromImageSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BootstrapSpec.
+func (in *BootstrapSpec) DeepCopy() *BootstrapSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BootstrapSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BringUpStatus) DeepCopyInto(out *BringUpStatus) {
+	*out = *in
+	if in.LastTransitionTime != nil {
+		in, out := &in.LastTransitionTime, &out.LastTransitionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BringUpStatus.
+func (in *BringUpStatus) DeepCopy() *BringUpStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BringUpStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterConfigSpec) DeepCopyInto(out *ClusterConfigSpec) {
 	*out = *in
@@ -68,6 +193,38 @@ func (in *ClusterConfigSpec) DeepCopyInto(out *ClusterConfigSpec) {
 		*out = new(AuthenticationSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Authorization != nil {
+		in, out := &in.Authorization, &out.Authorization
+		*out = new(AuthorizationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServiceAuthorization != nil {
+		in, out := &in.ServiceAuthorization, &out.ServiceAuthorization
+		*out = new(ServiceAuthorizationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GroupMapping != nil {
+		in, out := &in.GroupMapping, &out.GroupMapping
+		*out = new(GroupMappingSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProxyUsers != nil {
+		in, out := &in.ProxyUsers, &out.ProxyUsers
+		*out = make([]ProxyUserSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(BackupSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(MonitoringSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterConfigSpec.
@@ -80,6 +237,21 @@ func (in *ClusterConfigSpec) DeepCopy() *ClusterConfigSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapKeyRefSpec) DeepCopyInto(out *ConfigMapKeyRefSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapKeyRefSpec.
+func (in *ConfigMapKeyRefSpec) DeepCopy() *ConfigMapKeyRefSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapKeyRefSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConfigOverridesSpec) DeepCopyInto(out *ConfigOverridesSpec) {
 	*out = *in
@@ -144,6 +316,25 @@ func (in *ConfigOverridesSpec) DeepCopy() *ConfigOverridesSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigRefreshStatus) DeepCopyInto(out *ConfigRefreshStatus) {
+	*out = *in
+	if in.LastRefreshTime != nil {
+		in, out := &in.LastRefreshTime, &out.LastRefreshTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigRefreshStatus.
+func (in *ConfigRefreshStatus) DeepCopy() *ConfigRefreshStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigRefreshStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConfigSpec) DeepCopyInto(out *ConfigSpec) {
 	*out = *in
@@ -170,207 +361,857 @@ func (in *ConfigSpec) DeepCopy() *ConfigSpec {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HdfsCluster) DeepCopyInto(out *HdfsCluster) {
+func (in *DataNodeSpec) DeepCopyInto(out *DataNodeSpec) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	in.RoleSpec.DeepCopyInto(&out.RoleSpec)
+	if in.WaitForNameNodes != nil {
+		in, out := &in.WaitForNameNodes, &out.WaitForNameNodes
+		*out = new(WaitForNameNodesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DataVolumes != nil {
+		in, out := &in.DataVolumes, &out.DataVolumes
+		*out = make([]DataVolumeSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HdfsCluster.
-func (in *HdfsCluster) DeepCopy() *HdfsCluster {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataNodeSpec.
+func (in *DataNodeSpec) DeepCopy() *DataNodeSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(HdfsCluster)
+	out := new(DataNodeSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *HdfsCluster) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HdfsClusterList) DeepCopyInto(out *HdfsClusterList) {
+func (in *DataTransferSpec) DeepCopyInto(out *DataTransferSpec) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]HdfsCluster, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HdfsClusterList.
-func (in *HdfsClusterList) DeepCopy() *HdfsClusterList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataTransferSpec.
+func (in *DataTransferSpec) DeepCopy() *DataTransferSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(HdfsClusterList)
+	out := new(DataTransferSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *HdfsClusterList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HdfsClusterSpec) DeepCopyInto(out *HdfsClusterSpec) {
+func (in *DataVolumeSpec) DeepCopyInto(out *DataVolumeSpec) {
 	*out = *in
-	if in.Image != nil {
-		in, out := &in.Image, &out.Image
-		*out = new(ImageSpec)
-		**out = **in
-	}
-	if in.ClusterOperationSpec != nil {
-		in, out := &in.ClusterOperationSpec, &out.ClusterOperationSpec
-		*out = new(commonsv1alpha1.ClusterOperationSpec)
-		**out = **in
-	}
-	if in.ClusterConfig != nil {
-		in, out := &in.ClusterConfig, &out.ClusterConfig
-		*out = new(ClusterConfigSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.NameNode != nil {
-		in, out := &in.NameNode, &out.NameNode
-		*out = new(RoleSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.DataNode != nil {
-		in, out := &in.DataNode, &out.DataNode
-		*out = new(RoleSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.JournalNode != nil {
-		in, out := &in.JournalNode, &out.JournalNode
-		*out = new(RoleSpec)
-		(*in).DeepCopyInto(*out)
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		x := (*in).DeepCopy()
+		*out = &x
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HdfsClusterSpec.
-func (in *HdfsClusterSpec) DeepCopy() *HdfsClusterSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeSpec.
+func (in *DataVolumeSpec) DeepCopy() *DataVolumeSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(HdfsClusterSpec)
+	out := new(DataVolumeSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ImageSpec) DeepCopyInto(out *ImageSpec) {
+func (in *DiskBalancerPodStatus) DeepCopyInto(out *DiskBalancerPodStatus) {
 	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageSpec.
-func (in *ImageSpec) DeepCopy() *ImageSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskBalancerPodStatus.
+func (in *DiskBalancerPodStatus) DeepCopy() *DiskBalancerPodStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ImageSpec)
+	out := new(DiskBalancerPodStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KerberosSpec) DeepCopyInto(out *KerberosSpec) {
+func (in *DiskBalancerSpec) DeepCopyInto(out *DiskBalancerSpec) {
 	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RoleGroups != nil {
+		in, out := &in.RoleGroups, &out.RoleGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KerberosSpec.
-func (in *KerberosSpec) DeepCopy() *KerberosSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskBalancerSpec.
+func (in *DiskBalancerSpec) DeepCopy() *DiskBalancerSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(KerberosSpec)
+	out := new(DiskBalancerSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LogLevelSpec) DeepCopyInto(out *LogLevelSpec) {
+func (in *DiskBalancerStatus) DeepCopyInto(out *DiskBalancerStatus) {
 	*out = *in
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = make([]DiskBalancerPodStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogLevelSpec.
-func (in *LogLevelSpec) DeepCopy() *LogLevelSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskBalancerStatus.
+func (in *DiskBalancerStatus) DeepCopy() *DiskBalancerStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(LogLevelSpec)
+	out := new(DiskBalancerStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LoggingConfigSpec) DeepCopyInto(out *LoggingConfigSpec) {
+func (in *FromImageSpec) DeepCopyInto(out *FromImageSpec) {
 	*out = *in
-	if in.Loggers != nil {
-		in, out := &in.Loggers, &out.Loggers
-		*out = make(map[string]*LogLevelSpec, len(*in))
-		for key, val := range *in {
-			var outVal *LogLevelSpec
-			if val == nil {
-				(*out)[key] = nil
-			} else {
-				inVal := (*in)[key]
-				in, out := &inVal, &outVal
-				*out = new(LogLevelSpec)
-				**out = **in
-			}
-			(*out)[key] = outVal
-		}
+	if in.Pvc != nil {
+		in, out := &in.Pvc, &out.Pvc
+		*out = new(FsImagePvcSource)
+		**out = **in
 	}
-	if in.Console != nil {
-		in, out := &in.Console, &out.Console
-		*out = new(LogLevelSpec)
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(FsImageConfigMapSource)
 		**out = **in
 	}
-	if in.File != nil {
-		in, out := &in.File, &out.File
-		*out = new(LogLevelSpec)
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(FsImageS3Source)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingConfigSpec.
-func (in *LoggingConfigSpec) DeepCopy() *LoggingConfigSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FromImageSpec.
+func (in *FromImageSpec) DeepCopy() *FromImageSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(LoggingConfigSpec)
+	out := new(FromImageSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OidcSpec) DeepCopyInto(out *OidcSpec) {
+func (in *FsImageConfigMapSource) DeepCopyInto(out *FsImageConfigMapSource) {
 	*out = *in
-	if in.ExtraScopes != nil {
-		in, out := &in.ExtraScopes, &out.ExtraScopes
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OidcSpec.
-func (in *OidcSpec) DeepCopy() *OidcSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FsImageConfigMapSource.
+func (in *FsImageConfigMapSource) DeepCopy() *FsImageConfigMapSource {
+	if in == nil {
+		return nil
+	}
+	out := new(FsImageConfigMapSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FsImagePvcSource) DeepCopyInto(out *FsImagePvcSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FsImagePvcSource.
+func (in *FsImagePvcSource) DeepCopy() *FsImagePvcSource {
+	if in == nil {
+		return nil
+	}
+	out := new(FsImagePvcSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FsImageS3Source) DeepCopyInto(out *FsImageS3Source) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FsImageS3Source.
+func (in *FsImageS3Source) DeepCopy() *FsImageS3Source {
+	if in == nil {
+		return nil
+	}
+	out := new(FsImageS3Source)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GroupMappingSpec) DeepCopyInto(out *GroupMappingSpec) {
+	*out = *in
+	if in.Ldap != nil {
+		in, out := &in.Ldap, &out.Ldap
+		*out = new(LdapGroupMappingSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GroupMappingSpec.
+func (in *GroupMappingSpec) DeepCopy() *GroupMappingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GroupMappingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HdfsCluster) DeepCopyInto(out *HdfsCluster) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HdfsCluster.
+func (in *HdfsCluster) DeepCopy() *HdfsCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(HdfsCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HdfsCluster) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HdfsClusterList) DeepCopyInto(out *HdfsClusterList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HdfsCluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HdfsClusterList.
+func (in *HdfsClusterList) DeepCopy() *HdfsClusterList {
+	if in == nil {
+		return nil
+	}
+	out := new(HdfsClusterList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HdfsClusterList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HdfsClusterSpec) DeepCopyInto(out *HdfsClusterSpec) {
+	*out = *in
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(ImageSpec)
+		**out = **in
+	}
+	if in.VectorImage != nil {
+		in, out := &in.VectorImage, &out.VectorImage
+		*out = new(ImageOverrideSpec)
+		**out = **in
+	}
+	if in.Oauth2ProxyImage != nil {
+		in, out := &in.Oauth2ProxyImage, &out.Oauth2ProxyImage
+		*out = new(ImageOverrideSpec)
+		**out = **in
+	}
+	if in.ClusterOperationSpec != nil {
+		in, out := &in.ClusterOperationSpec, &out.ClusterOperationSpec
+		*out = new(commonsv1alpha1.ClusterOperationSpec)
+		**out = **in
+	}
+	if in.ClusterConfig != nil {
+		in, out := &in.ClusterConfig, &out.ClusterConfig
+		*out = new(ClusterConfigSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NameNode != nil {
+		in, out := &in.NameNode, &out.NameNode
+		*out = new(NameNodeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DataNode != nil {
+		in, out := &in.DataNode, &out.DataNode
+		*out = new(DataNodeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.JournalNode != nil {
+		in, out := &in.JournalNode, &out.JournalNode
+		*out = new(RoleSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DiskBalancer != nil {
+		in, out := &in.DiskBalancer, &out.DiskBalancer
+		*out = new(DiskBalancerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RestartRequestedAt != nil {
+		in, out := &in.RestartRequestedAt, &out.RestartRequestedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HdfsClusterSpec.
+func (in *HdfsClusterSpec) DeepCopy() *HdfsClusterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HdfsClusterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HdfsClusterStatus) DeepCopyInto(out *HdfsClusterStatus) {
+	*out = *in
+	in.Status.DeepCopyInto(&out.Status)
+	if in.DiskBalancer != nil {
+		in, out := &in.DiskBalancer, &out.DiskBalancer
+		*out = new(DiskBalancerStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServiceAuthorization != nil {
+		in, out := &in.ServiceAuthorization, &out.ServiceAuthorization
+		*out = new(ConfigRefreshStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProxyUsers != nil {
+		in, out := &in.ProxyUsers, &out.ProxyUsers
+		*out = new(ConfigRefreshStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Health != nil {
+		in, out := &in.Health, &out.Health
+		*out = new(HealthStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BringUp != nil {
+		in, out := &in.BringUp, &out.BringUp
+		*out = new(BringUpStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Stop != nil {
+		in, out := &in.Stop, &out.Stop
+		*out = new(StopStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Restarts != nil {
+		in, out := &in.Restarts, &out.Restarts
+		*out = make(map[string]RestartStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HdfsClusterStatus.
+func (in *HdfsClusterStatus) DeepCopy() *HdfsClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HdfsClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HdfsReplication) DeepCopyInto(out *HdfsReplication) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HdfsReplication.
+func (in *HdfsReplication) DeepCopy() *HdfsReplication {
+	if in == nil {
+		return nil
+	}
+	out := new(HdfsReplication)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HdfsReplication) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HdfsReplicationList) DeepCopyInto(out *HdfsReplicationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HdfsReplication, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HdfsReplicationList.
+func (in *HdfsReplicationList) DeepCopy() *HdfsReplicationList {
+	if in == nil {
+		return nil
+	}
+	out := new(HdfsReplicationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HdfsReplicationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HdfsReplicationSpec) DeepCopyInto(out *HdfsReplicationSpec) {
+	*out = *in
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(ImageSpec)
+		**out = **in
+	}
+	out.Source = in.Source
+	out.Target = in.Target
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]ReplicationPathSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.Bandwidth != nil {
+		in, out := &in.Bandwidth, &out.Bandwidth
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Kerberos != nil {
+		in, out := &in.Kerberos, &out.Kerberos
+		*out = new(ReplicationKerberosSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HdfsReplicationSpec.
+func (in *HdfsReplicationSpec) DeepCopy() *HdfsReplicationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HdfsReplicationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HdfsReplicationStatus) DeepCopyInto(out *HdfsReplicationStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = new(v1.Time)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastSuccessTime != nil {
+		in, out := &in.LastSuccessTime, &out.LastSuccessTime
+		*out = new(v1.Time)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastSyncedTime != nil {
+		in, out := &in.LastSyncedTime, &out.LastSyncedTime
+		*out = new(v1.Time)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LagSeconds != nil {
+		in, out := &in.LagSeconds, &out.LagSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HdfsReplicationStatus.
+func (in *HdfsReplicationStatus) DeepCopy() *HdfsReplicationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HdfsReplicationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HealthStatus) DeepCopyInto(out *HealthStatus) {
+	*out = *in
+	if in.NameNodes != nil {
+		in, out := &in.NameNodes, &out.NameNodes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LastTransitionTime != nil {
+		in, out := &in.LastTransitionTime, &out.LastTransitionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthStatus.
+func (in *HealthStatus) DeepCopy() *HealthStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HealthStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageOverrideSpec) DeepCopyInto(out *ImageOverrideSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageOverrideSpec.
+func (in *ImageOverrideSpec) DeepCopy() *ImageOverrideSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageOverrideSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageSpec) DeepCopyInto(out *ImageSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageSpec.
+func (in *ImageSpec) DeepCopy() *ImageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JmxExporterRulesSpec) DeepCopyInto(out *JmxExporterRulesSpec) {
+	*out = *in
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(ConfigMapKeyRefSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JmxExporterRulesSpec.
+func (in *JmxExporterRulesSpec) DeepCopy() *JmxExporterRulesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(JmxExporterRulesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KerberosPrincipalsSpec) DeepCopyInto(out *KerberosPrincipalsSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KerberosPrincipalsSpec.
+func (in *KerberosPrincipalsSpec) DeepCopy() *KerberosPrincipalsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KerberosPrincipalsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KerberosSpec) DeepCopyInto(out *KerberosSpec) {
+	*out = *in
+	if in.Principals != nil {
+		in, out := &in.Principals, &out.Principals
+		*out = new(KerberosPrincipalsSpec)
+		**out = **in
+	}
+	if in.AdditionalTrustedRealms != nil {
+		in, out := &in.AdditionalTrustedRealms, &out.AdditionalTrustedRealms
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AuthToLocal != nil {
+		in, out := &in.AuthToLocal, &out.AuthToLocal
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DataTransfer != nil {
+		in, out := &in.DataTransfer, &out.DataTransfer
+		*out = new(DataTransferSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KerberosSpec.
+func (in *KerberosSpec) DeepCopy() *KerberosSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KerberosSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LdapGroupMappingSpec) DeepCopyInto(out *LdapGroupMappingSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LdapGroupMappingSpec.
+func (in *LdapGroupMappingSpec) DeepCopy() *LdapGroupMappingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LdapGroupMappingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogLevelSpec) DeepCopyInto(out *LogLevelSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogLevelSpec.
+func (in *LogLevelSpec) DeepCopy() *LogLevelSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LogLevelSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoggingConfigSpec) DeepCopyInto(out *LoggingConfigSpec) {
+	*out = *in
+	if in.Loggers != nil {
+		in, out := &in.Loggers, &out.Loggers
+		*out = make(map[string]*LogLevelSpec, len(*in))
+		for key, val := range *in {
+			var outVal *LogLevelSpec
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = new(LogLevelSpec)
+				**out = **in
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.Console != nil {
+		in, out := &in.Console, &out.Console
+		*out = new(LogLevelSpec)
+		**out = **in
+	}
+	if in.File != nil {
+		in, out := &in.File, &out.File
+		*out = new(LogLevelSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingConfigSpec.
+func (in *LoggingConfigSpec) DeepCopy() *LoggingConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LoggingConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsSpec) DeepCopyInto(out *MetricsSpec) {
+	*out = *in
+	if in.JmxExporterEnabled != nil {
+		in, out := &in.JmxExporterEnabled, &out.JmxExporterEnabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.JmxExporterRules != nil {
+		in, out := &in.JmxExporterRules, &out.JmxExporterRules
+		*out = new(JmxExporterRulesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsSpec.
+func (in *MetricsSpec) DeepCopy() *MetricsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitoringSpec) DeepCopyInto(out *MonitoringSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Alerts != nil {
+		in, out := &in.Alerts, &out.Alerts
+		*out = new(AlertsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringSpec.
+func (in *MonitoringSpec) DeepCopy() *MonitoringSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitoringSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NameNodeSpec) DeepCopyInto(out *NameNodeSpec) {
+	*out = *in
+	in.RoleSpec.DeepCopyInto(&out.RoleSpec)
+	if in.Bootstrap != nil {
+		in, out := &in.Bootstrap, &out.Bootstrap
+		*out = new(BootstrapSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AuditLog != nil {
+		in, out := &in.AuditLog, &out.AuditLog
+		*out = new(AuditLogSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NameNodeSpec.
+func (in *NameNodeSpec) DeepCopy() *NameNodeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NameNodeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OidcSpec) DeepCopyInto(out *OidcSpec) {
+	*out = *in
+	if in.ExtraScopes != nil {
+		in, out := &in.ExtraScopes, &out.ExtraScopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedGroups != nil {
+		in, out := &in.AllowedGroups, &out.AllowedGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedEmails != nil {
+		in, out := &in.AllowedEmails, &out.AllowedEmails
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SkipAuthPaths != nil {
+		in, out := &in.SkipAuthPaths, &out.SkipAuthPaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CookieLifetime != nil {
+		in, out := &in.CookieLifetime, &out.CookieLifetime
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.UpstreamTlsVerification != nil {
+		in, out := &in.UpstreamTlsVerification, &out.UpstreamTlsVerification
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OidcSpec.
+func (in *OidcSpec) DeepCopy() *OidcSpec {
 	if in == nil {
 		return nil
 	}
@@ -379,6 +1220,21 @@ func (in *OidcSpec) DeepCopy() *OidcSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpaSpec) DeepCopyInto(out *OpaSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpaSpec.
+func (in *OpaSpec) DeepCopy() *OpaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OpaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodDisruptionBudgetSpec) DeepCopyInto(out *PodDisruptionBudgetSpec) {
 	*out = *in
@@ -394,6 +1250,155 @@ func (in *PodDisruptionBudgetSpec) DeepCopy() *PodDisruptionBudgetSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProxyUserSpec) DeepCopyInto(out *ProxyUserSpec) {
+	*out = *in
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxyUserSpec.
+func (in *ProxyUserSpec) DeepCopy() *ProxyUserSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProxyUserSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PvcBackupSpec) DeepCopyInto(out *PvcBackupSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PvcBackupSpec.
+func (in *PvcBackupSpec) DeepCopy() *PvcBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PvcBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicationClusterSpec) DeepCopyInto(out *ReplicationClusterSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationClusterSpec.
+func (in *ReplicationClusterSpec) DeepCopy() *ReplicationClusterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicationClusterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicationKerberosSpec) DeepCopyInto(out *ReplicationKerberosSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationKerberosSpec.
+func (in *ReplicationKerberosSpec) DeepCopy() *ReplicationKerberosSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicationKerberosSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicationPathSpec) DeepCopyInto(out *ReplicationPathSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationPathSpec.
+func (in *ReplicationPathSpec) DeepCopy() *ReplicationPathSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicationPathSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestartStatus) DeepCopyInto(out *RestartStatus) {
+	*out = *in
+	if in.RequestedAt != nil {
+		in, out := &in.RequestedAt, &out.RequestedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.LastRestartTime != nil {
+		in, out := &in.LastRestartTime, &out.LastRestartTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestartStatus.
+func (in *RestartStatus) DeepCopy() *RestartStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RestartStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreSpec) DeepCopyInto(out *RestoreSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreSpec.
+func (in *RestoreSpec) DeepCopy() *RestoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoleConfigSpec) DeepCopyInto(out *RoleConfigSpec) {
+	*out = *in
+	in.RoleConfigSpec.DeepCopyInto(&out.RoleConfigSpec)
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = new(MetricsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleConfigSpec.
+func (in *RoleConfigSpec) DeepCopy() *RoleConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RoleConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RoleGroupSpec) DeepCopyInto(out *RoleGroupSpec) {
 	*out = *in
@@ -407,6 +1412,10 @@ func (in *RoleGroupSpec) DeepCopyInto(out *RoleGroupSpec) {
 		*out = new(ConfigSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.RestartRequestedAt != nil {
+		in, out := &in.RestartRequestedAt, &out.RestartRequestedAt
+		*out = (*in).DeepCopy()
+	}
 	if in.OverridesSpec != nil {
 		in, out := &in.OverridesSpec, &out.OverridesSpec
 		*out = new(commonsv1alpha1.OverridesSpec)
@@ -427,6 +1436,11 @@ func (in *RoleGroupSpec) DeepCopy() *RoleGroupSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RoleSpec) DeepCopyInto(out *RoleSpec) {
 	*out = *in
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(ImageOverrideSpec)
+		**out = **in
+	}
 	if in.Config != nil {
 		in, out := &in.Config, &out.Config
 		*out = new(ConfigSpec)
@@ -441,9 +1455,13 @@ func (in *RoleSpec) DeepCopyInto(out *RoleSpec) {
 	}
 	if in.RoleConfig != nil {
 		in, out := &in.RoleConfig, &out.RoleConfig
-		*out = new(commonsv1alpha1.RoleConfigSpec)
+		*out = new(RoleConfigSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.RestartRequestedAt != nil {
+		in, out := &in.RestartRequestedAt, &out.RestartRequestedAt
+		*out = (*in).DeepCopy()
+	}
 	if in.OverridesSpec != nil {
 		in, out := &in.OverridesSpec, &out.OverridesSpec
 		*out = new(commonsv1alpha1.OverridesSpec)
@@ -461,6 +1479,151 @@ func (in *RoleSpec) DeepCopy() *RoleSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3BackupSpec) DeepCopyInto(out *S3BackupSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3BackupSpec.
+func (in *S3BackupSpec) DeepCopy() *S3BackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(S3BackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretKeyRefSpec) DeepCopyInto(out *SecretKeyRefSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyRefSpec.
+func (in *SecretKeyRefSpec) DeepCopy() *SecretKeyRefSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretKeyRefSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceAclSpec) DeepCopyInto(out *ServiceAclSpec) {
+	*out = *in
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceAclSpec.
+func (in *ServiceAclSpec) DeepCopy() *ServiceAclSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceAclSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceAuthorizationSpec) DeepCopyInto(out *ServiceAuthorizationSpec) {
+	*out = *in
+	if in.Default != nil {
+		in, out := &in.Default, &out.Default
+		*out = new(ServiceAclSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Client != nil {
+		in, out := &in.Client, &out.Client
+		*out = new(ServiceAclSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientDatanode != nil {
+		in, out := &in.ClientDatanode, &out.ClientDatanode
+		*out = new(ServiceAclSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Datanode != nil {
+		in, out := &in.Datanode, &out.Datanode
+		*out = new(ServiceAclSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InterDatanode != nil {
+		in, out := &in.InterDatanode, &out.InterDatanode
+		*out = new(ServiceAclSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Namenode != nil {
+		in, out := &in.Namenode, &out.Namenode
+		*out = new(ServiceAclSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HaAdmin != nil {
+		in, out := &in.HaAdmin, &out.HaAdmin
+		*out = new(ServiceAclSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Zkfc != nil {
+		in, out := &in.Zkfc, &out.Zkfc
+		*out = new(ServiceAclSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Qjournal != nil {
+		in, out := &in.Qjournal, &out.Qjournal
+		*out = new(ServiceAclSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InterQjournal != nil {
+		in, out := &in.InterQjournal, &out.InterQjournal
+		*out = new(ServiceAclSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RefreshPolicy != nil {
+		in, out := &in.RefreshPolicy, &out.RefreshPolicy
+		*out = new(ServiceAclSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RefreshUserMappings != nil {
+		in, out := &in.RefreshUserMappings, &out.RefreshUserMappings
+		*out = new(ServiceAclSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RefreshCallQueue != nil {
+		in, out := &in.RefreshCallQueue, &out.RefreshCallQueue
+		*out = new(ServiceAclSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GetUserMappings != nil {
+		in, out := &in.GetUserMappings, &out.GetUserMappings
+		*out = new(ServiceAclSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Reconfiguration != nil {
+		in, out := &in.Reconfiguration, &out.Reconfiguration
+		*out = new(ServiceAclSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceAuthorizationSpec.
+func (in *ServiceAuthorizationSpec) DeepCopy() *ServiceAuthorizationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceAuthorizationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceSpec) DeepCopyInto(out *ServiceSpec) {
 	*out = *in
@@ -483,9 +1646,33 @@ func (in *ServiceSpec) DeepCopy() *ServiceSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StopStatus) DeepCopyInto(out *StopStatus) {
+	*out = *in
+	if in.LastTransitionTime != nil {
+		in, out := &in.LastTransitionTime, &out.LastTransitionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StopStatus.
+func (in *StopStatus) DeepCopy() *StopStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StopStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TlsSpec) DeepCopyInto(out *TlsSpec) {
 	*out = *in
+	if in.JksPasswordSecretRef != nil {
+		in, out := &in.JksPasswordSecretRef, &out.JksPasswordSecretRef
+		*out = new(SecretKeyRefSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TlsSpec.
@@ -497,3 +1684,23 @@ func (in *TlsSpec) DeepCopy() *TlsSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaitForNameNodesSpec) DeepCopyInto(out *WaitForNameNodesSpec) {
+	*out = *in
+	if in.WaitForSafeModeExit != nil {
+		in, out := &in.WaitForSafeModeExit, &out.WaitForSafeModeExit
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaitForNameNodesSpec.
+func (in *WaitForNameNodesSpec) DeepCopy() *WaitForNameNodesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WaitForNameNodesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
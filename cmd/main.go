@@ -21,6 +21,8 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -28,10 +30,12 @@ import (
 
 	authv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/authentication/v1alpha1"
 	listenerv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/listeners/v1alpha1"
+	s3v1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/s3/v1alpha1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -55,10 +59,15 @@ func init() {
 	utilruntime.Must(authv1alpha1.AddToScheme(scheme))
 	utilruntime.Must(hdfsv1alpha1.AddToScheme(scheme))
 	utilruntime.Must(listenerv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(s3v1alpha1.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == renderCommand {
+		os.Exit(runRender(os.Args[2:]))
+	}
+
 	var metricsAddr string
 	var metricsCertPath, metricsCertName, metricsCertKey string
 	var webhookCertPath, webhookCertName, webhookCertKey string
@@ -67,6 +76,9 @@ func main() {
 	var secureMetrics bool
 	var enableHTTP2 bool
 	var showVersion bool
+	var observeInterval time.Duration
+	var clusterHealthProbe bool
+	var watchNamespaces string
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -86,6 +98,13 @@ func main() {
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
 	flag.BoolVar(&showVersion, "version", false, "Print version information and exit.")
+	flag.DurationVar(&observeInterval, "cluster-observe-interval", controller.DefaultObserveInterval,
+		"The interval the operator observes the running clusters for events and their health.")
+	flag.BoolVar(&clusterHealthProbe, "cluster-health-probe", false,
+		"If set, the health read from the namenodes is exported as metrics of the operator and in the cluster status.")
+	flag.StringVar(&watchNamespaces, "watch-namespaces", os.Getenv("WATCH_NAMESPACE"),
+		"Comma separated namespaces the operator watches, all namespaces if empty. "+
+			"Defaults to the WATCH_NAMESPACE environment variable.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -169,6 +188,7 @@ func main() {
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
+		Cache:                  cacheOptions(watchNamespaces),
 		Metrics:                metricsServerOptions,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
@@ -191,15 +211,33 @@ func main() {
 		os.Exit(1)
 	}
 
+	recorder := controller.NewEventRecorder(mgr.GetEventRecorder("hdfs-operator"), controller.DefaultEventInterval)
 	if err = (&controller.HdfsClusterReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Log:      setupLog,
+		Recorder: recorder,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "HdfsCluster")
+		os.Exit(1)
+	}
+	if err = (&controller.HdfsReplicationReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 		Log:    setupLog,
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "HdfsCluster")
+		setupLog.Error(err, "unable to create controller", "controller", "HdfsReplication")
 		os.Exit(1)
 	}
 
+	var clusterHealth *controller.ClusterHealth
+	if clusterHealthProbe {
+		clusterHealth = controller.NewClusterHealth(mgr.GetClient())
+	}
+	if err := mgr.Add(controller.NewClusterObserver(mgr.GetClient(), recorder, clusterHealth, observeInterval)); err != nil {
+		setupLog.Error(err, "unable to set up cluster observer")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -217,3 +255,23 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// cacheOptions restricts the caches of the manager to the watched namespaces, so the operator only needs access
+// to them. All namespaces are watched if there is none.
+func cacheOptions(watchNamespaces string) cache.Options {
+	options := cache.Options{}
+	for _, namespace := range strings.Split(watchNamespaces, ",") {
+		namespace = strings.TrimSpace(namespace)
+		if namespace == "" {
+			continue
+		}
+		if options.DefaultNamespaces == nil {
+			options.DefaultNamespaces = make(map[string]cache.Config)
+		}
+		options.DefaultNamespaces[namespace] = cache.Config{}
+	}
+	if len(options.DefaultNamespaces) > 0 {
+		setupLog.Info("Watching namespaces", "namespaces", watchNamespaces)
+	}
+	return options
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"emperror.dev/errors"
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/render"
+)
+
+// renderCommand is the subcommand which renders the objects of a cluster instead of running the operator
+const renderCommand = "render"
+
+// Exit codes of the render command, like kubectl diff
+const (
+	renderExitDiffers = 1
+	renderExitFailed  = 2
+)
+
+// runRender renders the objects the operator creates for the HdfsCluster of a file and prints them as YAML,
+// or as a diff against the live objects. It returns the exit code.
+func runRender(args []string) int {
+	var file string
+	var namespace string
+	var diff bool
+	var verbose bool
+	flag.StringVar(&file, "f", "", "The file with the HdfsCluster and the objects it references, - for stdin.")
+	flag.StringVar(&namespace, "n", "default", "The namespace of the objects of the file without a namespace.")
+	flag.BoolVar(&diff, "diff", false,
+		"If set, the rendered objects are compared with the live objects of the cluster in the current kubeconfig context.")
+	flag.BoolVar(&verbose, "v", false, "If set, the reconcile of the cluster is logged to stderr.")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s %s -f cluster.yaml [--diff]\n", os.Args[0], renderCommand)
+		flag.PrintDefaults()
+	}
+	if err := flag.CommandLine.Parse(args); err != nil {
+		return renderExitFailed
+	}
+	if file == "" {
+		flag.Usage()
+		return renderExitFailed
+	}
+
+	if verbose {
+		ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
+	} else {
+		ctrl.SetLogger(logr.Discard())
+	}
+
+	if err := renderCluster(ctrl.SetupSignalHandler(), file, namespace, diff, os.Stdout); err != nil {
+		if errors.Is(err, errRenderDiffers) {
+			return renderExitDiffers
+		}
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		return renderExitFailed
+	}
+	return 0
+}
+
+var errRenderDiffers = errors.New("rendered objects differ from the live objects")
+
+func renderCluster(ctx context.Context, file string, namespace string, diff bool, out io.Writer) error {
+	input := os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }()
+		input = f
+	}
+	cluster, objects, err := render.Load(input, namespace)
+	if err != nil {
+		return err
+	}
+
+	if !diff {
+		rendered, err := render.Render(ctx, cluster, objects, nil)
+		if err != nil {
+			return err
+		}
+		return render.Write(out, rendered)
+	}
+
+	config, err := ctrl.GetConfig()
+	if err != nil {
+		return err
+	}
+	live, err := ctrlclient.New(config, ctrlclient.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+	// the live cluster owns the live objects and its status decides the stopped roles
+	liveCluster := &hdfsv1alpha1.HdfsCluster{}
+	if err := live.Get(ctx, ctrlclient.ObjectKeyFromObject(cluster), liveCluster); err == nil {
+		cluster.UID = liveCluster.UID
+		cluster.Status = liveCluster.Status
+	} else if !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	rendered, err := render.Render(ctx, cluster, objects, live)
+	if err != nil {
+		return err
+	}
+	liveObjects, err := render.Objects(ctx, live, cluster)
+	if err != nil {
+		return err
+	}
+	differs, err := render.Diff(out, rendered, liveObjects)
+	if err != nil {
+		return err
+	}
+	if differs {
+		return errRenderDiffers
+	}
+	return nil
+}
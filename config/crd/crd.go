@@ -0,0 +1,8 @@
+// Package crd embeds the CustomResourceDefinitions generated by controller-gen, for the commands which need
+// the schemas of the resources, like the render command applying their defaults.
+package crd
+
+import "embed"
+
+//go:embed bases/*.yaml
+var Bases embed.FS
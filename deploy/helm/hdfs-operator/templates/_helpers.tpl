@@ -89,3 +89,143 @@ Get health probe port from bind address
 {{- $bindAddress := .Values.healthProbe.bindAddress | default ":8081" }}
 {{- regexFind "[0-9]+$" $bindAddress }}
 {{- end }}
+
+{{/*
+Rules of the operator for the namespaced resources of the clusters, granted with a ClusterRole or with a Role
+in each watched namespace
+*/}}
+{{- define "operator.namespacedRules" -}}
+- apiGroups:
+  - ""
+  resources:
+  - configmaps
+  - secrets
+  - serviceaccounts
+  - services
+  verbs:
+  - create
+  - delete
+  - get
+  - list
+  - patch
+  - update
+  - watch
+- apiGroups:
+  - ""
+  resources:
+  - pods
+  verbs:
+  - delete
+  - get
+  - list
+  - watch
+- apiGroups:
+  - apps
+  resources:
+  - statefulsets
+  verbs:
+  - create
+  - delete
+  - get
+  - list
+  - patch
+  - update
+  - watch
+- apiGroups:
+  - batch
+  resources:
+  - cronjobs
+  - jobs
+  verbs:
+  - create
+  - delete
+  - get
+  - list
+  - patch
+  - update
+  - watch
+- apiGroups:
+  - events.k8s.io
+  resources:
+  - events
+  verbs:
+  - create
+  - patch
+- apiGroups:
+  - hdfs.kubedoop.dev
+  resources:
+  - hdfsclusters
+  - hdfsreplications
+  verbs:
+  - create
+  - delete
+  - get
+  - list
+  - patch
+  - update
+  - watch
+- apiGroups:
+  - hdfs.kubedoop.dev
+  resources:
+  - hdfsclusters/finalizers
+  - hdfsreplications/finalizers
+  verbs:
+  - update
+- apiGroups:
+  - hdfs.kubedoop.dev
+  resources:
+  - hdfsclusters/status
+  - hdfsreplications/status
+  verbs:
+  - get
+  - patch
+  - update
+- apiGroups:
+  - listeners.kubedoop.dev
+  resources:
+  - listeners
+  verbs:
+  - create
+  - delete
+  - get
+  - list
+  - patch
+  - update
+  - watch
+- apiGroups:
+  - monitoring.coreos.com
+  resources:
+  - prometheusrules
+  - servicemonitors
+  verbs:
+  - create
+  - delete
+  - get
+  - list
+  - patch
+  - update
+  - watch
+- apiGroups:
+  - s3.kubedoop.dev
+  resources:
+  - s3buckets
+  - s3connections
+  verbs:
+  - get
+  - list
+  - watch
+{{- end }}
+
+{{/*
+Rules of the operator for cluster scoped resources, always granted with a ClusterRole
+*/}}
+{{- define "operator.clusterRules" -}}
+- apiGroups:
+  - authentication.kubedoop.dev
+  resources:
+  - authenticationclasses
+  verbs:
+  - get
+  - list
+  - watch
+{{- end }}
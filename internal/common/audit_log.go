@@ -0,0 +1,144 @@
+package common
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"emperror.dev/errors"
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/constant"
+	"github.com/zncdatadev/operator-go/pkg/constants"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// AuditLogType is the `logType` of the audit events in the vector pipeline,
+	// the aggregator can route them with the condition `.logType == "audit"`
+	AuditLogType = "audit"
+
+	// defaults of the CRD
+	defaultAuditLogMaxFileSize    = "10MB"
+	defaultAuditLogMaxBackupIndex = 5
+
+	auditLoggerName     = "org.apache.hadoop.hdfs.server.namenode.FSNamesystem.audit"
+	auditLogFileName    = "namenode.audit.json"
+	vectorTransformsKey = "\ntransforms:\n"
+)
+
+// AuditLogFile is the file of the audit events in the log volume of the namenode container
+var AuditLogFile = path.Join(constants.KubedoopLogDir, string(constant.NameNodeComponent), auditLogFileName)
+
+// GetAuditLog returns the audit log of the namenodes, it is nil if the audit log is disabled
+func GetAuditLog(instance *hdfsv1alpha1.HdfsCluster) *hdfsv1alpha1.AuditLogSpec {
+	if instance.Spec.NameNode == nil {
+		return nil
+	}
+	return instance.Spec.NameNode.AuditLog
+}
+
+// MakeAuditLog4jProperties routes the audit logger into its own rolling file,
+// `Log4Json` of hadoop-common writes one JSON object per event.
+func MakeAuditLog4jProperties(auditLog *hdfsv1alpha1.AuditLogSpec) string {
+	return fmt.Sprintf(`
+log4j.logger.%[1]s=INFO, AUDIT
+log4j.additivity.%[1]s=false
+log4j.appender.AUDIT=org.apache.log4j.RollingFileAppender
+log4j.appender.AUDIT.File=%[2]s
+log4j.appender.AUDIT.MaxFileSize=%[3]s
+log4j.appender.AUDIT.MaxBackupIndex=%[4]d
+log4j.appender.AUDIT.layout=org.apache.hadoop.log.Log4Json
+`, auditLoggerName, AuditLogFile, auditLogMaxFileSize(auditLog), auditLogMaxBackupIndex(auditLog))
+}
+
+// AuditLogSizeLimit returns the space of the current and all rolled audit log files
+func AuditLogSizeLimit(auditLog *hdfsv1alpha1.AuditLogSpec) (resource.Quantity, error) {
+	maxFileSize := auditLogMaxFileSize(auditLog)
+	// log4j sizes are binary, 10MB is 10Mi
+	fileSize, err := resource.ParseQuantity(strings.TrimSuffix(maxFileSize, "B") + "i")
+	if err != nil {
+		return resource.Quantity{}, errors.WrapIfWithDetails(err, "invalid maxFileSize of the audit log", "maxFileSize", maxFileSize)
+	}
+	limit := resource.NewQuantity(fileSize.Value()*int64(auditLogMaxBackupIndex(auditLog)+1), resource.BinarySI)
+	return *limit, nil
+}
+
+func auditLogMaxFileSize(auditLog *hdfsv1alpha1.AuditLogSpec) string {
+	if auditLog.MaxFileSize == "" {
+		return defaultAuditLogMaxFileSize
+	}
+	return auditLog.MaxFileSize
+}
+
+func auditLogMaxBackupIndex(auditLog *hdfsv1alpha1.AuditLogSpec) int32 {
+	if auditLog.MaxBackupIndex == 0 {
+		return defaultAuditLogMaxBackupIndex
+	}
+	return auditLog.MaxBackupIndex
+}
+
+// auditVectorSourceConfig reads the audit file as a dedicated source, the transform matches
+// `processed_files_*`, so the events get the same container, cluster and role fields as the other logs
+const auditVectorSourceConfig = `
+  files_audit:
+    type: file
+    include:
+      - %s
+`
+
+const auditVectorTransformConfig = `  processed_files_audit:
+    inputs:
+      - files_audit
+    type: remap
+    source: |
+      raw_message = string!(.message)
+
+      .timestamp = now()
+      .logger = "FSNamesystem.audit"
+      .level = "INFO"
+      .message = raw_message
+      .errors = []
+      .logType = "%s"
+
+      parsed_event, err = parse_json(raw_message)
+      if err != null {
+        error = "JSON not parsable: " + err
+        .errors = push(.errors, error)
+        log(error, level: "warn")
+      } else {
+        event = object(parsed_event) ?? {}
+
+        epoch_milliseconds, err = to_int(event.time)
+        if err == null && epoch_milliseconds != 0 {
+          .timestamp = from_unix_timestamp!(epoch_milliseconds, "milliseconds")
+        } else {
+          .errors = push(.errors, "Timestamp not found, using current time instead.")
+        }
+        .logger = string(event.name) ?? .logger
+        .level = string(event.level) ?? .level
+        .message = string(event.message) ?? raw_message
+
+        # the fields of the event are separated by tabs, e.g. allowed=true ugi=alice cmd=open src=/tmp
+        .audit, err = parse_key_value(.message, key_value_delimiter: "=", field_delimiter: "\t", whitespace: "strict")
+        if err != null {
+          .errors = push(.errors, "Audit event not parsable: " + err)
+        }
+      }
+
+`
+
+// ExtendVectorYamlByAuditLog adds the audit file source to the vector.yaml of the namenodes
+func ExtendVectorYamlByAuditLog(vectorYaml string) (string, error) {
+	i := strings.Index(vectorYaml, vectorTransformsKey)
+	if i < 0 {
+		return "", errors.New("vector.yaml has no transforms, can not add the audit log source")
+	}
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(vectorYaml[:i], "\n"))
+	b.WriteString("\n")
+	b.WriteString(fmt.Sprintf(auditVectorSourceConfig, AuditLogFile))
+	b.WriteString(vectorTransformsKey)
+	b.WriteString(fmt.Sprintf(auditVectorTransformConfig, AuditLogType))
+	b.WriteString(vectorYaml[i+len(vectorTransformsKey):])
+	return b.String(), nil
+}
@@ -0,0 +1,151 @@
+package common
+
+import (
+	"sort"
+
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/constant"
+	"github.com/zncdatadev/operator-go/pkg/constants"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	BackupVolumeName        = "backup"
+	BackupDir               = constants.KubedoopRoot + "backup"
+	S3CredentialsVolumeName = "s3-credentials"
+	S3CredentialsDir        = constants.KubedoopSecretDir + "s3-credentials"
+
+	// BackupClusterPropertiesFileName is stored next to the fsimage of every backup,
+	// it records the ids which must be kept when restoring.
+	BackupClusterPropertiesFileName = "cluster.properties"
+)
+
+func IsBackupEnabled(clusterConfig *hdfsv1alpha1.ClusterConfigSpec) bool {
+	return clusterConfig.Backup != nil && (clusterConfig.Backup.S3 != nil || clusterConfig.Backup.Pvc != nil)
+}
+
+func IsRestoreEnabled(clusterConfig *hdfsv1alpha1.ClusterConfigSpec) bool {
+	return IsBackupEnabled(clusterConfig) && clusterConfig.Backup.Restore != nil
+}
+
+// BackupVolumes returns the volumes of the backup location
+func BackupVolumes(clusterConfig *hdfsv1alpha1.ClusterConfigSpec, s3Target *S3Target, readOnly bool) []corev1.Volume {
+	backup := clusterConfig.Backup
+	var volumes []corev1.Volume
+	if backup.Pvc != nil {
+		volumes = append(volumes, corev1.Volume{
+			Name: BackupVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: backup.Pvc.ClaimName,
+					ReadOnly:  readOnly,
+				},
+			},
+		})
+	}
+	if backup.S3 != nil && s3Target != nil && s3Target.Credentials != nil {
+		volumes = append(volumes, *CredentialsVolume(S3CredentialsVolumeName, s3Target.Credentials))
+	}
+	return volumes
+}
+
+// BackupVolumeMounts returns the volume mounts of the backup location
+func BackupVolumeMounts(clusterConfig *hdfsv1alpha1.ClusterConfigSpec, s3Target *S3Target, readOnly bool) []corev1.VolumeMount {
+	backup := clusterConfig.Backup
+	var mounts []corev1.VolumeMount
+	if backup.Pvc != nil {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      BackupVolumeName,
+			MountPath: BackupDir,
+			ReadOnly:  readOnly,
+		})
+	}
+	if backup.S3 != nil && s3Target != nil && s3Target.Credentials != nil {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      S3CredentialsVolumeName,
+			MountPath: S3CredentialsDir,
+		})
+	}
+	return mounts
+}
+
+// BackupEnvVars returns the env vars required to access the backup location
+func BackupEnvVars(clusterConfig *hdfsv1alpha1.ClusterConfigSpec) []corev1.EnvVar {
+	if clusterConfig.Backup.S3 != nil {
+		return []corev1.EnvVar{
+			{
+				Name:  "HADOOP_OPTIONAL_TOOLS",
+				Value: "hadoop-aws",
+			},
+		}
+	}
+	return nil
+}
+
+const fetchBackupTemplate = `RESTORE_DIR={{ .restoreDir }}
+rm -rf "$RESTORE_DIR"
+mkdir -p "$RESTORE_DIR"
+BACKUP={{ .backup }}
+{{- if .s3 }}
+{{ .exportS3Credentials }}
+if [ "$BACKUP" == "latest" ]
+then
+    BACKUP=$(/kubedoop/hadoop/bin/hdfs dfs {{ .s3Options }} -ls -C "{{ .s3Uri }}/" | sort | tail -n1 | xargs basename)
+fi
+echo "Fetching backup $BACKUP from {{ .s3Uri }}"
+/kubedoop/hadoop/bin/hdfs dfs {{ .s3Options }} -get "{{ .s3Uri }}/$BACKUP/*" "$RESTORE_DIR"
+{{- else }}
+if [ "$BACKUP" == "latest" ]
+then
+    BACKUP=$(ls -1 {{ .backupDir }} | sort | tail -n1)
+fi
+echo "Fetching backup $BACKUP from {{ .backupDir }}"
+cp -r "{{ .backupDir }}/$BACKUP/." "$RESTORE_DIR"
+{{- end }}
+`
+
+// FetchBackupScript returns a script which copies the backup selected by the restore spec into restoreDir
+func FetchBackupScript(clusterConfig *hdfsv1alpha1.ClusterConfigSpec, s3Target *S3Target, restoreDir string) string {
+	backup := clusterConfig.Backup.Restore.Backup
+	if backup == "" {
+		backup = hdfsv1alpha1.LatestBackup
+	}
+	data := map[string]interface{}{
+		"restoreDir": restoreDir,
+		"backup":     backup,
+		"backupDir":  BackupDir,
+		"s3":         clusterConfig.Backup.S3 != nil && s3Target != nil,
+	}
+	if s3Target != nil {
+		data["s3Uri"] = s3Target.Uri()
+		data["s3Options"] = s3Target.HadoopOptions()
+		// missing keys would be rendered as "<no value>"
+		data["exportS3Credentials"] = ""
+		if s3Target.Credentials != nil {
+			data["exportS3Credentials"] = ExportS3CredentialsScript(S3CredentialsDir)
+		}
+	}
+	return ParseTemplate(fetchBackupTemplate, data)[0]
+}
+
+// GetNameNodePodNames returns the pod names of all namenode role groups, sorted by name
+func GetNameNodePodNames(instance *hdfsv1alpha1.HdfsCluster, clusterInfo reconciler.ClusterInfo) []string {
+	if instance.Spec.NameNode == nil {
+		return nil
+	}
+	var podNames []string
+	for groupName, roleGroup := range instance.Spec.NameNode.RoleGroups {
+		roleGroupInfo := reconciler.RoleGroupInfo{
+			RoleInfo: reconciler.RoleInfo{
+				ClusterInfo: clusterInfo,
+				RoleName:    string(constant.NameNode),
+			},
+			RoleGroupName: groupName,
+		}
+		podNames = append(podNames, CreatePodNamesByReplicas(ptr.Deref(roleGroup.Replicas, 1), roleGroupInfo.GetFullName())...)
+	}
+	sort.Strings(podNames)
+	return podNames
+}
@@ -0,0 +1,132 @@
+package common
+
+import (
+	"path"
+
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/operator-go/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	BootstrapImageVolumeName         = "bootstrap-image"
+	BootstrapImageDir                = constants.KubedoopRoot + "bootstrap-image"
+	BootstrapS3CredentialsVolumeName = "bootstrap-s3-credentials"
+	BootstrapS3CredentialsDir        = constants.KubedoopSecretDir + "bootstrap-s3-credentials"
+)
+
+// GetBootstrapFromImage returns the fromImage spec of the namenode, or nil if the namenode is formatted as usual
+func GetBootstrapFromImage(instance *hdfsv1alpha1.HdfsCluster) *hdfsv1alpha1.FromImageSpec {
+	nameNode := instance.Spec.NameNode
+	if nameNode == nil || nameNode.Bootstrap == nil {
+		return nil
+	}
+	return nameNode.Bootstrap.FromImage
+}
+
+// BootstrapImageVolumes returns the volumes of the fromImage location
+func BootstrapImageVolumes(fromImage *hdfsv1alpha1.FromImageSpec, s3Target *S3Target) []corev1.Volume {
+	var volumes []corev1.Volume
+	switch {
+	case fromImage.Pvc != nil:
+		volumes = append(volumes, corev1.Volume{
+			Name: BootstrapImageVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: fromImage.Pvc.ClaimName,
+					ReadOnly:  true,
+				},
+			},
+		})
+	case fromImage.ConfigMap != nil:
+		volumes = append(volumes, corev1.Volume{
+			Name: BootstrapImageVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: fromImage.ConfigMap.Name},
+				},
+			},
+		})
+	case fromImage.S3 != nil && s3Target != nil && s3Target.Credentials != nil:
+		volumes = append(volumes, *CredentialsVolume(BootstrapS3CredentialsVolumeName, s3Target.Credentials))
+	}
+	return volumes
+}
+
+// BootstrapImageVolumeMounts returns the volume mounts of the fromImage location
+func BootstrapImageVolumeMounts(fromImage *hdfsv1alpha1.FromImageSpec, s3Target *S3Target) []corev1.VolumeMount {
+	switch {
+	case fromImage.Pvc != nil, fromImage.ConfigMap != nil:
+		return []corev1.VolumeMount{
+			{
+				Name:      BootstrapImageVolumeName,
+				MountPath: BootstrapImageDir,
+				ReadOnly:  true,
+			},
+		}
+	case fromImage.S3 != nil && s3Target != nil && s3Target.Credentials != nil:
+		return []corev1.VolumeMount{
+			{
+				Name:      BootstrapS3CredentialsVolumeName,
+				MountPath: BootstrapS3CredentialsDir,
+			},
+		}
+	}
+	return nil
+}
+
+// BootstrapImageEnvVars returns the env vars required to access the fromImage location
+func BootstrapImageEnvVars(fromImage *hdfsv1alpha1.FromImageSpec) []corev1.EnvVar {
+	if fromImage.S3 != nil {
+		return []corev1.EnvVar{
+			{
+				Name:  "HADOOP_OPTIONAL_TOOLS",
+				Value: "hadoop-aws",
+			},
+		}
+	}
+	return nil
+}
+
+const fetchFsImageTemplate = `SOURCE_DIR={{ .sourceDir }}
+rm -rf "$SOURCE_DIR"
+mkdir -p "$SOURCE_DIR/current"
+{{- if .s3 }}
+{{ .exportS3Credentials }}
+echo "Fetching namenode metadata from {{ .s3Uri }}/current"
+/kubedoop/hadoop/bin/hdfs dfs {{ .s3Options }} -get "{{ .s3Uri }}/current/*" "$SOURCE_DIR/current"
+{{- else if .configMap }}
+echo "Fetching namenode metadata from ConfigMap {{ .configMap }}"
+# the files of a ConfigMap volume are symlinks, dereference them
+cp -L {{ .imageDir }}/* "$SOURCE_DIR/current"
+{{- else }}
+echo "Fetching namenode metadata from {{ .imageDir }}/current"
+cp -r "{{ .imageDir }}/current/." "$SOURCE_DIR/current"
+{{- end }}
+`
+
+// FetchFsImageScript returns a script which copies the `current/` directory of the fromImage location
+// into sourceDir/current
+func FetchFsImageScript(fromImage *hdfsv1alpha1.FromImageSpec, s3Target *S3Target, sourceDir string) string {
+	data := map[string]interface{}{
+		"sourceDir": sourceDir,
+		"s3":        fromImage.S3 != nil && s3Target != nil,
+		"configMap": "",
+		"imageDir":  BootstrapImageDir,
+	}
+	if fromImage.ConfigMap != nil {
+		data["configMap"] = fromImage.ConfigMap.Name
+	}
+	if fromImage.Pvc != nil {
+		data["imageDir"] = path.Join(BootstrapImageDir, fromImage.Pvc.Path)
+	}
+	if s3Target != nil {
+		data["s3Uri"] = s3Target.Uri()
+		data["s3Options"] = s3Target.HadoopOptions()
+		data["exportS3Credentials"] = ""
+		if s3Target.Credentials != nil {
+			data["exportS3Credentials"] = ExportS3CredentialsScript(BootstrapS3CredentialsDir)
+		}
+	}
+	return ParseTemplate(fetchFsImageTemplate, data)[0]
+}
@@ -110,6 +110,14 @@ func (b *ConfigMapBuilder) Build(ctx context.Context) (ctrlclient.Object, error)
 		}
 	}
 
+	// JMX exporter config, replaces the config of the image
+	metrics := GetRoleMetrics(b.hdfsCluster, b.roleType)
+	if jmxExporterConfig, err := ResolveJmxExporterConfig(ctx, b.client, b.hdfsCluster.Namespace, metrics); err != nil {
+		return nil, err
+	} else if jmxExporterConfig != "" {
+		b.AddItem(JmxExporterConfigFileName, jmxExporterConfig)
+	}
+
 	return b.GetObject(), nil
 }
 
@@ -154,6 +162,10 @@ func (b *ConfigMapBuilder) buildVectorConfig(ctx context.Context) (string, error
 			if err != nil {
 				return "", err
 			}
+			// the audit events of the namenodes are shipped as a separate source
+			if b.roleType == constant.NameNode && GetAuditLog(b.hdfsCluster) != nil {
+				return ExtendVectorYamlByAuditLog(s)
+			}
 			return s, nil
 		}
 	}
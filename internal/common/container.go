@@ -31,6 +31,10 @@ type ContainerHealthCheckProvider interface {
 	GetReadinessProbe() *corev1.Probe
 }
 
+type ContainerStartupProbeProvider interface {
+	GetStartupProbe() *corev1.Probe
+}
+
 type ContainerSecretProvider interface {
 	GetSecretEnvFrom() string
 }
@@ -47,6 +51,7 @@ type HdfsContainerBuilder struct {
 	ports         []corev1.ContainerPort
 	readiness     *corev1.Probe
 	liveness      *corev1.Probe
+	startup       *corev1.Probe
 	volumeMounts  []corev1.VolumeMount
 	command       []string
 	args          []string
@@ -96,6 +101,10 @@ func (c *HdfsContainerBuilder) BuildWithComponent(component ContainerComponentIn
 			c.readiness = healthProvider.GetReadinessProbe()
 		}
 
+		if startupProvider, ok := component.(ContainerStartupProbeProvider); ok {
+			c.startup = startupProvider.GetStartupProbe()
+		}
+
 		if secretProvider, ok := component.(ContainerSecretProvider); ok {
 			c.secretEnvfrom = secretProvider.GetSecretEnvFrom()
 		}
@@ -103,6 +112,7 @@ func (c *HdfsContainerBuilder) BuildWithComponent(component ContainerComponentIn
 
 	c.SetLivenessProbe(c.liveness).
 		SetReadinessProbe(c.readiness).
+		SetStartupProbe(c.startup).
 		AddEnvVars(c.envs).
 		// AddEnvFromConfigMap(RoleGroupEnvsConfigMapName(c.RoleGroupInfo.GetClusterName())).
 		AddPorts(c.ports).
@@ -0,0 +1,41 @@
+package common
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/constant"
+)
+
+const defaultDataNodeStorageType = "DISK"
+
+// DataNodeDataVolumes returns the volumes the datanodes store their blocks on, the `data` volume if the
+// datanodes have no data volumes
+func DataNodeDataVolumes(instance *hdfsv1alpha1.HdfsCluster) []hdfsv1alpha1.DataVolumeSpec {
+	if instance.Spec.DataNode != nil && len(instance.Spec.DataNode.DataVolumes) > 0 {
+		return instance.Spec.DataNode.DataVolumes
+	}
+	return []hdfsv1alpha1.DataVolumeSpec{{Name: hdfsv1alpha1.DataVolumeMountName}}
+}
+
+// DataNodeDataVolumeMountPath returns the mount path of a data volume in the datanode container
+func DataNodeDataVolumeMountPath(volume hdfsv1alpha1.DataVolumeSpec) string {
+	return path.Join(hdfsv1alpha1.DataNodeRootDataDirPrefix, volume.Name)
+}
+
+// DataNodeDataDirs returns `dfs.datanode.data.dir`, a directory on every data volume with its storage type,
+// e.g. `[DISK]/kubedoop/data/data/datanode`
+func DataNodeDataDirs(instance *hdfsv1alpha1.HdfsCluster) string {
+	volumes := DataNodeDataVolumes(instance)
+	dirs := make([]string, 0, len(volumes))
+	for _, volume := range volumes {
+		storageType := volume.StorageType
+		if storageType == "" {
+			storageType = defaultDataNodeStorageType
+		}
+		dirs = append(dirs, fmt.Sprintf("[%s]%s", storageType, path.Join(DataNodeDataVolumeMountPath(volume), string(constant.DataNode))))
+	}
+	return strings.Join(dirs, ",")
+}
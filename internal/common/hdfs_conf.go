@@ -12,7 +12,9 @@ import (
 	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
 	"github.com/zncdatadev/hdfs-operator/internal/constant"
 	"github.com/zncdatadev/hdfs-operator/internal/util"
+	authv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/authentication/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
 )
 
 // TODO: refactor this
@@ -37,14 +39,15 @@ type CoreSiteXmlGenerator struct {
 	IsDiscovery bool
 
 	properties []util.XmlNameValuePair
+	includes   []string
 }
 
 func (c *CoreSiteXmlGenerator) Generate() string {
 	xml := fmt.Sprintf(coreSiteTemplate, c.InstanceName)
 	if len(c.properties) != 0 {
-		return util.Append(xml, c.properties)
+		xml = util.Append(xml, c.properties)
 	}
-	return xml
+	return util.Include(xml, c.includes...)
 }
 
 func (c *CoreSiteXmlGenerator) HaZookeeperQuorum() *CoreSiteXmlGenerator {
@@ -60,14 +63,69 @@ func (c *CoreSiteXmlGenerator) EnableKerberos(
 	clusterConfig *hdfsv1alpha1.ClusterConfigSpec, ns string) *CoreSiteXmlGenerator {
 	if IsKerberosEnabled(clusterConfig) {
 		if c.IsDiscovery {
-			c.properties = append(c.properties, SecurityDiscoveryCoreSiteXml(c.InstanceName, ns)...)
+			c.properties = append(c.properties, SecurityDiscoveryCoreSiteXml(clusterConfig.Authentication.Kerberos, c.InstanceName, ns, clusterConfig.ClusterDomain)...)
 		} else {
-			c.properties = append(c.properties, SecurityCoreSiteXml(c.InstanceName, ns)...)
+			c.properties = append(c.properties, SecurityCoreSiteXml(clusterConfig.Authentication.Kerberos, c.InstanceName, ns, clusterConfig.ClusterDomain)...)
 		}
 	}
 	return c
 }
 
+// EnableOpa resolves the groups of users with OPA if it has a group mapper, opaUrl is resolved from the OPA
+// discovery ConfigMap
+func (c *CoreSiteXmlGenerator) EnableOpa(clusterConfig *hdfsv1alpha1.ClusterConfigSpec, opaUrl string) *CoreSiteXmlGenerator {
+	if IsOpaGroupMappingEnabled(clusterConfig) {
+		c.properties = append(c.properties, OpaCoreSiteXml(clusterConfig.Authorization.Opa, opaUrl)...)
+	}
+	return c
+}
+
+// EnableLdapGroupMapping resolves the groups of users from LDAP, provider is resolved from the AuthenticationClass
+func (c *CoreSiteXmlGenerator) EnableLdapGroupMapping(
+	clusterConfig *hdfsv1alpha1.ClusterConfigSpec, provider *authv1alpha1.LDAPProvider) *CoreSiteXmlGenerator {
+	if IsLdapGroupMappingEnabled(clusterConfig) && provider != nil {
+		var tls *hdfsv1alpha1.TlsSpec
+		if IsTlsEnabled(clusterConfig) {
+			tls = clusterConfig.Authentication.Tls
+		}
+		c.properties = append(c.properties, LdapGroupMappingCoreSiteXml(clusterConfig.GroupMapping.Ldap, provider, tls)...)
+	}
+	return c
+}
+
+// EnableServiceAuthorization enables the ACLs of hadoop-policy.xml, not used by clients
+func (c *CoreSiteXmlGenerator) EnableServiceAuthorization(clusterConfig *hdfsv1alpha1.ClusterConfigSpec) *CoreSiteXmlGenerator {
+	if IsServiceAuthorizationEnabled(clusterConfig) && !c.IsDiscovery {
+		c.properties = append(c.properties, ServiceAuthorizationCoreSiteXml()...)
+	}
+	return c
+}
+
+// EnableProxyUsers allows the configured users to impersonate other users, not used by clients
+func (c *CoreSiteXmlGenerator) EnableProxyUsers(clusterConfig *hdfsv1alpha1.ClusterConfigSpec) *CoreSiteXmlGenerator {
+	if IsProxyUsersEnabled(clusterConfig) && !c.IsDiscovery {
+		c.properties = append(c.properties, ProxyUserCoreSiteXml(clusterConfig.ProxyUsers)...)
+	}
+	return c
+}
+
+// IncludeProxyUsers includes the proxy users from proxy-users.xml, see MakeProxyUsersData. The namenodes read it
+// from their ConfigMap to reload the proxy users, without the other properties of core-site.xml.
+func (c *CoreSiteXmlGenerator) IncludeProxyUsers(clusterConfig *hdfsv1alpha1.ClusterConfigSpec) *CoreSiteXmlGenerator {
+	if IsProxyUsersEnabled(clusterConfig) && !c.IsDiscovery {
+		c.includes = append(c.includes, hdfsv1alpha1.ProxyUsersFileName)
+	}
+	return c
+}
+
+// EnableMonitoring enables the `/prom` endpoint of the web UIs scraped by the ServiceMonitors
+func (c *CoreSiteXmlGenerator) EnableMonitoring(clusterConfig *hdfsv1alpha1.ClusterConfigSpec) *CoreSiteXmlGenerator {
+	if IsMonitoringEnabled(clusterConfig) && !c.IsDiscovery {
+		c.properties = append(c.properties, MonitoringCoreSiteXml()...)
+	}
+	return c
+}
+
 type NameNodeHdfsSiteXmlGenerator struct {
 	NameNodeReplicas     int32
 	InstanceName         string
@@ -119,7 +177,15 @@ func (c *NameNodeHdfsSiteXmlGenerator) Generate() string {
 // EnablerKerberos enable kerberos
 func (c *NameNodeHdfsSiteXmlGenerator) EnablerKerberos(clusterConfig *hdfsv1alpha1.ClusterConfigSpec) *NameNodeHdfsSiteXmlGenerator {
 	if IsKerberosEnabled(clusterConfig) {
-		c.properties = append(c.properties, SecurityHdfsSiteXml()...)
+		c.properties = append(c.properties, SecurityHdfsSiteXml(clusterConfig.Authentication.Kerberos)...)
+	}
+	return c
+}
+
+// EnableOpa authorizes requests with OPA, opaUrl is resolved from the OPA discovery ConfigMap
+func (c *NameNodeHdfsSiteXmlGenerator) EnableOpa(opaUrl string) *NameNodeHdfsSiteXmlGenerator {
+	if IsOpaEnabled(c.clusterConfig) {
+		c.properties = append(c.properties, OpaHdfsSiteXml(c.clusterConfig.Authorization.Opa, opaUrl)...)
 	}
 	return c
 }
@@ -322,7 +388,12 @@ const hdfsSiteTemplate = `<?xml version="1.0"?>
 `
 
 // MakeHadoopPolicyData make hadoop-policy.xml data
-func MakeHadoopPolicyData() string {
+func MakeHadoopPolicyData(clusterSpec *hdfsv1alpha1.ClusterConfigSpec) string {
+	if IsServiceAuthorizationEnabled(clusterSpec) {
+		if properties := ServiceAclXml(clusterSpec.ServiceAuthorization); len(properties) != 0 {
+			return util.Append(emptyXmlConfig, properties)
+		}
+	}
 	return emptyXmlConfig
 }
 
@@ -335,7 +406,8 @@ networkaddress.cache.ttl=30`
 // MakeSslClientData make ssl-client.xml data
 func MakeSslClientData(clusterSpec *hdfsv1alpha1.ClusterConfigSpec) string {
 	if IsTlsEnabled(clusterSpec) {
-		jksPasswd := clusterSpec.Authentication.Tls.JksPassword
+		// the password is never rendered into the ConfigMap, see SubstituteJksPasswordScript
+		jksPasswd := JksPasswordPlaceholder
 		if xml, err := xml.NewXMLConfigurationFromMap(map[string]string{
 			"ssl.client.truststore.location": path.Join(constants.KubedoopTlsDir, "truststore.p12"),
 			"ssl.client.truststore.type":     pkcs12StoreType,
@@ -353,7 +425,8 @@ func MakeSslClientData(clusterSpec *hdfsv1alpha1.ClusterConfigSpec) string {
 // MakeSslServerData make ssl-server.xml data
 func MakeSslServerData(clusterSpec *hdfsv1alpha1.ClusterConfigSpec) string {
 	if IsTlsEnabled(clusterSpec) {
-		jksPasswd := clusterSpec.Authentication.Tls.JksPassword
+		// the password is never rendered into the ConfigMap, see SubstituteJksPasswordScript
+		jksPasswd := JksPasswordPlaceholder
 		if xml, err := xml.NewXMLConfigurationFromMap(map[string]string{
 			"ssl.server.truststore.location": path.Join(constants.KubedoopTlsDir, "truststore.p12"),
 			"ssl.server.truststore.type":     pkcs12StoreType,
@@ -463,6 +536,18 @@ func NewDataNodeHdfsSiteXmlGenerator(
 	}
 }
 
+// EnableDiskBalancer set dfs.disk.balancer.enabled for the intra-node disk balancer
+func (c *DataNodeHdfsSiteXmlGenerator) EnableDiskBalancer(diskBalancer *hdfsv1alpha1.DiskBalancerSpec) *DataNodeHdfsSiteXmlGenerator {
+	if diskBalancer == nil {
+		return c
+	}
+	if c.DataNodeConfig == nil {
+		c.DataNodeConfig = make(map[string]string)
+	}
+	c.DataNodeConfig["dfs.disk.balancer.enabled"] = strconv.FormatBool(ptr.Deref(diskBalancer.Enabled, true))
+	return c
+}
+
 // Generate make hdfs-site.xml data
 func (c *DataNodeHdfsSiteXmlGenerator) Generate() string {
 	nameNodeSiteXml := c.NameNodeHdfsSiteXmlGenerator.Generate()
@@ -0,0 +1,117 @@
+package common
+
+import (
+	"slices"
+
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/constant"
+	"github.com/zncdatadev/hdfs-operator/internal/util/version"
+	"github.com/zncdatadev/operator-go/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ClusterImage returns the image of the cluster
+func ClusterImage(instance *hdfsv1alpha1.HdfsCluster) *util.Image {
+	return SpecImage(instance.Spec.Image)
+}
+
+// SpecImage returns the image of an image spec, the default image if it is nil
+func SpecImage(imageSpec *hdfsv1alpha1.ImageSpec) *util.Image {
+	if imageSpec == nil {
+		imageSpec = &hdfsv1alpha1.ImageSpec{Repo: hdfsv1alpha1.DefaultRepository}
+	}
+	productVersion := imageSpec.ProductVersion
+	if productVersion == "" {
+		productVersion = hdfsv1alpha1.DefaultProductVersion
+	}
+
+	image := util.NewImage(
+		hdfsv1alpha1.DefaultProductName,
+		version.BuildVersion,
+		productVersion,
+		func(options *util.ImageOptions) {
+			options.Custom = imageSpec.Custom
+			options.Repo = imageSpec.Repo
+			options.PullPolicy = imageSpec.PullPolicy
+			options.PullSecretName = imageSpec.PullSecretName
+		},
+	)
+	if imageSpec.KubedoopVersion != "" {
+		image.KubedoopVersion = imageSpec.KubedoopVersion
+	}
+	return image
+}
+
+// RoleImage returns the image of a role, the image of the cluster with the image override of the role
+func RoleImage(instance *hdfsv1alpha1.HdfsCluster, role constant.Role) *util.Image {
+	var override *hdfsv1alpha1.ImageOverrideSpec
+	switch role {
+	case constant.NameNode:
+		if instance.Spec.NameNode != nil {
+			override = instance.Spec.NameNode.Image
+		}
+	case constant.DataNode:
+		if instance.Spec.DataNode != nil {
+			override = instance.Spec.DataNode.Image
+		}
+	case constant.JournalNode:
+		if instance.Spec.JournalNode != nil {
+			override = instance.Spec.JournalNode.Image
+		}
+	}
+	return OverrideImage(ClusterImage(instance), override)
+}
+
+// VectorImage returns the image of the vector sidecar of a role
+func VectorImage(instance *hdfsv1alpha1.HdfsCluster, roleImage *util.Image) *util.Image {
+	return OverrideImage(roleImage, instance.Spec.VectorImage)
+}
+
+// Oauth2ProxyImage returns the image of the oauth2-proxy sidecar of a role
+func Oauth2ProxyImage(instance *hdfsv1alpha1.HdfsCluster, roleImage *util.Image) *util.Image {
+	return OverrideImage(roleImage, instance.Spec.Oauth2ProxyImage)
+}
+
+// OverrideImage returns a copy of the image with the fields set in the override. The repo or a version of the
+// override replaces a custom image, which would take precedence over them.
+func OverrideImage(image *util.Image, override *hdfsv1alpha1.ImageOverrideSpec) *util.Image {
+	result := *image
+	if override == nil {
+		return &result
+	}
+	if override.Repo != "" || override.KubedoopVersion != "" || override.ProductVersion != "" {
+		result.Custom = ""
+	}
+	if override.Custom != "" {
+		result.Custom = override.Custom
+	}
+	if override.Repo != "" {
+		result.Repo = override.Repo
+	}
+	if override.KubedoopVersion != "" {
+		result.KubedoopVersion = override.KubedoopVersion
+	}
+	if override.ProductVersion != "" {
+		result.ProductVersion = override.ProductVersion
+	}
+	if override.PullPolicy != "" {
+		result.PullPolicy = override.PullPolicy
+	}
+	if override.PullSecretName != "" {
+		result.PullSecretName = override.PullSecretName
+	}
+	return &result
+}
+
+// AddImagePullSecrets adds the pull secrets of the images to the pod, the secrets the pod has are kept
+func AddImagePullSecrets(podSpec *corev1.PodSpec, images ...*util.Image) {
+	for _, image := range images {
+		if image == nil || image.PullSecretName == "" {
+			continue
+		}
+		secret := corev1.LocalObjectReference{Name: image.PullSecretName}
+		if !slices.Contains(podSpec.ImagePullSecrets, secret) {
+			podSpec.ImagePullSecrets = append(podSpec.ImagePullSecrets, secret)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"emperror.dev/errors"
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/constant"
+	"github.com/zncdatadev/operator-go/pkg/client"
+	"github.com/zncdatadev/operator-go/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// JmxExporterConfigFileName is the key of the JMX exporter config in the role group ConfigMap
+const JmxExporterConfigFileName = "jmx-exporter.yaml"
+
+// GetRoleMetrics returns the metrics of a role, it is nil if they are not configured
+func GetRoleMetrics(instance *hdfsv1alpha1.HdfsCluster, role constant.Role) *hdfsv1alpha1.MetricsSpec {
+	var roleSpec *hdfsv1alpha1.RoleSpec
+	switch role {
+	case constant.NameNode:
+		if instance.Spec.NameNode != nil {
+			roleSpec = &instance.Spec.NameNode.RoleSpec
+		}
+	case constant.DataNode:
+		if instance.Spec.DataNode != nil {
+			roleSpec = &instance.Spec.DataNode.RoleSpec
+		}
+	case constant.JournalNode:
+		roleSpec = instance.Spec.JournalNode
+	}
+	if roleSpec == nil || roleSpec.RoleConfig == nil {
+		return nil
+	}
+	return roleSpec.RoleConfig.Metrics
+}
+
+// IsJmxExporterEnabled reports whether the JMX exporter agent runs in the containers of a role, it is enabled by default
+func IsJmxExporterEnabled(metrics *hdfsv1alpha1.MetricsSpec) bool {
+	return metrics == nil || metrics.JmxExporterEnabled == nil || *metrics.JmxExporterEnabled
+}
+
+func hasJmxExporterRules(metrics *hdfsv1alpha1.MetricsSpec) bool {
+	return IsJmxExporterEnabled(metrics) && metrics != nil && metrics.JmxExporterRules != nil
+}
+
+// jmxExporterJvmArg returns the javaagent of the role, the rules of the role are read from the mounted
+// role group ConfigMap, which is updated in place, the exporter reloads its config when it changes
+func jmxExporterJvmArg(role constant.Role, metrics *hdfsv1alpha1.MetricsSpec) (string, error) {
+	metricPort, err := GetMetricsPort(role)
+	if err != nil {
+		return "", err
+	}
+	jarPath := path.Join(constants.KubedoopJmxDir, "jmx_prometheus_javaagent.jar")
+	configPath := path.Join(constants.KubedoopJmxDir, fmt.Sprintf("%s.yaml", strings.ToLower(string(role))))
+	if hasJmxExporterRules(metrics) {
+		// the container of a role has the name of the role
+		configPath = path.Join(constants.KubedoopConfigDirMount, string(role), JmxExporterConfigFileName)
+	}
+	return fmt.Sprintf("-javaagent:%s=%d:%s", jarPath, metricPort, configPath), nil
+}
+
+// ResolveJmxExporterConfig returns the JMX exporter config rendered into the role group ConfigMap,
+// it is empty if the config of the image is used
+func ResolveJmxExporterConfig(
+	ctx context.Context,
+	client *client.Client,
+	namespace string,
+	metrics *hdfsv1alpha1.MetricsSpec,
+) (string, error) {
+	if !hasJmxExporterRules(metrics) {
+		return "", nil
+	}
+	rules := metrics.JmxExporterRules
+	if (rules.Inline == "") == (rules.ConfigMap == nil) {
+		return "", errors.New("exactly one of jmxExporterRules.inline and jmxExporterRules.configMap must be set")
+	}
+
+	config := rules.Inline
+	if ref := rules.ConfigMap; ref != nil {
+		configMap := &corev1.ConfigMap{}
+		if err := client.Get(ctx, ctrlclient.ObjectKey{Namespace: namespace, Name: ref.Name}, configMap); err != nil {
+			return "", errors.WrapIfWithDetails(err, "failed to get ConfigMap of the JMX exporter rules", "name", ref.Name)
+		}
+		var ok bool
+		if config, ok = configMap.Data[ref.Key]; !ok {
+			return "", errors.Errorf("ConfigMap %s of the JMX exporter rules has no %s", ref.Name, ref.Key)
+		}
+	}
+
+	// reject a broken config, the agent would fail the start of the role
+	var parsed map[string]interface{}
+	if err := yaml.Unmarshal([]byte(config), &parsed); err != nil {
+		return "", errors.WrapIf(err, "JMX exporter rules are not valid YAML")
+	}
+	return config, nil
+}
@@ -3,7 +3,11 @@ package common
 import (
 	"fmt"
 	"path"
+	"regexp"
+	"strconv"
+	"strings"
 
+	"emperror.dev/errors"
 	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
 	"github.com/zncdatadev/hdfs-operator/internal/constant"
 	"github.com/zncdatadev/hdfs-operator/internal/util"
@@ -16,9 +20,20 @@ import (
 
 const KrbVolumeName = "kerberos"
 
+// default kerberos services of the principals
 const (
-	xmlTrue                   = "true"
-	dataTransferProtectionVal = "privacy"
+	nameNodeKerberosService    = "nn"
+	dataNodeKerberosService    = "dn"
+	journalNodeKerberosService = "jn"
+	httpKerberosService        = "HTTP"
+
+	xmlKerberosRealm    = "${env.KERBEROS_REALM}"
+	scriptKerberosRealm = "${KERBEROS_REALM}"
+)
+
+const (
+	xmlTrue           = "true"
+	privacyProtection = "privacy"
 )
 
 func IsKerberosEnabled(clusterSpec *hdfsv1alpha1.ClusterConfigSpec) bool {
@@ -30,8 +45,8 @@ func ExportKrbRealmFromConfig(krb5ConfPath string) string {
 }
 
 // SecurityHdfsSiteXml make kerberos config for hdfs-site.xml
-func SecurityHdfsSiteXml() []util.XmlNameValuePair {
-	return []util.XmlNameValuePair{
+func SecurityHdfsSiteXml(kerberos *hdfsv1alpha1.KerberosSpec) []util.XmlNameValuePair {
+	properties := []util.XmlNameValuePair{
 		{
 			Name:  "dfs.block.access.token.enable",
 			Value: xmlTrue,
@@ -52,87 +67,149 @@ func SecurityHdfsSiteXml() []util.XmlNameValuePair {
 			Name:  "dfs.https.client.keystore.resource",
 			Value: "ssl-client.xml",
 		},
-		{
-			Name:  "dfs.encrypt.data.transfer",
-			Value: xmlTrue,
-		},
-		{
-			Name:  "dfs.data.transfer.protection",
-			Value: dataTransferProtectionVal,
-		},
 	}
+	return append(properties, dataTransferXml(kerberos)...)
 }
 
-func SecurityDiscoveryHdfsSiteXml() []util.XmlNameValuePair {
-	return []util.XmlNameValuePair{
+func SecurityDiscoveryHdfsSiteXml(kerberos *hdfsv1alpha1.KerberosSpec) []util.XmlNameValuePair {
+	properties := []util.XmlNameValuePair{
 		{
 			Name:  "hadoop.kerberos.keytab.login.autorenewal.enabled",
 			Value: xmlTrue,
 		},
+	}
+	return append(properties, dataTransferXml(kerberos)...)
+}
+
+// dataTransferXml returns the SASL properties of the block transfers. The discovery ConfigMap publishes
+// the same properties, the clients must request a protection the datanodes accept.
+func dataTransferXml(kerberos *hdfsv1alpha1.KerberosSpec) []util.XmlNameValuePair {
+	protection := dataTransferProtection(kerberos)
+	properties := []util.XmlNameValuePair{
 		{
 			Name:  "dfs.data.transfer.protection",
-			Value: dataTransferProtectionVal,
-		},
-		{
-			Name:  "dfs.encrypt.data.transfer",
-			Value: xmlTrue,
+			Value: protection,
 		},
 	}
+	if protection != privacyProtection {
+		return properties
+	}
+	// the encryption of the blocks takes precedence over the SASL protection
+	properties = append(properties, util.XmlNameValuePair{
+		Name:  "dfs.encrypt.data.transfer",
+		Value: xmlTrue,
+	})
+	if dataTransfer := kerberos.DataTransfer; dataTransfer != nil && dataTransfer.CipherSuite != "" {
+		properties = append(properties, util.XmlNameValuePair{
+			Name:  "dfs.encrypt.data.transfer.cipher.suites",
+			Value: dataTransfer.CipherSuite,
+		})
+		if dataTransfer.CipherKeyBitLength != 0 {
+			properties = append(properties, util.XmlNameValuePair{
+				Name:  "dfs.encrypt.data.transfer.cipher.key.bitlength",
+				Value: strconv.Itoa(int(dataTransfer.CipherKeyBitLength)),
+			})
+		}
+	}
+	return properties
+}
+
+func rpcProtection(kerberos *hdfsv1alpha1.KerberosSpec) string {
+	if kerberos.RpcProtection != "" {
+		return kerberos.RpcProtection
+	}
+	return privacyProtection
+}
+
+func dataTransferProtection(kerberos *hdfsv1alpha1.KerberosSpec) string {
+	if kerberos.DataTransfer != nil && kerberos.DataTransfer.Protection != "" {
+		return kerberos.DataTransfer.Protection
+	}
+	return privacyProtection
 }
 
-func SecurityDiscoveryCoreSiteXml(instanceName string, ns string) []util.XmlNameValuePair {
-	principalHostPart := PrincipalHostPart(instanceName, ns)
-	return []util.XmlNameValuePair{
+// ValidateDataTransfer checks the protection of the block transfers. The datanodes use SASL instead of
+// privileged ports, which requires the HTTPS web UIs, and the cipher suite is only used to encrypt the blocks.
+// TLS is only required with the protection settings, clusters created without them keep reconciling.
+func ValidateDataTransfer(clusterSpec *hdfsv1alpha1.ClusterConfigSpec) error {
+	if !IsKerberosEnabled(clusterSpec) {
+		return nil
+	}
+	kerberos := clusterSpec.Authentication.Kerberos
+	dataTransfer := kerberos.DataTransfer
+	if kerberos.RpcProtection == "" && dataTransfer == nil {
+		return nil
+	}
+	if !IsTlsEnabled(clusterSpec) {
+		return errors.New("rpcProtection and dataTransfer require tls, the datanodes secure the data transfer with SASL and HTTPS")
+	}
+	if dataTransfer == nil {
+		return nil
+	}
+	protection := dataTransferProtection(clusterSpec.Authentication.Kerberos)
+	if dataTransfer.CipherSuite != "" && protection != privacyProtection {
+		return errors.Errorf("dataTransfer.cipherSuite requires the privacy protection, not %s", protection)
+	}
+	if dataTransfer.CipherKeyBitLength != 0 && dataTransfer.CipherSuite == "" {
+		return errors.New("dataTransfer.cipherKeyBitLength requires a cipherSuite")
+	}
+	return nil
+}
+
+func SecurityDiscoveryCoreSiteXml(kerberos *hdfsv1alpha1.KerberosSpec, instanceName string, ns string, clusterDomain string) []util.XmlNameValuePair {
+	properties := []util.XmlNameValuePair{
 		{
 			Name:  "hadoop.security.authentication",
 			Value: "kerberos",
 		},
 		{
 			Name:  "dfs.journalnode.kerberos.principal",
-			Value: fmt.Sprintf("jn/%s", principalHostPart),
+			Value: KerberosPrincipal(kerberos, journalNodeKerberosService, instanceName, ns, clusterDomain, xmlKerberosRealm),
 		},
 		{
 			Name:  "dfs.namenode.kerberos.principal",
-			Value: fmt.Sprintf("nn/%s", principalHostPart),
+			Value: KerberosPrincipal(kerberos, nameNodeKerberosService, instanceName, ns, clusterDomain, xmlKerberosRealm),
 		},
 		{
 			Name:  "dfs.datanode.kerberos.principal",
-			Value: fmt.Sprintf("dn/%s", principalHostPart),
+			Value: KerberosPrincipal(kerberos, dataNodeKerberosService, instanceName, ns, clusterDomain, xmlKerberosRealm),
 		},
 		{
 			Name:  "hadoop.rpc.protection",
-			Value: dataTransferProtectionVal,
+			Value: rpcProtection(kerberos),
 		},
 	}
+	return append(properties, authToLocalXml(kerberos)...)
 }
 
 // SecurityCoreSiteXml make kerberos config for core-site.xml
-func SecurityCoreSiteXml(instanceName string, ns string) []util.XmlNameValuePair {
-	principalHostPart := PrincipalHostPart(instanceName, ns)
-	return []util.XmlNameValuePair{
+func SecurityCoreSiteXml(kerberos *hdfsv1alpha1.KerberosSpec, instanceName string, ns string, clusterDomain string) []util.XmlNameValuePair {
+	journalNodePrincipal := KerberosPrincipal(kerberos, journalNodeKerberosService, instanceName, ns, clusterDomain, xmlKerberosRealm)
+	nameNodePrincipal := KerberosPrincipal(kerberos, nameNodeKerberosService, instanceName, ns, clusterDomain, xmlKerberosRealm)
+	properties := []util.XmlNameValuePair{
 		{
 			Name:  "hadoop.security.authentication",
 			Value: "kerberos",
 		},
 		{
 			Name:  "dfs.journalnode.kerberos.principal",
-			Value: fmt.Sprintf("jn/%s", principalHostPart),
+			Value: journalNodePrincipal,
 		},
 		{
 			Name:  "dfs.journalnode.kerberos.internal.spnego.principal",
-			Value: fmt.Sprintf("jn/%s", principalHostPart),
+			Value: journalNodePrincipal,
 		},
 		{
 			Name:  "dfs.namenode.kerberos.principal",
-			Value: fmt.Sprintf("nn/%s", principalHostPart),
+			Value: nameNodePrincipal,
 		},
 		{
 			Name:  "dfs.datanode.kerberos.principal",
-			Value: fmt.Sprintf("dn/%s", principalHostPart),
+			Value: KerberosPrincipal(kerberos, dataNodeKerberosService, instanceName, ns, clusterDomain, xmlKerberosRealm),
 		},
 		{
 			Name:  "dfs.web.authentication.kerberos.principal",
-			Value: fmt.Sprintf("HTTP/%s", principalHostPart),
+			Value: KerberosPrincipal(kerberos, httpKerberosService, instanceName, ns, clusterDomain, xmlKerberosRealm),
 		},
 		{
 			Name:  "dfs.journalnode.keytab.file",
@@ -148,17 +225,49 @@ func SecurityCoreSiteXml(instanceName string, ns string) []util.XmlNameValuePair
 		},
 		{
 			Name:  "dfs.journalnode.kerberos.principal.pattern",
-			Value: fmt.Sprintf("jn/%s", principalHostPart),
+			Value: journalNodePrincipal,
 		},
 		{
 			Name:  "dfs.namenode.kerberos.principal.pattern",
-			Value: fmt.Sprintf("nn/%s", principalHostPart),
+			Value: nameNodePrincipal,
 		},
 		{
 			Name:  "hadoop.rpc.protection",
-			Value: dataTransferProtectionVal,
+			Value: rpcProtection(kerberos),
 		},
 	}
+	return append(properties, authToLocalXml(kerberos)...)
+}
+
+// authToLocalXml returns the hadoop.security.auth_to_local property, if rules or trusted realms are configured
+func authToLocalXml(kerberos *hdfsv1alpha1.KerberosSpec) []util.XmlNameValuePair {
+	if rules := AuthToLocalRules(kerberos); len(rules) != 0 {
+		return []util.XmlNameValuePair{
+			{
+				Name:  "hadoop.security.auth_to_local",
+				Value: strings.Join(rules, "\n"),
+			},
+		}
+	}
+	return nil
+}
+
+// AuthToLocalRules returns the custom rules, followed by rules stripping the additional trusted realms
+// and the DEFAULT rule. No rules are returned if neither is configured, so hadoop uses its default.
+func AuthToLocalRules(kerberos *hdfsv1alpha1.KerberosSpec) []string {
+	if len(kerberos.AuthToLocal) == 0 && len(kerberos.AdditionalTrustedRealms) == 0 {
+		return nil
+	}
+	rules := make([]string, 0, len(kerberos.AuthToLocal)+2*len(kerberos.AdditionalTrustedRealms)+1)
+	rules = append(rules, kerberos.AuthToLocal...)
+	for _, realm := range kerberos.AdditionalTrustedRealms {
+		quoted := regexp.QuoteMeta(realm)
+		rules = append(rules,
+			fmt.Sprintf("RULE:[1:$1@$0](.*@%s)s/@.*//", quoted),
+			fmt.Sprintf("RULE:[2:$1@$0](.*@%s)s/@.*//", quoted),
+		)
+	}
+	return append(rules, "DEFAULT")
 }
 
 func SecurityEnvs(container constant.ContainerComponent, jvmArgs *[]string) []corev1.EnvVar {
@@ -189,9 +298,15 @@ func SecurityVolumeMounts() []corev1.VolumeMount {
 	}
 }
 
-func CreateKerberosSecretPvc(secretClass string, instanceName string, role constant.Role) corev1.Volume {
-	kerberosServiceName := GetKerberosServiceName(role)
+// CreateKerberosSecretPvc creates the keytab volume of a role, containing the role and the HTTP principals
+func CreateKerberosSecretPvc(kerberos *hdfsv1alpha1.KerberosSpec, instanceName string, role constant.Role) corev1.Volume {
+	serviceNames := kerberosServiceName(kerberos, GetKerberosServiceName(role)) + "," + kerberosServiceName(kerberos, httpKerberosService)
+	return CreateKerberosSecretVolume(kerberos.SecretClass, instanceName, serviceNames)
+}
 
+// CreateKerberosSecretVolume creates a secret operator volume providing a keytab for the
+// comma separated kerberos service names, scoped to the service scopeServiceName
+func CreateKerberosSecretVolume(secretClass string, scopeServiceName string, kerberosServiceNames string) corev1.Volume {
 	return corev1.Volume{
 		Name: KrbVolumeName,
 		VolumeSource: corev1.VolumeSource{
@@ -200,8 +315,8 @@ func CreateKerberosSecretPvc(secretClass string, instanceName string, role const
 					ObjectMeta: metav1.ObjectMeta{
 						Annotations: map[string]string{
 							constants.AnnotationSecretsClass:                secretClass,
-							constants.AnnotationSecretsScope:                fmt.Sprintf("service=%s", instanceName),
-							constants.AnnotationSecretsKerberosServiceNames: kerberosServiceName + ",HTTP",
+							constants.AnnotationSecretsScope:                fmt.Sprintf("service=%s", scopeServiceName),
+							constants.AnnotationSecretsKerberosServiceNames: kerberosServiceNames,
 						},
 					},
 					Spec: corev1.PersistentVolumeClaimSpec{
@@ -249,23 +364,104 @@ func ParseTemplate(tmpl string, data map[string]interface{}) []string {
 	}
 }
 
-func PrincipalHostPart(instanceName string, ns string) string {
-	return fmt.Sprintf("%s.%s.svc.cluster.local@${env.KERBEROS_REALM}", instanceName, ns)
+// CreateKerberosPrincipal returns the principal of a role used in scripts, it is also valid if kerberos is disabled
+func CreateKerberosPrincipal(clusterConfig *hdfsv1alpha1.ClusterConfigSpec, instanceName string, ns string, role constant.Role) string {
+	var kerberos *hdfsv1alpha1.KerberosSpec
+	if IsKerberosEnabled(clusterConfig) {
+		kerberos = clusterConfig.Authentication.Kerberos
+	}
+	return KerberosPrincipal(kerberos, GetKerberosServiceName(role), instanceName, ns, clusterConfig.ClusterDomain, scriptKerberosRealm)
+}
+
+// CreateServiceKerberosPrincipal returns the principal of a kerberos service which is not a role of a cluster,
+// like the replication job, used in scripts. It has the default principal template.
+func CreateServiceKerberosPrincipal(service string, instanceName string, ns string, clusterDomain string) string {
+	return KerberosPrincipal(nil, service, instanceName, ns, clusterDomain, scriptKerberosRealm)
+}
+
+// KerberosPrincipal renders the principal of a kerberos service, e.g. `nn`, from its template.
+// The realm is `${env.KERBEROS_REALM}` in hadoop configs and `${KERBEROS_REALM}` in scripts.
+func KerberosPrincipal(
+	kerberos *hdfsv1alpha1.KerberosSpec,
+	service string,
+	instanceName string,
+	ns string,
+	clusterDomain string,
+	realm string,
+) string {
+	return strings.NewReplacer(
+		"${instance}", instanceName,
+		"${namespace}", ns,
+		"${host}", kerberosHost(instanceName, ns, clusterDomain),
+		"${realm}", realm,
+	).Replace(principalTemplate(kerberos, service))
+}
+
+// kerberosHost returns the FQDN of the service the keytabs are requested for, see CreateKerberosSecretVolume
+func kerberosHost(instanceName string, ns string, clusterDomain string) string {
+	return fmt.Sprintf("%s.%s.svc.%s", instanceName, ns, clusterDomain)
+}
+
+// ValidatePrincipals checks that the principal templates render to `<service>/<host>@<realm>`, where the host is
+// the FQDN of the service of the cluster. The SecretClass only issues keytabs for this host, a principal with
+// another host could not log in.
+func ValidatePrincipals(clusterSpec *hdfsv1alpha1.ClusterConfigSpec, instanceName string, ns string) error {
+	if !IsKerberosEnabled(clusterSpec) {
+		return nil
+	}
+	kerberos := clusterSpec.Authentication.Kerberos
+	host := kerberosHost(instanceName, ns, clusterSpec.ClusterDomain)
+	for _, service := range []string{nameNodeKerberosService, dataNodeKerberosService, journalNodeKerberosService, httpKerberosService} {
+		principal := KerberosPrincipal(kerberos, service, instanceName, ns, clusterSpec.ClusterDomain, scriptKerberosRealm)
+		name, rest, found := strings.Cut(principal, "/")
+		principalHost, realm, _ := strings.Cut(rest, "@")
+		if !found || name == "" || realm == "" {
+			return errors.Errorf("principal %q of %s is not of the form <service>/<host>@<realm>", principal, service)
+		}
+		if principalHost != host {
+			return errors.Errorf("principal %q of %s must have the host %s, the keytab is only issued for it", principal, service, host)
+		}
+	}
+	return nil
+}
+
+func principalTemplate(kerberos *hdfsv1alpha1.KerberosSpec, service string) string {
+	var tmpl string
+	if kerberos != nil && kerberos.Principals != nil {
+		principals := kerberos.Principals
+		switch service {
+		case nameNodeKerberosService:
+			tmpl = principals.NameNode
+		case dataNodeKerberosService:
+			tmpl = principals.DataNode
+		case journalNodeKerberosService:
+			tmpl = principals.JournalNode
+		case httpKerberosService:
+			tmpl = principals.Http
+		}
+	}
+	if tmpl == "" {
+		tmpl = service + "/${host}@${realm}"
+	}
+	return tmpl
 }
 
-func CreateKerberosPrincipal(instanceName string, ns string, role constant.Role) string {
-	host := fmt.Sprintf("%s.%s.svc.cluster.local@${KERBEROS_REALM}", instanceName, ns)
-	return fmt.Sprintf("%s/%s", GetKerberosServiceName(role), host)
+// kerberosServiceName returns the service part of the principal template, requested from the SecretClass
+func kerberosServiceName(kerberos *hdfsv1alpha1.KerberosSpec, service string) string {
+	if name, _, found := strings.Cut(principalTemplate(kerberos, service), "/"); found && name != "" {
+		return name
+	}
+	return service
 }
 
 func GetKerberosServiceName(role constant.Role) string {
 	switch role {
 	case constant.NameNode:
-		return "nn"
+		return nameNodeKerberosService
 	case constant.DataNode:
-		return "dn"
+		return dataNodeKerberosService
 	case constant.JournalNode:
-		return "jn"
+		return journalNodeKerberosService
 	default:
 		panic(fmt.Sprintf("unsupported role for kerberos: %s", role))
 	}
@@ -0,0 +1,98 @@
+package common_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zncdatadev/hdfs-operator/internal/common"
+	"github.com/zncdatadev/hdfs-operator/internal/render"
+)
+
+// TestValidateDataTransfer validates the authentication of clusters with the defaults of the CRD applied,
+// like the API server stores them
+func TestValidateDataTransfer(t *testing.T) {
+	tests := []struct {
+		name           string
+		authentication string
+		wantErr        string
+	}{
+		{
+			name: "kerberos without tls",
+			authentication: `
+      kerberos:
+        secretClass: kerberos`,
+		},
+		{
+			name: "rpc protection without tls",
+			authentication: `
+      kerberos:
+        secretClass: kerberos
+        rpcProtection: integrity`,
+			wantErr: "require tls",
+		},
+		{
+			name: "data transfer without tls",
+			authentication: `
+      kerberos:
+        secretClass: kerberos
+        dataTransfer: {}`,
+			wantErr: "require tls",
+		},
+		{
+			name: "data transfer with tls",
+			authentication: `
+      tls: {}
+      kerberos:
+        secretClass: kerberos
+        dataTransfer:
+          cipherSuite: AES/CTR/NoPadding
+          cipherKeyBitLength: 256`,
+		},
+		{
+			name: "cipher suite without privacy",
+			authentication: `
+      tls: {}
+      kerberos:
+        secretClass: kerberos
+        dataTransfer:
+          protection: integrity
+          cipherSuite: AES/CTR/NoPadding`,
+			wantErr: "requires the privacy protection",
+		},
+		{
+			name: "key length without cipher suite",
+			authentication: `
+      tls: {}
+      kerberos:
+        secretClass: kerberos
+        dataTransfer:
+          cipherKeyBitLength: 256`,
+			wantErr: "requires a cipherSuite",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := `
+apiVersion: hdfs.kubedoop.dev/v1alpha1
+kind: HdfsCluster
+metadata:
+  name: hdfs
+spec:
+  clusterConfig:
+    zookeeperConfigMapName: zk
+    authentication:` + tt.authentication + `
+`
+			cluster, _, err := render.Load(strings.NewReader(input), "default")
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = common.ValidateDataTransfer(cluster.Spec.ClusterConfig)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"emperror.dev/errors"
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/util"
+	authv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/authentication/v1alpha1"
+	"github.com/zncdatadev/operator-go/pkg/client"
+	"github.com/zncdatadev/operator-go/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	LdapBindCredentialsVolumeName = "ldap-bind-credentials"
+	LdapBindCredentialsDir        = constants.KubedoopSecretDir + "ldap-bind-credentials"
+	// LdapBindUserEnvName is exported by the namenode from the bind credentials,
+	// hadoop only reads the bind password from a file
+	LdapBindUserEnvName = "LDAP_BIND_USER"
+
+	ldapGroupsMappingClass = "org.apache.hadoop.security.LdapGroupsMapping"
+	ldapPropertyPrefix     = "hadoop.security.group.mapping.ldap."
+	defaultLdapPort        = 389
+	defaultLdapsPort       = 636
+	defaultLdapUidField    = "uid"
+)
+
+func IsLdapGroupMappingEnabled(clusterSpec *hdfsv1alpha1.ClusterConfigSpec) bool {
+	return clusterSpec.GroupMapping != nil && clusterSpec.GroupMapping.Ldap != nil
+}
+
+// ResolveLdapGroupMapping returns the LDAP provider of the group mapping AuthenticationClass,
+// it is nil if the LDAP group mapping is disabled
+func ResolveLdapGroupMapping(
+	ctx context.Context,
+	client *client.Client,
+	clusterSpec *hdfsv1alpha1.ClusterConfigSpec,
+) (*authv1alpha1.LDAPProvider, error) {
+	if !IsLdapGroupMappingEnabled(clusterSpec) {
+		return nil, nil
+	}
+	if IsOpaGroupMappingEnabled(clusterSpec) {
+		return nil, errors.New("groupMapping.ldap can not be combined with authorization.opa.groupMapperClass, OPA resolves the groups")
+	}
+
+	name := clusterSpec.GroupMapping.Ldap.AuthenticationClass
+	authClass := &authv1alpha1.AuthenticationClass{}
+	if err := client.Get(ctx, ctrlclient.ObjectKey{Name: name}, authClass); err != nil {
+		return nil, errors.WrapIfWithDetails(err, "failed to get AuthenticationClass of the group mapping", "name", name)
+	}
+	provider := authClass.Spec.AuthenticationProvider.LDAP
+	if provider == nil {
+		return nil, errors.Errorf("AuthenticationClass %s has no LDAP provider", name)
+	}
+
+	if provider.TLS != nil {
+		verification := provider.TLS.Verification
+		if verification == nil || verification.Server == nil || verification.Server.CACert == nil {
+			return nil, errors.Errorf("LDAP of AuthenticationClass %s must verify the server, hadoop can not skip the verification", name)
+		}
+		// the CA is read from the truststore of the TLS volume, so it must be provided by the same SecretClass
+		if secretClass := verification.Server.CACert.SecretClass; secretClass != "" {
+			if !IsTlsEnabled(clusterSpec) || clusterSpec.Authentication.Tls.SecretClass != secretClass {
+				return nil, errors.Errorf("LDAP CA SecretClass %s of AuthenticationClass %s must be the TLS SecretClass of the cluster", secretClass, name)
+			}
+		}
+	}
+	return provider, nil
+}
+
+// LdapGroupMappingCoreSiteXml resolves the groups of users from LDAP
+func LdapGroupMappingCoreSiteXml(
+	ldap *hdfsv1alpha1.LdapGroupMappingSpec,
+	provider *authv1alpha1.LDAPProvider,
+	tls *hdfsv1alpha1.TlsSpec,
+) []util.XmlNameValuePair {
+	scheme, port := "ldap", defaultLdapPort
+	if provider.TLS != nil {
+		scheme, port = "ldaps", defaultLdapsPort
+	}
+	if provider.Port != 0 {
+		port = provider.Port
+	}
+
+	properties := []util.XmlNameValuePair{
+		{Name: "hadoop.security.group.mapping", Value: ldapGroupsMappingClass},
+		{Name: ldapPropertyPrefix + "url", Value: fmt.Sprintf("%s://%s:%d", scheme, provider.Hostname, port)},
+		{Name: ldapPropertyPrefix + "base", Value: provider.SearchBase},
+		{Name: ldapPropertyPrefix + "search.filter.user", Value: ldapUserFilter(ldap, provider)},
+		{Name: ldapPropertyPrefix + "search.filter.group", Value: valueOrDefault(ldap.GroupFilter, "(objectClass=groupOfNames)")},
+		{Name: ldapPropertyPrefix + "search.attr.member", Value: valueOrDefault(ldap.MemberAttribute, "member")},
+		{Name: ldapPropertyPrefix + "search.attr.group.name", Value: valueOrDefault(ldap.GroupNameAttribute, "cn")},
+	}
+	if ldap.UserSearchBase != "" {
+		properties = append(properties, util.XmlNameValuePair{Name: ldapPropertyPrefix + "userbase", Value: ldap.UserSearchBase})
+	}
+	if ldap.GroupSearchBase != "" {
+		properties = append(properties, util.XmlNameValuePair{Name: ldapPropertyPrefix + "groupbase", Value: ldap.GroupSearchBase})
+	}
+	if provider.BindCredentials != nil {
+		properties = append(properties,
+			util.XmlNameValuePair{Name: ldapPropertyPrefix + "bind.user", Value: "${env." + LdapBindUserEnvName + "}"},
+			util.XmlNameValuePair{Name: ldapPropertyPrefix + "bind.password.file", Value: path.Join(LdapBindCredentialsDir, "password")},
+		)
+	}
+	if provider.TLS != nil {
+		properties = append(properties, util.XmlNameValuePair{Name: ldapPropertyPrefix + "ssl", Value: xmlTrue})
+		// web PKI uses the default truststore of the JVM
+		if provider.TLS.Verification.Server.CACert.SecretClass != "" {
+			properties = append(properties,
+				util.XmlNameValuePair{Name: ldapPropertyPrefix + "ssl.truststore", Value: path.Join(constants.KubedoopTlsDir, "truststore.p12")},
+				util.XmlNameValuePair{Name: ldapPropertyPrefix + "ssl.truststore.password", Value: MountedTruststorePassword(tls)},
+			)
+		}
+	}
+	return properties
+}
+
+func ldapUserFilter(ldap *hdfsv1alpha1.LdapGroupMappingSpec, provider *authv1alpha1.LDAPProvider) string {
+	if ldap.UserFilter != "" {
+		return ldap.UserFilter
+	}
+	uid := defaultLdapUidField
+	if provider.LDAPFieldNames != nil && provider.LDAPFieldNames.Uid != "" {
+		uid = provider.LDAPFieldNames.Uid
+	}
+	filter := fmt.Sprintf("(%s={0})", uid)
+	if provider.SearchFilter != "" {
+		return fmt.Sprintf("(&%s%s)", provider.SearchFilter, filter)
+	}
+	return filter
+}
+
+func valueOrDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+// LdapBindCredentialsVolumes returns the secret operator volume of the bind credentials.
+// Every container loading the namenode configuration needs it, hadoop reads the password file on start.
+func LdapBindCredentialsVolumes(provider *authv1alpha1.LDAPProvider) []corev1.Volume {
+	if provider == nil || provider.BindCredentials == nil {
+		return nil
+	}
+	return []corev1.Volume{*CredentialsVolume(LdapBindCredentialsVolumeName, provider.BindCredentials)}
+}
+
+// LdapBindCredentialsVolumeMounts returns the volume mounts of the bind credentials
+func LdapBindCredentialsVolumeMounts(provider *authv1alpha1.LDAPProvider) []corev1.VolumeMount {
+	if provider == nil || provider.BindCredentials == nil {
+		return nil
+	}
+	return []corev1.VolumeMount{
+		{
+			Name:      LdapBindCredentialsVolumeName,
+			MountPath: LdapBindCredentialsDir,
+		},
+	}
+}
+
+// ExportLdapBindUserScript exports the bind user referenced by `hadoop.security.group.mapping.ldap.bind.user`
+func ExportLdapBindUserScript() string {
+	return fmt.Sprintf(`if [[ -f %[1]s ]]; then
+  export %[2]s=$(cat %[1]s)
+fi`, path.Join(LdapBindCredentialsDir, "user"), LdapBindUserEnvName)
+}
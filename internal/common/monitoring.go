@@ -0,0 +1,391 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+
+	"emperror.dev/errors"
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/constant"
+	"github.com/zncdatadev/hdfs-operator/internal/util"
+	"github.com/zncdatadev/operator-go/pkg/builder"
+	"github.com/zncdatadev/operator-go/pkg/client"
+	opconstants "github.com/zncdatadev/operator-go/pkg/constants"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// the resources of the Prometheus operator are built as unstructured objects,
+// so the operator does not depend on its API module and its CRDs are only required with monitoring
+const (
+	prometheusApiVersion = "monitoring.coreos.com/v1"
+	ServiceMonitorKind   = "ServiceMonitor"
+	PrometheusRuleKind   = "PrometheusRule"
+
+	prometheusMetricsPath = "/prom"
+	labelPrometheusScrape = "prometheus.io/scrape"
+	tlsCaSecretKey        = "ca.crt"
+
+	defaultCapacityWarningPercent  = 80
+	defaultCapacityCriticalPercent = 90
+	defaultJournalLagTxns          = 1000
+)
+
+var monitoringLogger = ctrl.Log.WithName("monitoring")
+
+// prometheusTargetLabels are copied from the metrics Services to the scraped series,
+// prometheus replaces the `.`, `/` and `-` of the names with `_`
+var prometheusTargetLabels = []string{
+	opconstants.LabelKubernetesInstance,
+	opconstants.LabelKubernetesComponent,
+	opconstants.LabelKubernetesRoleGroup,
+}
+
+func IsMonitoringEnabled(clusterSpec *hdfsv1alpha1.ClusterConfigSpec) bool {
+	return clusterSpec.Monitoring != nil
+}
+
+// IsAlertsEnabled returns true if the PrometheusRule contains the alerts, the recording rules are always created
+func IsAlertsEnabled(monitoring *hdfsv1alpha1.MonitoringSpec) bool {
+	return monitoring.Alerts == nil || monitoring.Alerts.Enabled == nil || *monitoring.Alerts.Enabled
+}
+
+// MonitoringCoreSiteXml enables the PrometheusMetricsSink, which serves the metrics of all roles on `/prom` of the web UI
+func MonitoringCoreSiteXml() []util.XmlNameValuePair {
+	return []util.XmlNameValuePair{
+		{
+			Name:  "hadoop.prometheus.endpoint.enabled",
+			Value: xmlTrue,
+		},
+	}
+}
+
+var _ reconciler.Reconciler = &MonitoringCleanup{}
+
+// MonitoringCleanup deletes the ServiceMonitors and the PrometheusRule of the cluster when the monitoring is
+// disabled. Without the CRDs of the Prometheus operator there is nothing to delete.
+type MonitoringCleanup struct {
+	client *client.Client
+	hdfs   *hdfsv1alpha1.HdfsCluster
+}
+
+func NewMonitoringCleanup(client *client.Client, hdfs *hdfsv1alpha1.HdfsCluster) *MonitoringCleanup {
+	return &MonitoringCleanup{client: client, hdfs: hdfs}
+}
+
+func (r *MonitoringCleanup) GetName() string {
+	return r.hdfs.Name + "-monitoring-cleanup"
+}
+
+func (r *MonitoringCleanup) GetNamespace() string {
+	return r.hdfs.Namespace
+}
+
+func (r *MonitoringCleanup) GetClient() *client.Client {
+	return r.client
+}
+
+func (r *MonitoringCleanup) Reconcile(ctx context.Context) (ctrl.Result, error) {
+	for _, kind := range []string{ServiceMonitorKind, PrometheusRuleKind} {
+		list := &unstructured.UnstructuredList{}
+		list.SetAPIVersion(prometheusApiVersion)
+		list.SetKind(kind + "List")
+		if err := r.client.Client.List(ctx, list,
+			ctrlclient.InNamespace(r.hdfs.Namespace),
+			ctrlclient.MatchingLabels{opconstants.LabelKubernetesInstance: r.hdfs.Name},
+		); err != nil {
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return ctrl.Result{}, errors.WrapIfWithDetails(err, "failed to list monitoring resources", "kind", kind)
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			if !metav1.IsControlledBy(obj, r.hdfs) {
+				continue
+			}
+			monitoringLogger.Info("Deleting monitoring resource, the monitoring is disabled", "kind", kind, "name", obj.GetName())
+			if err := r.client.Client.Delete(ctx, obj); ctrlclient.IgnoreNotFound(err) != nil {
+				return ctrl.Result{}, errors.WrapIfWithDetails(err, "failed to delete monitoring resource", "kind", kind, "name", obj.GetName())
+			}
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *MonitoringCleanup) Ready(ctx context.Context) (ctrl.Result, error) {
+	return ctrl.Result{}, nil
+}
+
+// PrometheusResourceBuilder builds a resource of the Prometheus operator with the given spec
+type PrometheusResourceBuilder struct {
+	*builder.ObjectMeta
+	kind string
+	spec map[string]interface{}
+}
+
+var _ builder.ObjectBuilder = &PrometheusResourceBuilder{}
+
+func (b *PrometheusResourceBuilder) Build(_ context.Context) (ctrlclient.Object, error) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": b.spec}}
+	obj.SetAPIVersion(prometheusApiVersion)
+	obj.SetKind(b.kind)
+	objectMeta := b.GetObjectMeta()
+	obj.SetName(objectMeta.Name)
+	obj.SetNamespace(objectMeta.Namespace)
+	obj.SetLabels(objectMeta.Labels)
+	obj.SetAnnotations(objectMeta.Annotations)
+	return obj, nil
+}
+
+// NewRoleGroupServiceMonitor scrapes the metrics Service of the role group, see NewRoleGroupMetricsService
+func NewRoleGroupServiceMonitor(
+	client *client.Client,
+	roleGroupInfo *reconciler.RoleGroupInfo,
+	hdfs *hdfsv1alpha1.HdfsCluster,
+) reconciler.Reconciler {
+	clusterConfig := hdfs.Spec.ClusterConfig
+	monitoring := clusterConfig.Monitoring
+
+	selector := roleGroupInfo.GetLabels()
+	selector[labelPrometheusScrape] = xmlTrue
+	endpoint := map[string]interface{}{
+		"port":   hdfsv1alpha1.MetricName,
+		"path":   prometheusMetricsPath,
+		"scheme": "http",
+	}
+	if monitoring.ScrapeInterval != "" {
+		endpoint["interval"] = monitoring.ScrapeInterval
+	}
+	if IsTlsEnabled(clusterConfig) {
+		endpoint["scheme"] = "https"
+		// the pods are scraped by their address, which is not verified without the CA
+		tlsConfig := map[string]interface{}{"insecureSkipVerify": true}
+		if monitoring.TlsCaSecretName != "" {
+			tlsConfig = map[string]interface{}{
+				"ca": map[string]interface{}{
+					"secret": map[string]interface{}{
+						"name": monitoring.TlsCaSecretName,
+						"key":  tlsCaSecretKey,
+					},
+				},
+			}
+		}
+		endpoint["tlsConfig"] = tlsConfig
+	}
+
+	labels := roleGroupInfo.GetLabels()
+	maps.Copy(labels, monitoring.Labels)
+	serviceMonitorBuilder := &PrometheusResourceBuilder{
+		ObjectMeta: builder.NewObjectMeta(client, CreateServiceMetricsName(roleGroupInfo), func(o *builder.Options) {
+			o.Labels = labels
+			o.Annotations = roleGroupInfo.GetAnnotations()
+		}),
+		kind: ServiceMonitorKind,
+		spec: map[string]interface{}{
+			"selector": map[string]interface{}{
+				"matchLabels": toUnstructuredMap(selector),
+			},
+			"targetLabels": toUnstructuredSlice(prometheusTargetLabels),
+			"endpoints":    []interface{}{endpoint},
+		},
+	}
+	return reconciler.NewGenericResourceReconciler(client, serviceMonitorBuilder)
+}
+
+// NewPrometheusRule creates the recording rules and alerts of the cluster, the series are selected
+// by the namespace and instance label of the ServiceMonitors
+func NewPrometheusRule(
+	client *client.Client,
+	hdfs *hdfsv1alpha1.HdfsCluster,
+	clusterInfo reconciler.ClusterInfo,
+) reconciler.Reconciler {
+	monitoring := hdfs.Spec.ClusterConfig.Monitoring
+	groups := []interface{}{
+		map[string]interface{}{
+			"name":  "hdfs.rules",
+			"rules": toRules(recordingRules(hdfs)),
+		},
+	}
+	if IsAlertsEnabled(monitoring) {
+		groups = append(groups, map[string]interface{}{
+			"name":  "hdfs.alerts",
+			"rules": toRules(alertRules(hdfs)),
+		})
+	}
+
+	labels := clusterInfo.GetLabels()
+	maps.Copy(labels, monitoring.Labels)
+	ruleBuilder := &PrometheusResourceBuilder{
+		ObjectMeta: builder.NewObjectMeta(client, hdfs.Name, func(o *builder.Options) {
+			o.Labels = labels
+			o.Annotations = clusterInfo.GetAnnotations()
+		}),
+		kind: PrometheusRuleKind,
+		spec: map[string]interface{}{"groups": groups},
+	}
+	return reconciler.NewGenericResourceReconciler(client, ruleBuilder)
+}
+
+type prometheusRule struct {
+	record   string
+	alert    string
+	expr     string
+	duration string
+	severity string
+	summary  string
+}
+
+// seriesSelector selects the series of the cluster, optional of a role
+func seriesSelector(hdfs *hdfsv1alpha1.HdfsCluster, role constant.Role) string {
+	return "{" + seriesMatchers(hdfs, role) + "}"
+}
+
+func seriesMatchers(hdfs *hdfsv1alpha1.HdfsCluster, role constant.Role) string {
+	matchers := []string{
+		fmt.Sprintf(`namespace="%s"`, hdfs.Namespace),
+		fmt.Sprintf(`%s="%s"`, prometheusLabelName(opconstants.LabelKubernetesInstance), hdfs.Name),
+	}
+	if role != "" {
+		matchers = append(matchers, fmt.Sprintf(`%s="%s"`, prometheusLabelName(opconstants.LabelKubernetesComponent), role))
+	}
+	return strings.Join(matchers, ",")
+}
+
+func prometheusLabelName(label string) string {
+	return strings.NewReplacer(".", "_", "/", "_", "-", "_").Replace(label)
+}
+
+// recordingRules aggregate the metrics of the namenodes, the metrics of the PrometheusMetricsSink are
+// `<record>_<metric>` in snake case, e.g. `fs_namesystem_missing_blocks`. The standby reports the same
+// datanodes and blocks as the active namenode, so the maximum is used.
+func recordingRules(hdfs *hdfsv1alpha1.HdfsCluster) []prometheusRule {
+	nameNode := seriesSelector(hdfs, constant.NameNode)
+	by := fmt.Sprintf("max by (namespace, %s)", prometheusLabelName(opconstants.LabelKubernetesInstance))
+	return []prometheusRule{
+		{record: "hdfs:missing_blocks:max", expr: fmt.Sprintf("%s (fs_namesystem_missing_blocks%s)", by, nameNode)},
+		{record: "hdfs:under_replicated_blocks:max", expr: fmt.Sprintf("%s (fs_namesystem_under_replicated_blocks%s)", by, nameNode)},
+		{record: "hdfs:dead_datanodes:max", expr: fmt.Sprintf("%s (fs_namesystem_num_dead_data_nodes%s)", by, nameNode)},
+		{record: "hdfs:live_datanodes:max", expr: fmt.Sprintf("%s (fs_namesystem_num_live_data_nodes%s)", by, nameNode)},
+		{
+			record: "hdfs:capacity_used:ratio",
+			expr: fmt.Sprintf("%[1]s (fs_namesystem_capacity_used%[2]s) / %[1]s (fs_namesystem_capacity_total%[2]s)",
+				by, nameNode),
+		},
+	}
+}
+
+func alertRules(hdfs *hdfsv1alpha1.HdfsCluster) []prometheusRule {
+	warningPercent := int32(defaultCapacityWarningPercent)
+	criticalPercent := int32(defaultCapacityCriticalPercent)
+	journalLagTxns := int32(defaultJournalLagTxns)
+	if alerts := hdfs.Spec.ClusterConfig.Monitoring.Alerts; alerts != nil {
+		if alerts.CapacityWarningPercent != 0 {
+			warningPercent = alerts.CapacityWarningPercent
+		}
+		if alerts.CapacityCriticalPercent != 0 {
+			criticalPercent = alerts.CapacityCriticalPercent
+		}
+		if alerts.JournalLagTxns != 0 {
+			journalLagTxns = alerts.JournalLagTxns
+		}
+	}
+
+	cluster := seriesSelector(hdfs, "")
+	return []prometheusRule{
+		{
+			alert: "HdfsMissingBlocks", expr: "hdfs:missing_blocks:max" + cluster + " > 0",
+			duration: "5m", severity: "critical",
+			summary: "HDFS {{ $labels.app_kubernetes_io_instance }} has {{ $value }} blocks without any replica",
+		},
+		{
+			alert: "HdfsUnderReplicatedBlocks", expr: "hdfs:under_replicated_blocks:max" + cluster + " > 0",
+			duration: "30m", severity: "warning",
+			summary: "HDFS {{ $labels.app_kubernetes_io_instance }} has {{ $value }} under-replicated blocks",
+		},
+		{
+			alert: "HdfsDeadDataNodes", expr: "hdfs:dead_datanodes:max" + cluster + " > 0",
+			duration: "5m", severity: "warning",
+			summary: "HDFS {{ $labels.app_kubernetes_io_instance }} has {{ $value }} dead datanodes",
+		},
+		{
+			// the safe mode at startup, until enough blocks are reported
+			alert:    "HdfsNameNodeSafeMode",
+			expr:     fmt.Sprintf(`{__name__=~"startup_progress_safe_?mode_percent_complete",%s} < 1`, seriesMatchers(hdfs, constant.NameNode)),
+			duration: "15m", severity: "critical",
+			summary: "NameNode {{ $labels.pod }} is in safe mode for more than 15 minutes",
+		},
+		{
+			// the safe mode entered with `hdfs dfsadmin -safemode enter`, exported by the health probe of the operator
+			alert: "HdfsNameNodeManualSafeMode",
+			expr: fmt.Sprintf(`hdfs_cluster_manual_safe_mode{namespace="%s",cluster="%s"} > 0`,
+				hdfs.Namespace, hdfs.Name),
+			duration: "30m", severity: "warning",
+			summary: "HDFS {{ $labels.cluster }} is in a safe mode turned on manually for more than 30 minutes",
+		},
+		{
+			alert:    "HdfsJournalNodeLag",
+			expr:     fmt.Sprintf(`{__name__=~"journal_.*_current_lag_txns",%s} > %d`, seriesMatchers(hdfs, constant.JournalNode), journalLagTxns),
+			duration: "5m", severity: "warning",
+			summary: "JournalNode {{ $labels.pod }} lags {{ $value }} transactions behind the committed transactions",
+		},
+		{
+			alert: "HdfsCapacityWarning", expr: fmt.Sprintf("hdfs:capacity_used:ratio%s * 100 > %d", cluster, warningPercent),
+			duration: "15m", severity: "warning",
+			summary: "HDFS {{ $labels.app_kubernetes_io_instance }} uses {{ $value | humanize }}% of its capacity",
+		},
+		{
+			alert: "HdfsCapacityCritical", expr: fmt.Sprintf("hdfs:capacity_used:ratio%s * 100 > %d", cluster, criticalPercent),
+			duration: "5m", severity: "critical",
+			summary: "HDFS {{ $labels.app_kubernetes_io_instance }} uses {{ $value | humanize }}% of its capacity",
+		},
+	}
+}
+
+func toRules(rules []prometheusRule) []interface{} {
+	result := make([]interface{}, 0, len(rules))
+	for _, rule := range rules {
+		if rule.record != "" {
+			result = append(result, map[string]interface{}{
+				"record": rule.record,
+				"expr":   rule.expr,
+			})
+			continue
+		}
+		result = append(result, map[string]interface{}{
+			"alert": rule.alert,
+			"expr":  rule.expr,
+			"for":   rule.duration,
+			"labels": map[string]interface{}{
+				"severity": rule.severity,
+			},
+			"annotations": map[string]interface{}{
+				"summary": rule.summary,
+			},
+		})
+	}
+	return result
+}
+
+// toUnstructuredMap and toUnstructuredSlice convert to the JSON types of unstructured objects, which are deep copied
+func toUnstructuredMap(m map[string]string) map[string]interface{} {
+	result := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
+
+func toUnstructuredSlice(s []string) []interface{} {
+	result := make([]interface{}, 0, len(s))
+	for _, v := range s {
+		result = append(result, v)
+	}
+	return result
+}
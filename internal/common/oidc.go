@@ -5,7 +5,9 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
+	"fmt"
 	"net/url"
+	"path"
 	"strconv"
 	"strings"
 
@@ -13,6 +15,8 @@ import (
 	"github.com/zncdatadev/hdfs-operator/internal/constant"
 	authv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/authentication/v1alpha1"
 	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
+	"github.com/zncdatadev/operator-go/pkg/builder"
+	"github.com/zncdatadev/operator-go/pkg/constants"
 	"github.com/zncdatadev/operator-go/pkg/reconciler"
 	"github.com/zncdatadev/operator-go/pkg/util"
 	corev1 "k8s.io/api/core/v1"
@@ -24,6 +28,19 @@ var (
 	oidcLogger = ctrl.Log.WithName("oidc")
 )
 
+const (
+	// OidcUpstreamTlsVolumeName provides the CA of the TLS SecretClass in PEM, oauth2-proxy can not read the truststore
+	OidcUpstreamTlsVolumeName = "oidc-upstream-tls"
+	oidcUpstreamTlsDir        = constants.KubedoopRoot + "oidc-upstream-tls"
+	oidcAuthenticatedEmails   = "/tmp/authenticated-emails"
+)
+
+// IsOidcEnabled returns whether the web UIs are protected by the oauth2-proxy sidecar
+func IsOidcEnabled(clusterSpec *hdfsv1alpha1.ClusterConfigSpec) bool {
+	return clusterSpec.Authentication != nil && clusterSpec.Authentication.AuthenticationClass != "" &&
+		clusterSpec.Authentication.Oidc != nil
+}
+
 // OidcContainerBuilder builds OIDC proxy containers using new architecture
 type OidcContainerBuilder struct {
 	instance        *hdfsv1alpha1.HdfsCluster
@@ -68,17 +85,18 @@ func (b *OidcContainerBuilder) Build() *corev1.Container {
 	)
 
 	// Create OIDC component and build container
-	component := newOidcComponent(b.instance, b.port, b.oidcProvider, b.oidc)
+	component := newOidcComponent(b.instance, b.roleGroupInfo, b.port, b.oidcProvider, b.oidc)
 
 	return builder.BuildWithComponent(component)
 }
 
 // oidcComponent implements ContainerComponentInterface for OIDC proxy
 type oidcComponent struct {
-	instance     *hdfsv1alpha1.HdfsCluster
-	port         int32
-	oidcProvider *authv1alpha1.OIDCProvider
-	oidc         *hdfsv1alpha1.OidcSpec
+	instance      *hdfsv1alpha1.HdfsCluster
+	roleGroupInfo *reconciler.RoleGroupInfo
+	port          int32
+	oidcProvider  *authv1alpha1.OIDCProvider
+	oidc          *hdfsv1alpha1.OidcSpec
 }
 
 // Ensure oidcComponent implements all required interfaces
@@ -87,15 +105,17 @@ var _ ContainerPortsProvider = &oidcComponent{}
 
 func newOidcComponent(
 	instance *hdfsv1alpha1.HdfsCluster,
+	roleGroupInfo *reconciler.RoleGroupInfo,
 	port int32,
 	oidcProvider *authv1alpha1.OIDCProvider,
 	oidc *hdfsv1alpha1.OidcSpec,
 ) *oidcComponent {
 	return &oidcComponent{
-		instance:     instance,
-		port:         port,
-		oidcProvider: oidcProvider,
-		oidc:         oidc,
+		instance:      instance,
+		roleGroupInfo: roleGroupInfo,
+		port:          port,
+		oidcProvider:  oidcProvider,
+		oidc:          oidc,
 	}
 }
 
@@ -111,10 +131,14 @@ func (c *oidcComponent) GetCommand() []string {
 }
 
 func (c *oidcComponent) GetArgs() []string {
-	// OIDC proxy doesn't need complex args, command handles everything
-	return []string{
-		"/kubedoop/oauth2-proxy/oauth2-proxy --upstream=${UPSTREAM}",
+	// the options of oauth2-proxy are passed as environment variables, see GetEnvVars
+	args := "/kubedoop/oauth2-proxy/oauth2-proxy --upstream=${UPSTREAM}"
+	if len(c.oidc.AllowedEmails) != 0 {
+		// oauth2-proxy only reads the allowed emails from a file
+		args = fmt.Sprintf(`printf '%%s\n' "${OIDC_ALLOWED_EMAILS}" > %s
+%s`, oidcAuthenticatedEmails, args)
 	}
+	return []string{args}
 }
 
 func (c *oidcComponent) GetEnvVars() []corev1.EnvVar {
@@ -147,7 +171,7 @@ func (c *oidcComponent) GetEnvVars() []corev1.EnvVar {
 	tokenBytes := []byte(hashStr[:16])
 	cookieSecret := base64.StdEncoding.EncodeToString([]byte(base64.StdEncoding.EncodeToString(tokenBytes)))
 
-	return []corev1.EnvVar{
+	envs := []corev1.EnvVar{
 		{
 			Name:  "OAUTH2_PROXY_COOKIE_SECRET",
 			Value: cookieSecret,
@@ -182,6 +206,14 @@ func (c *oidcComponent) GetEnvVars() []corev1.EnvVar {
 				},
 			},
 		},
+		{
+			Name: "POD_NAME",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					FieldPath: "metadata.name",
+				},
+			},
+		},
 		{
 			Name:  "OAUTH2_PROXY_OIDC_ISSUER_URL",
 			Value: issuer.String(),
@@ -196,7 +228,7 @@ func (c *oidcComponent) GetEnvVars() []corev1.EnvVar {
 		},
 		{
 			Name:  "UPSTREAM",
-			Value: "http://$(POD_IP):" + strconv.Itoa(int(c.port)),
+			Value: c.upstream(),
 		},
 		{
 			Name:  "OAUTH2_PROXY_HTTP_ADDRESS",
@@ -206,10 +238,6 @@ func (c *oidcComponent) GetEnvVars() []corev1.EnvVar {
 			Name:  "OAUTH2_PROXY_CODE_CHALLENGE_METHOD",
 			Value: "S256",
 		},
-		{
-			Name:  "OAUTH2_PROXY_EMAIL_DOMAINS",
-			Value: "*",
-		},
 		{
 			Name:  "OAUTH2_PROXY_COOKIE_SECURE",
 			Value: "false",
@@ -219,13 +247,86 @@ func (c *oidcComponent) GetEnvVars() []corev1.EnvVar {
 			Value: "*",
 		},
 	}
+	return append(envs, c.accessEnvVars()...)
+}
+
+// upstream returns the web UI of the pod, with TLS the certificate is issued for the FQDN of the pod
+func (c *oidcComponent) upstream() string {
+	clusterConfig := c.instance.Spec.ClusterConfig
+	if !IsTlsEnabled(clusterConfig) {
+		return "http://$(POD_IP):" + strconv.Itoa(int(c.port))
+	}
+	return fmt.Sprintf("https://$(POD_NAME).%s.%s.svc.%s:%d",
+		c.roleGroupInfo.GetFullName(), c.instance.Namespace, clusterConfig.ClusterDomain, c.port)
+}
+
+// isOidcUpstreamTlsVerified returns whether the proxy verifies the certificate of the web UI
+func isOidcUpstreamTlsVerified(clusterConfig *hdfsv1alpha1.ClusterConfigSpec) bool {
+	if !IsTlsEnabled(clusterConfig) {
+		return false
+	}
+	verification := clusterConfig.Authentication.Oidc.UpstreamTlsVerification
+	return verification == nil || *verification
+}
+
+// accessEnvVars restricts the users, the session cookie and the upstream verification of the proxy
+func (c *oidcComponent) accessEnvVars() []corev1.EnvVar {
+	var envs []corev1.EnvVar
+	if len(c.oidc.AllowedEmails) != 0 {
+		envs = append(envs,
+			corev1.EnvVar{Name: "OIDC_ALLOWED_EMAILS", Value: strings.Join(c.oidc.AllowedEmails, "\n")},
+			corev1.EnvVar{Name: "OAUTH2_PROXY_AUTHENTICATED_EMAILS_FILE", Value: oidcAuthenticatedEmails},
+		)
+	} else {
+		envs = append(envs, corev1.EnvVar{Name: "OAUTH2_PROXY_EMAIL_DOMAINS", Value: "*"})
+	}
+	if len(c.oidc.AllowedGroups) != 0 {
+		envs = append(envs, corev1.EnvVar{Name: "OAUTH2_PROXY_ALLOWED_GROUPS", Value: strings.Join(c.oidc.AllowedGroups, ",")})
+	}
+	if len(c.oidc.SkipAuthPaths) != 0 {
+		envs = append(envs, corev1.EnvVar{Name: "OAUTH2_PROXY_SKIP_AUTH_ROUTES", Value: strings.Join(c.oidc.SkipAuthPaths, ",")})
+	}
+	if c.oidc.CookieDomain != "" {
+		envs = append(envs, corev1.EnvVar{Name: "OAUTH2_PROXY_COOKIE_DOMAINS", Value: c.oidc.CookieDomain})
+	}
+	if c.oidc.CookieLifetime != nil {
+		envs = append(envs, corev1.EnvVar{Name: "OAUTH2_PROXY_COOKIE_EXPIRE", Value: c.oidc.CookieLifetime.Duration.String()})
+	}
+	if IsTlsEnabled(c.instance.Spec.ClusterConfig) {
+		if isOidcUpstreamTlsVerified(c.instance.Spec.ClusterConfig) {
+			// go reads the system roots from SSL_CERT_FILE, the certificates of /etc/ssl/certs are still trusted
+			envs = append(envs, corev1.EnvVar{Name: "SSL_CERT_FILE", Value: path.Join(oidcUpstreamTlsDir, "ca.crt")})
+		} else {
+			envs = append(envs, corev1.EnvVar{Name: "OAUTH2_PROXY_SSL_UPSTREAM_INSECURE_SKIP_VERIFY", Value: "true"})
+		}
+	}
+	return envs
 }
 
 func (c *oidcComponent) GetVolumeMounts() []corev1.VolumeMount {
-	// OIDC proxy typically doesn't need additional volume mounts beyond common ones
+	if isOidcUpstreamTlsVerified(c.instance.Spec.ClusterConfig) {
+		return []corev1.VolumeMount{
+			{
+				Name:      OidcUpstreamTlsVolumeName,
+				MountPath: oidcUpstreamTlsDir,
+			},
+		}
+	}
 	return []corev1.VolumeMount{}
 }
 
+// OidcVolumes returns the volumes of the OIDC container, the CA to verify the web UI if TLS is enabled
+func OidcVolumes(instance *hdfsv1alpha1.HdfsCluster) []corev1.Volume {
+	clusterConfig := instance.Spec.ClusterConfig
+	if !isOidcUpstreamTlsVerified(clusterConfig) {
+		return nil
+	}
+	volume := builder.NewSecretOperatorVolume(OidcUpstreamTlsVolumeName, clusterConfig.Authentication.Tls.SecretClass)
+	volume.SetScope(&builder.SecretVolumeScope{Pod: true})
+	volume.SetFormatName(constants.TLSPEM)
+	return []corev1.Volume{*volume.Builde()}
+}
+
 // ContainerPortsProvider interface implementation
 func (c *oidcComponent) GetPorts() []corev1.ContainerPort {
 	return []corev1.ContainerPort{
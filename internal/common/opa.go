@@ -0,0 +1,85 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"emperror.dev/errors"
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/util"
+	"github.com/zncdatadev/operator-go/pkg/client"
+	corev1 "k8s.io/api/core/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// OpaDiscoveryKey is the key of the OPA url in the OPA discovery ConfigMap
+	OpaDiscoveryKey = "OPA"
+
+	defaultOpaPackage = "hdfs"
+)
+
+func IsOpaEnabled(clusterSpec *hdfsv1alpha1.ClusterConfigSpec) bool {
+	return clusterSpec.Authorization != nil && clusterSpec.Authorization.Opa != nil
+}
+
+// IsOpaGroupMappingEnabled returns whether the groups of users are resolved by OPA
+func IsOpaGroupMappingEnabled(clusterSpec *hdfsv1alpha1.ClusterConfigSpec) bool {
+	return IsOpaEnabled(clusterSpec) && clusterSpec.Authorization.Opa.GroupMapperClass != ""
+}
+
+// ResolveOpaUrl reads the OPA url from the OPA discovery ConfigMap
+func ResolveOpaUrl(ctx context.Context, client *client.Client, namespace string, opa *hdfsv1alpha1.OpaSpec) (string, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := client.Get(ctx, ctrlclient.ObjectKey{Namespace: namespace, Name: opa.ConfigMapName}, configMap); err != nil {
+		return "", errors.WrapIfWithDetails(err, "failed to get OPA discovery ConfigMap", "name", opa.ConfigMapName)
+	}
+	url, ok := configMap.Data[OpaDiscoveryKey]
+	if !ok || url == "" {
+		return "", errors.Errorf("OPA discovery ConfigMap %s has no %s", opa.ConfigMapName, OpaDiscoveryKey)
+	}
+	return strings.TrimSuffix(url, "/"), nil
+}
+
+// OpaPolicyUrl returns the url of a rule in the configured package, e.g. `http://opa:8081/v1/data/hdfs/allow`
+func OpaPolicyUrl(opa *hdfsv1alpha1.OpaSpec, opaUrl string, rule string) string {
+	pkg := opa.Package
+	if pkg == "" {
+		pkg = defaultOpaPackage
+	}
+	return fmt.Sprintf("%s/v1/data/%s/%s", opaUrl, strings.ReplaceAll(pkg, ".", "/"), rule)
+}
+
+// OpaCoreSiteXml resolves the groups of users with the group mapper of OPA
+func OpaCoreSiteXml(opa *hdfsv1alpha1.OpaSpec, opaUrl string) []util.XmlNameValuePair {
+	return []util.XmlNameValuePair{
+		{
+			Name:  "hadoop.security.group.mapping",
+			Value: opa.GroupMapperClass,
+		},
+		{
+			Name:  "hadoop.security.group.mapping.opa.policy.url",
+			Value: OpaPolicyUrl(opa, opaUrl, "groups"),
+		},
+		{
+			// all users are resolved by OPA, including the default static mapping of dr.who
+			Name:  "hadoop.user.group.static.mapping.overrides",
+			Value: "",
+		},
+	}
+}
+
+// OpaHdfsSiteXml authorizes the requests of the namenode with OPA
+func OpaHdfsSiteXml(opa *hdfsv1alpha1.OpaSpec, opaUrl string) []util.XmlNameValuePair {
+	return []util.XmlNameValuePair{
+		{
+			Name:  "dfs.namenode.inode.attributes.provider.class",
+			Value: opa.AuthorizerClass,
+		},
+		{
+			Name:  "hadoop.security.authorization.opa.policy.url",
+			Value: OpaPolicyUrl(opa, opaUrl, "allow"),
+		},
+	}
+}
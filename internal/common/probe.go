@@ -0,0 +1,83 @@
+package common
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"strings"
+
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/constant"
+	"github.com/zncdatadev/operator-go/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// probeScriptTemplate runs a health check with the keytab of the role. The ticket is cached in its own
+// ccache between the probes, the role itself logs in from the keytab.
+const probeScriptTemplate = `{{- if .kerberosEnabled }}
+{{- .kerberosEnv }}
+export KRB5CCNAME=/tmp/krb5cc_probe
+klist -s 2>/dev/null || kinit "{{ .principal }}" -kt {{ .keytab }} >/dev/null
+{{ end -}}
+{{ .check }}`
+
+// NewExecProbeAction returns the exec action running the check script of a role,
+// see HaServiceStateCheck and JmxCheck
+func NewExecProbeAction(
+	clusterConfig *hdfsv1alpha1.ClusterConfigSpec,
+	instanceName string,
+	namespace string,
+	role constant.Role,
+	check string,
+) *corev1.ExecAction {
+	data := CreateExportKrbRealmEnvData(clusterConfig)
+	maps.Copy(data, map[string]interface{}{
+		"principal": CreateKerberosPrincipal(clusterConfig, instanceName, namespace, role),
+		"keytab":    path.Join(constants.KubedoopKerberosDir, "keytab"),
+		"check":     check,
+	})
+	return &corev1.ExecAction{
+		Command: []string{"/bin/bash", "-euo", "pipefail", "-c", ParseTemplate(probeScriptTemplate, data)[0]},
+	}
+}
+
+// HaServiceStateCheck succeeds if the namenode of the pod is active, standby or observer
+func HaServiceStateCheck() string {
+	return `export HADOOP_CLIENT_OPTS="-Xmx64m"
+STATE=$(/kubedoop/hadoop/bin/hdfs haadmin -getServiceState "$POD_NAME" 2>/dev/null)
+[[ "$STATE" =~ ^(active|standby|observer)$ ]]`
+}
+
+// JmxCheck reads a bean of the JMX servlet of the web UI and runs condition on it, which reads the response
+// from $JMX. The web UI is reached at the pod address, with Kerberos it is requested with the host
+// of the HTTP principal to get a SPNEGO ticket for it.
+func JmxCheck(clusterConfig *hdfsv1alpha1.ClusterConfigSpec, instanceName string, namespace string, port int32, bean string, condition string) string {
+	scheme := "http"
+	// the certificate is issued for the FQDN of the pod, the pod address is not verified
+	var curlOptions []string
+	if IsTlsEnabled(clusterConfig) {
+		scheme = "https"
+		curlOptions = append(curlOptions, "--insecure")
+	}
+	host := "$POD_NAME"
+	if IsKerberosEnabled(clusterConfig) {
+		host = spnegoHost(clusterConfig, instanceName, namespace)
+		curlOptions = append(curlOptions, "--negotiate", "-u", ":")
+	}
+	check := fmt.Sprintf(`POD_ADDRESS=$(hostname -i | cut -d' ' -f1)
+JMX=$(curl -sSf --max-time 5 %s --resolve "%s:%d:$POD_ADDRESS" "%s://%s:%d/jmx?qry=%s")`,
+		strings.Join(curlOptions, " "), host, port, scheme, host, port, bean)
+	if condition != "" {
+		check += "\n" + condition
+	}
+	return check
+}
+
+// spnegoHost returns the host of the HTTP principal of the web UIs
+func spnegoHost(clusterConfig *hdfsv1alpha1.ClusterConfigSpec, instanceName string, namespace string) string {
+	principal := KerberosPrincipal(clusterConfig.Authentication.Kerberos, httpKerberosService, instanceName, namespace,
+		clusterConfig.ClusterDomain, scriptKerberosRealm)
+	_, host, _ := strings.Cut(principal, "/")
+	host, _, _ = strings.Cut(host, "@")
+	return host
+}
@@ -0,0 +1,62 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/util"
+)
+
+const proxyUserPropertyPrefix = "hadoop.proxyuser."
+
+func IsProxyUsersEnabled(clusterSpec *hdfsv1alpha1.ClusterConfigSpec) bool {
+	return len(clusterSpec.ProxyUsers) != 0
+}
+
+// ProxyUserCoreSiteXml returns the `hadoop.proxyuser.<user>.*` properties of the proxy users
+func ProxyUserCoreSiteXml(proxyUsers []hdfsv1alpha1.ProxyUserSpec) []util.XmlNameValuePair {
+	var properties []util.XmlNameValuePair
+	for _, proxyUser := range proxyUsers {
+		for _, property := range []struct {
+			name   string
+			values []string
+		}{
+			{"hosts", proxyUser.Hosts},
+			{"groups", proxyUser.Groups},
+			{"users", proxyUser.Users},
+		} {
+			if len(property.values) == 0 {
+				continue
+			}
+			properties = append(properties, util.XmlNameValuePair{
+				Name:  fmt.Sprintf("%s%s.%s", proxyUserPropertyPrefix, proxyUser.User, property.name),
+				Value: strings.Join(property.values, ","),
+			})
+		}
+	}
+	return properties
+}
+
+// MakeProxyUsersData returns the proxy-users.xml of the namenodes, which only contains the proxy users
+func MakeProxyUsersData(clusterSpec *hdfsv1alpha1.ClusterConfigSpec) string {
+	return util.Append(emptyXmlConfig, ProxyUserCoreSiteXml(clusterSpec.ProxyUsers))
+}
+
+// ProxyUserConfig extracts the proxy user properties of proxy-users.xml in a stable order,
+// used to detect changes of the proxy users
+func ProxyUserConfig(proxyUsers string) (string, error) {
+	dom, err := util.Parse(proxyUsers)
+	if err != nil {
+		return "", err
+	}
+	var properties []string
+	for _, property := range dom.Properties {
+		if strings.HasPrefix(property.Name, proxyUserPropertyPrefix) {
+			properties = append(properties, property.Name+"="+property.Value)
+		}
+	}
+	sort.Strings(properties)
+	return strings.Join(properties, "\n"), nil
+}
@@ -7,6 +7,7 @@ import (
 	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
 	"github.com/zncdatadev/hdfs-operator/internal/constant"
 	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
+	"github.com/zncdatadev/operator-go/pkg/builder"
 	"github.com/zncdatadev/operator-go/pkg/client"
 	"github.com/zncdatadev/operator-go/pkg/reconciler"
 	opgoutil "github.com/zncdatadev/operator-go/pkg/util"
@@ -81,10 +82,7 @@ func NewBaseHdfsRoleReconciler(
 	componentType constant.Role,
 	componentRec HdfsComponentReconciler,
 ) *BaseHdfsRoleReconciler {
-	stopped := false
-	if hdfsCluster.Spec.ClusterOperationSpec != nil {
-		stopped = hdfsCluster.Spec.ClusterOperationSpec.Stopped
-	}
+	stopped := IsRoleStopped(hdfsCluster, componentType)
 
 	return &BaseHdfsRoleReconciler{
 		BaseRoleReconciler: *reconciler.NewBaseRoleReconciler(
@@ -102,6 +100,54 @@ func NewBaseHdfsRoleReconciler(
 	}
 }
 
+// IsRoleStopped reports whether the StatefulSets of the role are scaled to zero. The roles are stopped one after
+// another by the graceful stop of the cluster: the datanodes first, then the namenodes and the journalnodes.
+func IsRoleStopped(hdfsCluster *hdfsv1alpha1.HdfsCluster, role constant.Role) bool {
+	stop := hdfsCluster.Status.Stop
+	if stop == nil {
+		return false
+	}
+	switch stop.Phase {
+	case hdfsv1alpha1.StopPhaseDataNodes:
+		return role == constant.DataNode
+	case hdfsv1alpha1.StopPhaseNameNodes:
+		return role != constant.JournalNode
+	case hdfsv1alpha1.StopPhaseJournalNodes, hdfsv1alpha1.StopPhaseStopped:
+		return true
+	default:
+		return false
+	}
+}
+
+// Reconcile reconciles the resources of the role groups and the PodDisruptionBudget of the role. The role config
+// of HDFS extends the one of operator-go, which BaseRoleReconciler can not read, so the PodDisruptionBudget
+// is reconciled here.
+func (r *BaseHdfsRoleReconciler) Reconcile(ctx context.Context) (ctrl.Result, error) {
+	for _, resource := range r.GetResources() {
+		if result, err := resource.Reconcile(ctx); !result.IsZero() || err != nil {
+			return result, err
+		}
+	}
+	pdbReconciler, err := r.pdbReconciler()
+	if err != nil || pdbReconciler == nil {
+		return ctrl.Result{}, err
+	}
+	return pdbReconciler.Reconcile(ctx)
+}
+
+// pdbReconciler returns the reconciler of the PodDisruptionBudget of the role, nil if it is not enabled
+func (r *BaseHdfsRoleReconciler) pdbReconciler() (reconciler.Reconciler, error) {
+	roleConfig := r.Spec.RoleConfig
+	if roleConfig == nil || roleConfig.PodDisruptionBudget == nil || !roleConfig.PodDisruptionBudget.Enabled {
+		return nil, nil
+	}
+	return reconciler.NewPDBReconciler(r.Client, r.GetFullName(), func(o *builder.PDBBuilderOptions) {
+		o.Labels = r.RoleInfo.GetLabels()
+		o.Annotations = r.RoleInfo.GetAnnotations()
+		o.MaxUnavailableAmount = roleConfig.PodDisruptionBudget.MaxUnavailable
+	})
+}
+
 // RegisterResources registers all resources for all role groups
 func (r *BaseHdfsRoleReconciler) RegisterResources(ctx context.Context) error {
 	for name, roleGroup := range r.Spec.RoleGroups {
@@ -181,6 +227,9 @@ func RegisterStandardResources(
 	)
 	if metricsServiceReconciler != nil {
 		reconcilers = append(reconcilers, metricsServiceReconciler)
+		if IsMonitoringEnabled(hdfsCluster.Spec.ClusterConfig) {
+			reconcilers = append(reconcilers, NewRoleGroupServiceMonitor(client, roleGroupInfo, hdfsCluster))
+		}
 	}
 
 	// Create StatefulSet
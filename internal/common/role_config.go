@@ -90,8 +90,9 @@ func (n *RoleNodeConfig) MergeDefaultConfig(mergedCfg *hdfsv1alpha1.ConfigSpec)
 		// if cpu and memory both null, then not set resources
 		if mergedResource.CPU == nil && mergedResource.Memory == nil {
 			resourceRes = &commonsv1alpha1.ResourcesSpec{
-				CPU:    nil,
-				Memory: nil,
+				CPU:     nil,
+				Memory:  nil,
+				Storage: mergedResource.Storage,
 			}
 		} else {
 			if mergedResource.CPU == nil {
@@ -0,0 +1,120 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"emperror.dev/errors"
+	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
+	s3v1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/s3/v1alpha1"
+	"github.com/zncdatadev/operator-go/pkg/builder"
+	"github.com/zncdatadev/operator-go/pkg/client"
+	corev1 "k8s.io/api/core/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// S3Target is a location in a S3Bucket, resolved with its S3Connection
+type S3Target struct {
+	BucketName  string
+	Prefix      string
+	Endpoint    string
+	Region      string
+	PathStyle   bool
+	Credentials *commonsv1alpha1.Credentials
+}
+
+// ResolveS3Target get the S3Bucket by name and resolve its connection
+func ResolveS3Target(
+	ctx context.Context,
+	client *client.Client,
+	namespace string,
+	bucketName string,
+	prefix string,
+) (*S3Target, error) {
+	bucket := &s3v1alpha1.S3Bucket{}
+	if err := client.Get(ctx, ctrlclient.ObjectKey{Namespace: namespace, Name: bucketName}, bucket); err != nil {
+		return nil, errors.WrapIfWithDetails(err, "failed to get S3Bucket", "name", bucketName)
+	}
+
+	connection := bucket.Spec.Connection
+	if connection == nil {
+		return nil, errors.Errorf("S3Bucket %s has no connection", bucketName)
+	}
+	connectionSpec := connection.Inline
+	if connectionSpec == nil {
+		if connection.Reference == "" {
+			return nil, errors.Errorf("S3Bucket %s has neither inline nor referenced connection", bucketName)
+		}
+		s3Connection := &s3v1alpha1.S3Connection{}
+		if err := client.Get(ctx, ctrlclient.ObjectKey{Namespace: namespace, Name: connection.Reference}, s3Connection); err != nil {
+			return nil, errors.WrapIfWithDetails(err, "failed to get S3Connection", "name", connection.Reference)
+		}
+		connectionSpec = &s3Connection.Spec
+	}
+
+	scheme := "http"
+	if connectionSpec.Tls != nil {
+		scheme = "https"
+	}
+	endpoint := fmt.Sprintf("%s://%s", scheme, connectionSpec.Host)
+	if connectionSpec.Port != 0 {
+		endpoint = fmt.Sprintf("%s:%d", endpoint, connectionSpec.Port)
+	}
+
+	return &S3Target{
+		BucketName:  bucket.Spec.BucketName,
+		Prefix:      strings.Trim(prefix, "/"),
+		Endpoint:    endpoint,
+		Region:      connectionSpec.Region,
+		PathStyle:   connectionSpec.PathStyle,
+		Credentials: connectionSpec.Credentials,
+	}, nil
+}
+
+// Uri returns the s3a uri of the location
+func (t *S3Target) Uri() string {
+	if t.Prefix == "" {
+		return fmt.Sprintf("s3a://%s", t.BucketName)
+	}
+	return fmt.Sprintf("s3a://%s/%s", t.BucketName, t.Prefix)
+}
+
+// HadoopOptions returns the generic options passed to `hdfs dfs` to access the bucket.
+// Credentials are read from the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables.
+func (t *S3Target) HadoopOptions() string {
+	options := []string{
+		"-Dfs.s3a.endpoint=" + t.Endpoint,
+		fmt.Sprintf("-Dfs.s3a.path.style.access=%t", t.PathStyle),
+	}
+	if t.Region != "" {
+		options = append(options, "-Dfs.s3a.endpoint.region="+t.Region)
+	}
+	return strings.Join(options, " ")
+}
+
+// CredentialsVolume returns a secret operator volume providing the credentials of the SecretClass,
+// e.g. the `ACCESS_KEY` and `SECRET_KEY` of S3 or the `user` and `password` of a LDAP bind
+func CredentialsVolume(name string, credentials *commonsv1alpha1.Credentials) *corev1.Volume {
+	volume := builder.NewSecretOperatorVolume(name, credentials.SecretClass)
+	if scope := credentials.Scope; scope != nil {
+		volume.SetScope(&builder.SecretVolumeScope{
+			Pod:            scope.Pod,
+			Node:           scope.Node,
+			Service:        scope.Services,
+			ListenerVolume: scope.ListenerVolumes,
+		})
+	}
+	return volume.Builde()
+}
+
+// ExportS3CredentialsScript export the s3 credentials mounted in credentialsDir,
+// tracing is disabled to keep the credentials out of the logs
+func ExportS3CredentialsScript(credentialsDir string) string {
+	return fmt.Sprintf(`set +x
+export AWS_ACCESS_KEY_ID=$(cat %s)
+export AWS_SECRET_ACCESS_KEY=$(cat %s)
+set -x
+`, path.Join(credentialsDir, "ACCESS_KEY"), path.Join(credentialsDir, "SECRET_KEY"))
+}
@@ -0,0 +1,65 @@
+package common
+
+import (
+	"strings"
+
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/util"
+)
+
+func IsServiceAuthorizationEnabled(clusterSpec *hdfsv1alpha1.ClusterConfigSpec) bool {
+	return clusterSpec.ServiceAuthorization != nil
+}
+
+// ServiceAuthorizationCoreSiteXml enables service level authorization
+func ServiceAuthorizationCoreSiteXml() []util.XmlNameValuePair {
+	return []util.XmlNameValuePair{
+		{
+			Name:  "hadoop.security.authorization",
+			Value: xmlTrue,
+		},
+	}
+}
+
+// ServiceAclXml returns the configured ACLs of hadoop-policy.xml
+func ServiceAclXml(spec *hdfsv1alpha1.ServiceAuthorizationSpec) []util.XmlNameValuePair {
+	acls := []struct {
+		name string
+		acl  *hdfsv1alpha1.ServiceAclSpec
+	}{
+		{"security.service.authorization.default.acl", spec.Default},
+		{"security.client.protocol.acl", spec.Client},
+		{"security.client.datanode.protocol.acl", spec.ClientDatanode},
+		{"security.datanode.protocol.acl", spec.Datanode},
+		{"security.inter.datanode.protocol.acl", spec.InterDatanode},
+		{"security.namenode.protocol.acl", spec.Namenode},
+		{"security.ha.service.protocol.acl", spec.HaAdmin},
+		{"security.zkfc.protocol.acl", spec.Zkfc},
+		{"security.qjournal.service.protocol.acl", spec.Qjournal},
+		{"security.interqjournal.service.protocol.acl", spec.InterQjournal},
+		{"security.refresh.policy.protocol.acl", spec.RefreshPolicy},
+		{"security.refresh.user.mappings.protocol.acl", spec.RefreshUserMappings},
+		{"security.refresh.callqueue.protocol.acl", spec.RefreshCallQueue},
+		{"security.get.user.mappings.protocol.acl", spec.GetUserMappings},
+		{"security.reconfiguration.protocol.acl", spec.Reconfiguration},
+	}
+
+	properties := make([]util.XmlNameValuePair, 0, len(acls))
+	for _, acl := range acls {
+		if acl.acl == nil {
+			continue
+		}
+		properties = append(properties, util.XmlNameValuePair{Name: acl.name, Value: FormatServiceAcl(acl.acl)})
+	}
+	return properties
+}
+
+// FormatServiceAcl formats an ACL as `user1,user2 group1,group2`, a single space allows nobody
+func FormatServiceAcl(acl *hdfsv1alpha1.ServiceAclSpec) string {
+	for _, user := range acl.Users {
+		if user == "*" {
+			return "*"
+		}
+	}
+	return strings.Join(acl.Users, ",") + " " + strings.Join(acl.Groups, ",")
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/zncdatadev/operator-go/pkg/client"
 	"github.com/zncdatadev/operator-go/pkg/reconciler"
 	"github.com/zncdatadev/operator-go/pkg/util"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -22,6 +23,8 @@ type StatefulSetBuilder struct {
 	roleType      constant.Role
 	ctx           context.Context
 	component     StatefulSetComponentBuilder
+	// sidecarImages are the images of the added sidecars, their pull secrets are added to the pods
+	sidecarImages []*util.Image
 }
 
 // NewStatefulSetBuilder creates a new StatefulSetBuilder with common configuration
@@ -96,8 +99,24 @@ func (b *StatefulSetBuilder) Build(ctx context.Context) (ctrlclient.Object, erro
 		b.AddInitContainer(&initContainer)
 	}
 
+	// The pods are restarted when the keystore password in the Secret of jksPasswordSecretRef is rotated
+	jksPasswordVersion := ""
+	if IsTlsEnabled(b.instance.Spec.ClusterConfig) {
+		var err error
+		if jksPasswordVersion, err = JksPasswordVersion(ctx, b.Client, b.instance.Namespace, b.instance.Spec.ClusterConfig.Authentication.Tls); err != nil {
+			return nil, err
+		}
+	}
+
 	// Get common volumes
-	commonVolumes := GetCommonVolumes(b.instance.Spec.ClusterConfig, b.instance.GetName(), b.roleGroupInfo)
+	var auditLog *hdfsv1alpha1.AuditLogSpec
+	if b.roleType == constant.NameNode {
+		auditLog = GetAuditLog(b.instance)
+	}
+	commonVolumes, err := GetCommonVolumes(b.instance.Spec.ClusterConfig, b.instance.GetName(), b.roleGroupInfo, auditLog)
+	if err != nil {
+		return nil, err
+	}
 
 	// Add common volumes
 	b.AddVolumes(commonVolumes)
@@ -124,8 +143,21 @@ func (b *StatefulSetBuilder) Build(ctx context.Context) (ctrlclient.Object, erro
 		return nil, err
 	}
 
-	// Set parallel pod management for faster scaling
-	// sts.Spec.PodManagementPolicy = appv1.ParallelPodManagement
+	// The role image is pulled with the pull secret of the builder, the sidecars may need other secrets
+	AddImagePullSecrets(&sts.Spec.Template.Spec, b.sidecarImages...)
+
+	if jksPasswordVersion != "" {
+		if sts.Spec.Template.Annotations == nil {
+			sts.Spec.Template.Annotations = make(map[string]string)
+		}
+		sts.Spec.Template.Annotations[JksPasswordVersionAnnotation] = jksPasswordVersion
+	}
+
+	// A journalnode is ready once a namenode wrote to its journal, which needs a quorum of journalnodes,
+	// so the journalnodes do not wait for each other
+	if b.roleType == constant.JournalNode {
+		sts.Spec.PodManagementPolicy = appsv1.ParallelPodManagement
+	}
 
 	// Set security context if provided
 	if securityContext := b.component.GetSecurityContext(); securityContext != nil {
@@ -148,8 +180,10 @@ func (b *StatefulSetBuilder) addVectorContainer() error {
 	if vectorEnable, err := IsVectorEnable(b.RoleGroupConfig.Logging); err != nil {
 		return err
 	} else if vectorEnable {
-		vectorFactory := GetVectorFactory(b.Image)
+		vectorImage := VectorImage(b.instance, b.Image)
+		vectorFactory := GetVectorFactory(vectorImage)
 		if vectorFactory != nil {
+			b.sidecarImages = append(b.sidecarImages, vectorImage)
 			b.AddContainer(vectorFactory.GetContainer())
 			b.AddVolumes(vectorFactory.GetVolumes())
 		}
@@ -161,12 +195,15 @@ func (b *StatefulSetBuilder) addVectorContainer() error {
 // addOIDCContainer adds OIDC container if authentication is configured
 func (b *StatefulSetBuilder) addOIDCContainer(component StatefulSetComponentBuilder) error {
 	if b.instance.Spec.ClusterConfig.Authentication != nil && b.instance.Spec.ClusterConfig.Authentication.AuthenticationClass != "" {
-		oidcContainer, err := MakeOidcContainer(b.ctx, b.Client.Client, b.GetInstance(), b.roleGroupInfo, b.RoleGroupConfig, component.GetHttpPort(), b.Image)
+		oauth2ProxyImage := Oauth2ProxyImage(b.instance, b.Image)
+		oidcContainer, err := MakeOidcContainer(b.ctx, b.Client.Client, b.GetInstance(), b.roleGroupInfo, b.RoleGroupConfig, component.GetHttpPort(), oauth2ProxyImage)
 		if err != nil {
 			return err
 		}
 		if oidcContainer != nil {
+			b.sidecarImages = append(b.sidecarImages, oauth2ProxyImage)
 			b.AddContainer(oidcContainer)
+			b.AddVolumes(OidcVolumes(b.instance))
 		}
 	}
 	return nil
@@ -1,19 +1,40 @@
 package common
 
 import (
+	"context"
 	"fmt"
 
+	"emperror.dev/errors"
 	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
 	"github.com/zncdatadev/hdfs-operator/internal/util"
+	"github.com/zncdatadev/operator-go/pkg/client"
 	"github.com/zncdatadev/operator-go/pkg/constants"
 	"github.com/zncdatadev/operator-go/pkg/reconciler"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-const TlsVolumeName = "tls"
+const (
+	TlsVolumeName = "tls"
+
+	// JksPasswordEnvName is the env var providing the keystore password to the containers
+	JksPasswordEnvName = "JKS_PASSWORD"
+	// JksPasswordPlaceholder is rendered into ssl-server.xml and ssl-client.xml instead of the password,
+	// it is substituted when the container starts.
+	JksPasswordPlaceholder = "${env." + JksPasswordEnvName + "}"
+
+	// JksPasswordVersionAnnotation is the resourceVersion of the Secret of jksPasswordSecretRef on the pods,
+	// they are restarted when the password is rotated
+	JksPasswordVersionAnnotation = "hdfs.kubedoop.dev/jks-password-version"
+
+	// jksTransportPassword is the password of the stores created by the secret-operator if the password is
+	// read from jksPasswordSecretRef. The secret-operator only accepts the password in the volume annotation,
+	// so the stores are copied with the password of the Secret when the container starts.
+	jksTransportPassword = "changeit"
+)
 
 func IsTlsEnabled(clusterSpec *hdfsv1alpha1.ClusterConfigSpec) bool {
 	return clusterSpec.Authentication != nil && clusterSpec.Authentication.Tls != nil
@@ -77,22 +98,6 @@ func WebUiPortProbe(clusterSpec *hdfsv1alpha1.ClusterConfigSpec) corev1.URISchem
 	}
 }
 
-func TlsHttpGetAction(clusterSpec *hdfsv1alpha1.ClusterConfigSpec, probePath string) *corev1.HTTPGetAction {
-	if IsTlsEnabled(clusterSpec) {
-		return &corev1.HTTPGetAction{
-			Path:   probePath,
-			Port:   intstr.FromString(hdfsv1alpha1.HttpsName),
-			Scheme: corev1.URISchemeHTTPS,
-		}
-	} else {
-		return &corev1.HTTPGetAction{
-			Path:   probePath,
-			Port:   intstr.FromString(hdfsv1alpha1.HttpName),
-			Scheme: corev1.URISchemeHTTP,
-		}
-	}
-}
-
 func TlsVolumeMounts() []corev1.VolumeMount {
 	return []corev1.VolumeMount{
 		{
@@ -120,7 +125,7 @@ func TlsHdfsSiteXml(clusterSpec *hdfsv1alpha1.ClusterConfigSpec) []util.XmlNameV
 	}
 }
 
-func CreateTlsSecretPvc(secretClass string, jksPassword string, roleGroupInfo *reconciler.RoleGroupInfo) corev1.Volume {
+func CreateTlsSecretPvc(tls *hdfsv1alpha1.TlsSpec, roleGroupInfo *reconciler.RoleGroupInfo) corev1.Volume {
 	return corev1.Volume{
 		Name: TlsVolumeName,
 		VolumeSource: corev1.VolumeSource{
@@ -128,10 +133,10 @@ func CreateTlsSecretPvc(secretClass string, jksPassword string, roleGroupInfo *r
 				VolumeClaimTemplate: &corev1.PersistentVolumeClaimTemplate{
 					ObjectMeta: metav1.ObjectMeta{
 						Annotations: map[string]string{
-							constants.AnnotationSecretsClass:          secretClass,
+							constants.AnnotationSecretsClass:          tls.SecretClass,
 							constants.AnnotationSecretsScope:          "pod,node,service=" + CreateServiceMetricsName(roleGroupInfo),
 							constants.AnnotationSecretsFormat:         "tls-p12",
-							constants.AnnotationSecretsPKCS12Password: jksPassword,
+							constants.AnnotationSecretsPKCS12Password: mountedStorePassword(tls),
 						},
 					},
 					Spec: corev1.PersistentVolumeClaimSpec{
@@ -152,3 +157,113 @@ func CreateTlsSecretPvc(secretClass string, jksPassword string, roleGroupInfo *r
 		},
 	}
 }
+
+// mountedStorePassword returns the password of the stores in the tls volume
+func mountedStorePassword(tls *hdfsv1alpha1.TlsSpec) string {
+	if tls.JksPasswordSecretRef != nil {
+		return jksTransportPassword
+	}
+	return tls.JksPassword
+}
+
+// MountedTruststorePassword returns the password of the truststore in the tls volume for configs which are
+// not substituted, like the truststore of the LDAP group mapping
+func MountedTruststorePassword(tls *hdfsv1alpha1.TlsSpec) string {
+	if tls != nil && tls.JksPasswordSecretRef != nil {
+		return jksTransportPassword
+	}
+	return JksPasswordPlaceholder
+}
+
+// JksPasswordVersion returns the resourceVersion of the Secret of jksPasswordSecretRef, it is empty if the
+// password is not read from a Secret. The password itself is only passed to the containers by the Secret.
+// Only the metadata of the Secret is read, it is served by the metadata cache of the Secret watch. A missing
+// key is reported by the kubelet when the containers are created.
+func JksPasswordVersion(ctx context.Context, client *client.Client, namespace string, tls *hdfsv1alpha1.TlsSpec) (string, error) {
+	if tls.JksPasswordSecretRef == nil {
+		return "", nil
+	}
+	secret := &metav1.PartialObjectMetadata{}
+	secret.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Secret"))
+	// the wrapped client can not resolve the kind of PartialObjectMetadata, it is read with the ctrl client
+	if err := client.GetCtrlClient().Get(ctx, ctrlclient.ObjectKey{Namespace: namespace, Name: tls.JksPasswordSecretRef.Name}, secret); err != nil {
+		return "", errors.WrapIfWithDetails(err, "failed to get jks password secret", "name", tls.JksPasswordSecretRef.Name)
+	}
+	return secret.ResourceVersion, nil
+}
+
+func jksPasswordSecretKey(secretRef *hdfsv1alpha1.SecretKeyRefSpec) string {
+	if secretRef.Key == "" {
+		return "password"
+	}
+	return secretRef.Key
+}
+
+// JksPasswordEnvVar provides the keystore password to a container,
+// from the referenced secret if jksPasswordSecretRef is set.
+func JksPasswordEnvVar(tls *hdfsv1alpha1.TlsSpec) corev1.EnvVar {
+	if tls.JksPasswordSecretRef == nil {
+		return corev1.EnvVar{Name: JksPasswordEnvName, Value: tls.JksPassword}
+	}
+	return corev1.EnvVar{
+		Name: JksPasswordEnvName,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: tls.JksPasswordSecretRef.Name},
+				Key:                  jksPasswordSecretKey(tls.JksPasswordSecretRef),
+			},
+		},
+	}
+}
+
+// SubstituteJksPasswordScript replaces the password placeholder of ssl-server.xml and ssl-client.xml
+// in $HADOOP_CONF_DIR. If the password is read from a Secret, the stores are copied to $HADOOP_CONF_DIR
+// with the password first. Tracing is disabled so the password is not written to the log, it is restored
+// afterwards.
+func SubstituteJksPasswordScript(tls *hdfsv1alpha1.TlsSpec) string {
+	copyStores := ""
+	if tls.JksPasswordSecretRef != nil {
+		copyStores = `for store in keystore.p12 truststore.p12
+do
+    "${JAVA_HOME:+$JAVA_HOME/bin/}keytool" -importkeystore -noprompt \
+        -srckeystore "` + constants.KubedoopTlsDir + `$store" -srcstoretype PKCS12 -srcstorepass ` + jksTransportPassword + ` \
+        -destkeystore "$HADOOP_CONF_DIR/$store" -deststoretype PKCS12 -deststorepass:env ` + JksPasswordEnvName + `
+done
+`
+	}
+	return `case $- in *x*) jks_xtrace=1 ;; *) jks_xtrace= ;; esac
+{ set +x; } 2>/dev/null
+` + copyStores + `jks_password=$(printf '%s' "$` + JksPasswordEnvName + `" | sed -e 's/&/\&amp;/g' -e 's/</\&lt;/g' -e 's/>/\&gt;/g' -e 's/[\\|&]/\\&/g')
+for ssl_file in ssl-server.xml ssl-client.xml
+do
+    if [ -f "$HADOOP_CONF_DIR/$ssl_file" ]
+    then
+        sed -i "s|\${env\.` + JksPasswordEnvName + `}|${jks_password}|g` + storeLocationSed(tls) + `" "$HADOOP_CONF_DIR/$ssl_file"
+    fi
+done
+unset jks_password
+if [ -n "$jks_xtrace" ]
+then
+    set -x
+fi
+`
+}
+
+// storeLocationSed returns the sed command moving the locations of the stores to the copies in $HADOOP_CONF_DIR
+func storeLocationSed(tls *hdfsv1alpha1.TlsSpec) string {
+	if tls.JksPasswordSecretRef == nil {
+		return ""
+	}
+	return `;s|` + constants.KubedoopTlsDir + `\([a-z]*\.p12\)|$HADOOP_CONF_DIR/\1|g`
+}
+
+// CreateJksPasswordData returns the template data substituting the keystore password
+func CreateJksPasswordData(clusterConfig *hdfsv1alpha1.ClusterConfigSpec) map[string]interface{} {
+	script := ""
+	if IsTlsEnabled(clusterConfig) {
+		script = SubstituteJksPasswordScript(clusterConfig.Authentication.Tls)
+	}
+	return map[string]interface{}{
+		"substituteJksPassword": script,
+	}
+}
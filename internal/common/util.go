@@ -138,6 +138,7 @@ func GetCommonContainerEnv(
 	clusterConfig *hdfsv1alpha1.ClusterConfigSpec,
 	container constant.ContainerComponent,
 	role *constant.Role,
+	metrics *hdfsv1alpha1.MetricsSpec,
 ) []corev1.EnvVar {
 	envs := []corev1.EnvVar{
 		{
@@ -174,16 +175,16 @@ func GetCommonContainerEnv(
 	if IsKerberosEnabled(clusterConfig) {
 		envs = append(envs, SecurityEnvs(container, &jvmArgs)...)
 	}
+	if IsTlsEnabled(clusterConfig) {
+		envs = append(envs, JksPasswordEnvVar(clusterConfig.Authentication.Tls))
+	}
 	if envName = getEnvNameByContainerComponent(container); envName != "" {
 		jvmArgs = append(jvmArgs, "-Xmx419430k")
 		securityDir := getSubDirByContainerComponent(container)
 
 		securityConfigEnValue := fmt.Sprintf("-Djava.security.properties=%s", path.Join(constants.KubedoopConfigDir, securityDir, "security.properties"))
-		if role != nil {
-			if metricPort, err := GetMetricsPort(*role); err == nil {
-				jarPath := path.Join(constants.KubedoopJmxDir, "jmx_prometheus_javaagent.jar")
-				configPath := path.Join(constants.KubedoopJmxDir, fmt.Sprintf("%s.yaml", strings.ToLower(string(*role))))
-				javaAgentArg := fmt.Sprintf("-javaagent:%s=%d:%s", jarPath, metricPort, configPath)
+		if role != nil && IsJmxExporterEnabled(metrics) {
+			if javaAgentArg, err := jmxExporterJvmArg(*role, metrics); err == nil {
 				jvmArgs = append(jvmArgs, javaAgentArg)
 			} else {
 				fmt.Printf("GetMetricsPort error for role %v: %v. Cannot configure JMX agent.\n", role, err)
@@ -201,8 +202,21 @@ func GetCommonContainerEnv(
 	return envs
 }
 
-func GetCommonVolumes(clusterConfig *hdfsv1alpha1.ClusterConfigSpec, instanceName string, roleGroupInfo *reconciler.RoleGroupInfo) []corev1.Volume {
+// GetCommonVolumes returns the volumes shared by all roles, the log volume is extended by the audit log if it is set
+func GetCommonVolumes(
+	clusterConfig *hdfsv1alpha1.ClusterConfigSpec,
+	instanceName string,
+	roleGroupInfo *reconciler.RoleGroupInfo,
+	auditLog *hdfsv1alpha1.AuditLogSpec,
+) ([]corev1.Volume, error) {
 	limit := resource.MustParse("150Mi")
+	if auditLog != nil {
+		auditLogLimit, err := AuditLogSizeLimit(auditLog)
+		if err != nil {
+			return nil, err
+		}
+		limit.Add(auditLogLimit)
+	}
 	volumes := []corev1.Volume{
 		{
 			Name: hdfsv1alpha1.KubedoopLogVolumeMountName,
@@ -214,17 +228,13 @@ func GetCommonVolumes(clusterConfig *hdfsv1alpha1.ClusterConfigSpec, instanceNam
 		},
 	}
 	if IsKerberosEnabled(clusterConfig) {
-		secretClass := clusterConfig.Authentication.Kerberos.SecretClass
 		role := constant.Role(roleGroupInfo.GetRoleName())
-		volumes = append(volumes, CreateKerberosSecretPvc(secretClass, instanceName, role))
+		volumes = append(volumes, CreateKerberosSecretPvc(clusterConfig.Authentication.Kerberos, instanceName, role))
 	}
 	if IsTlsEnabled(clusterConfig) {
-		tlsSecretClass := clusterConfig.Authentication.Tls.SecretClass
-		volumes = append(volumes, CreateTlsSecretPvc(tlsSecretClass, clusterConfig.Authentication.Tls.JksPassword, roleGroupInfo))
-		volumes = append(volumes, CreateTlsSecretPvc(tlsSecretClass, clusterConfig.Authentication.Tls.JksPassword, roleGroupInfo))
+		volumes = append(volumes, CreateTlsSecretPvc(clusterConfig.Authentication.Tls, roleGroupInfo))
 	}
-	return volumes
-
+	return volumes, nil
 }
 
 // GetMetricsPort returns the metrics port for the specified HDFS role.
@@ -10,6 +10,7 @@ import (
 	"github.com/zncdatadev/operator-go/pkg/util"
 
 	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -22,7 +23,8 @@ func IsVectorEnable(roleLoggingConfig *commonsv1alpha1.LoggingSpec) (bool, error
 		return false, nil
 	}
 
-	return *roleLoggingConfig.EnableVectorAgent, nil
+	// the vector agent is disabled unless it is enabled explicitly
+	return ptr.Deref(roleLoggingConfig.EnableVectorAgent, false), nil
 }
 
 type VectorConfigParams struct {
@@ -27,6 +27,7 @@ const (
 	FormatZookeeperContainer  = "format-zookeeper"
 	WaitForNameNodesContainer = "wait-for-namenodes"
 	OidcContainer             = "oidc"
+	DiskBalancerContainer     = "disk-balancer"
 	// const ContainerVector ContainerComponent = "vector"
 	VectorContainer = "vector"
 )
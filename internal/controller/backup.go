@@ -0,0 +1,343 @@
+package controller
+
+import (
+	"context"
+	"maps"
+	"path"
+	"sort"
+	"strings"
+
+	"emperror.dev/errors"
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/common"
+	"github.com/zncdatadev/hdfs-operator/internal/constant"
+	authv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/authentication/v1alpha1"
+	"github.com/zncdatadev/operator-go/pkg/builder"
+	pkgclient "github.com/zncdatadev/operator-go/pkg/client"
+	"github.com/zncdatadev/operator-go/pkg/constants"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	"github.com/zncdatadev/operator-go/pkg/util"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	backupConfigVolumeName = "backup-config"
+	backupWorkVolumeName   = "backup-work"
+	backupWorkDir          = constants.KubedoopRoot + "backup-work"
+)
+
+func NewBackupReconciler(
+	client *pkgclient.Client,
+	instance *hdfsv1alpha1.HdfsCluster,
+	clusterInfo reconciler.ClusterInfo,
+	image *util.Image,
+) reconciler.ResourceReconciler[builder.ObjectBuilder] {
+	backupBuilder := NewBackupCronJobBuilder(client, instance, clusterInfo, image)
+	return reconciler.NewGenericResourceReconciler[builder.ObjectBuilder](client, backupBuilder)
+}
+
+var _ builder.ObjectBuilder = &BackupCronJobBuilder{}
+
+// BackupCronJobBuilder builds the CronJob which fetches the latest fsimage from the active namenode
+// and stores it in the backup location.
+type BackupCronJobBuilder struct {
+	*builder.ObjectMeta
+	instance    *hdfsv1alpha1.HdfsCluster
+	clusterInfo reconciler.ClusterInfo
+	image       *util.Image
+}
+
+func NewBackupCronJobBuilder(
+	client *pkgclient.Client,
+	instance *hdfsv1alpha1.HdfsCluster,
+	clusterInfo reconciler.ClusterInfo,
+	image *util.Image,
+) *BackupCronJobBuilder {
+	return &BackupCronJobBuilder{
+		ObjectMeta: builder.NewObjectMeta(
+			client,
+			instance.Name+"-backup",
+			func(o *builder.Options) {
+				o.ClusterName = clusterInfo.ClusterName
+				o.Labels = clusterInfo.GetLabels()
+				o.Annotations = clusterInfo.GetAnnotations()
+			},
+		),
+		instance:    instance,
+		clusterInfo: clusterInfo,
+		image:       image,
+	}
+}
+
+func (b *BackupCronJobBuilder) Build(ctx context.Context) (ctrlclient.Object, error) {
+	clusterConfig := b.instance.Spec.ClusterConfig
+	backup := clusterConfig.Backup
+	if (backup.S3 == nil) == (backup.Pvc == nil) {
+		return nil, errors.New("exactly one of s3 or pvc must be set in the backup")
+	}
+
+	roleGroupInfo, err := firstNameNodeRoleGroupInfo(b.instance, b.clusterInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	var s3Target *common.S3Target
+	if backup.S3 != nil {
+		var err error
+		if s3Target, err = common.ResolveS3Target(ctx, b.Client, b.instance.Namespace, backup.S3.Bucket, backup.S3.Prefix); err != nil {
+			return nil, err
+		}
+	}
+
+	ldapProvider, err := common.ResolveLdapGroupMapping(ctx, b.Client, clusterConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := b.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[common.LabelComponent] = "backup"
+
+	var pullSecrets []corev1.LocalObjectReference
+	if b.image.PullSecretName != "" {
+		pullSecrets = []corev1.LocalObjectReference{{Name: b.image.PullSecretName}}
+	}
+
+	return &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        b.GetName(),
+			Namespace:   b.instance.Namespace,
+			Labels:      labels,
+			Annotations: b.GetAnnotations(),
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule:                   backup.Schedule,
+			Suspend:                    ptr.To(backup.Suspend),
+			ConcurrencyPolicy:          batchv1.ForbidConcurrent,
+			SuccessfulJobsHistoryLimit: ptr.To[int32](3),
+			FailedJobsHistoryLimit:     ptr.To[int32](3),
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: batchv1.JobSpec{
+					BackoffLimit: ptr.To[int32](1),
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: labels},
+						Spec: corev1.PodSpec{
+							RestartPolicy:      corev1.RestartPolicyNever,
+							ServiceAccountName: common.CreateServiceAccountName(b.instance.Name),
+							ImagePullSecrets:   pullSecrets,
+							SecurityContext:    &corev1.PodSecurityContext{FSGroup: ptr.To[int64](1000)},
+							Containers:         []corev1.Container{b.buildContainer(s3Target, ldapProvider)},
+							Volumes:            b.volumes(roleGroupInfo, s3Target, ldapProvider),
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// firstNameNodeRoleGroupInfo returns the first namenode role group, its config map
+// contains the client configuration including tls and kerberos settings.
+func firstNameNodeRoleGroupInfo(instance *hdfsv1alpha1.HdfsCluster, clusterInfo reconciler.ClusterInfo) (*reconciler.RoleGroupInfo, error) {
+	if instance.Spec.NameNode == nil || len(instance.Spec.NameNode.RoleGroups) == 0 {
+		return nil, errors.New("the namenode role has no role groups")
+	}
+	groups := make([]string, 0, len(instance.Spec.NameNode.RoleGroups))
+	for groupName := range instance.Spec.NameNode.RoleGroups {
+		groups = append(groups, groupName)
+	}
+	sort.Strings(groups)
+	return &reconciler.RoleGroupInfo{
+		RoleInfo: reconciler.RoleInfo{
+			ClusterInfo: clusterInfo,
+			RoleName:    string(constant.NameNode),
+		},
+		RoleGroupName: groups[0],
+	}, nil
+}
+
+func (b *BackupCronJobBuilder) buildContainer(s3Target *common.S3Target, ldapProvider *authv1alpha1.LDAPProvider) corev1.Container {
+	clusterConfig := b.instance.Spec.ClusterConfig
+	envs := []corev1.EnvVar{
+		{Name: "HADOOP_CONF_DIR", Value: path.Join(constants.KubedoopConfigDir, "backup")},
+		{Name: "HADOOP_HOME", Value: hdfsv1alpha1.HadoopHome},
+	}
+	envs = append(envs, common.BackupEnvVars(clusterConfig)...)
+	mounts := []corev1.VolumeMount{
+		{Name: backupConfigVolumeName, MountPath: path.Join(constants.KubedoopConfigDirMount, "backup")},
+		{Name: backupWorkVolumeName, MountPath: backupWorkDir},
+	}
+	mounts = append(mounts, common.BackupVolumeMounts(clusterConfig, s3Target, false)...)
+	if common.IsKerberosEnabled(clusterConfig) {
+		var jvmArgs []string
+		envs = append(envs, common.SecurityEnvs(constant.NameNodeComponent, &jvmArgs)...)
+		mounts = append(mounts, common.SecurityVolumeMounts()...)
+	}
+	if common.IsTlsEnabled(clusterConfig) {
+		envs = append(envs, common.JksPasswordEnvVar(clusterConfig.Authentication.Tls))
+		mounts = append(mounts, common.TlsVolumeMounts()...)
+	}
+	mounts = append(mounts, common.LdapBindCredentialsVolumeMounts(ldapProvider)...)
+
+	return corev1.Container{
+		Name:            "backup",
+		Image:           b.image.String(),
+		ImagePullPolicy: b.image.GetPullPolicy(),
+		Command:         []string{"/bin/bash", "-x", "-euo", "pipefail", "-c"},
+		Args:            b.args(s3Target),
+		Env:             envs,
+		VolumeMounts:    mounts,
+	}
+}
+
+func (b *BackupCronJobBuilder) volumes(
+	roleGroupInfo *reconciler.RoleGroupInfo,
+	s3Target *common.S3Target,
+	ldapProvider *authv1alpha1.LDAPProvider,
+) []corev1.Volume {
+	clusterConfig := b.instance.Spec.ClusterConfig
+	workDirLimit := resource.MustParse("10Gi")
+	volumes := []corev1.Volume{
+		{
+			Name: backupConfigVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: roleGroupInfo.GetFullName()},
+				},
+			},
+		},
+		{
+			Name: backupWorkVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: &workDirLimit},
+			},
+		},
+	}
+	volumes = append(volumes, common.BackupVolumes(clusterConfig, s3Target, false)...)
+	if common.IsKerberosEnabled(clusterConfig) {
+		volumes = append(volumes, common.CreateKerberosSecretPvc(clusterConfig.Authentication.Kerberos, b.instance.Name, constant.NameNode))
+	}
+	if common.IsTlsEnabled(clusterConfig) {
+		volumes = append(volumes, common.CreateTlsSecretPvc(clusterConfig.Authentication.Tls, roleGroupInfo))
+	}
+	volumes = append(volumes, common.LdapBindCredentialsVolumes(ldapProvider)...)
+	return volumes
+}
+
+const backupScriptTemplate = `mkdir -p {{ .configDir }}
+cp {{ .mountConfigDir }}/*.xml {{ .configDir }}
+{{ .substituteJksPassword }}
+{{ if .kerberosEnabled }}
+{{- .kerberosEnv }}
+
+{{- .kinitScript }}
+
+{{- end }}
+
+BACKUP_NAME=$(date -u +%Y%m%d-%H%M%S)
+WORK_DIR={{ .workDir }}/$BACKUP_NAME
+mkdir -p "$WORK_DIR"
+
+echo "Fetching latest fsimage from the active namenode"
+/kubedoop/hadoop/bin/hdfs dfsadmin -fetchImage "$WORK_DIR"
+
+for namenode_id in {{ .nameNodeIds }}
+do
+    SERVICE_STATE=$(/kubedoop/hadoop/bin/hdfs haadmin -getServiceState $namenode_id | tail -n1 || true)
+    if [ "$SERVICE_STATE" == "active" ]
+    then
+        ACTIVE_NAMENODE=$namenode_id
+        break
+    fi
+done
+
+# the cluster id and block pool id must be kept when restoring, otherwise datanodes are rejected
+if [ -n "${ACTIVE_NAMENODE:-}" ]
+then
+    HTTP_ADDRESS=$(/kubedoop/hadoop/bin/hdfs getconf -confKey dfs.namenode.{{ .httpScheme }}-address.{{ .instanceName }}.$ACTIVE_NAMENODE)
+    NAMENODE_INFO=$(curl -sSf {{ .curlOptions }} "{{ .httpScheme }}://$HTTP_ADDRESS/jmx?qry=Hadoop:service=NameNode,name=NameNodeInfo" || true)
+    CLUSTER_ID=$(echo "$NAMENODE_INFO" | grep -oP '"ClusterId"\s*:\s*"\K[^"]+' || true)
+    BLOCK_POOL_ID=$(echo "$NAMENODE_INFO" | grep -oP '"BlockPoolId"\s*:\s*"\K[^"]+' || true)
+fi
+if [ -z "${CLUSTER_ID:-}" ]
+then
+    echo "WARN: could not read the cluster id, set clusterConfig.backup.restore.clusterId when restoring this backup"
+fi
+cat > "$WORK_DIR/{{ .clusterPropertiesFile }}" <<EOF
+CLUSTER_ID=${CLUSTER_ID:-}
+BLOCK_POOL_ID=${BLOCK_POOL_ID:-}
+EOF
+
+{{- if .s3 }}
+{{ .exportS3Credentials }}
+/kubedoop/hadoop/bin/hdfs dfs {{ .s3Options }} -mkdir -p "{{ .s3Uri }}"
+/kubedoop/hadoop/bin/hdfs dfs {{ .s3Options }} -put "$WORK_DIR" "{{ .s3Uri }}/"
+echo "Uploaded backup $BACKUP_NAME to {{ .s3Uri }}"
+
+/kubedoop/hadoop/bin/hdfs dfs {{ .s3Options }} -ls -C "{{ .s3Uri }}/" | sort | head -n -{{ .retention }} | while read -r expired
+do
+    echo "Removing expired backup $expired"
+    /kubedoop/hadoop/bin/hdfs dfs {{ .s3Options }} -rm -r "$expired"
+done
+{{- else }}
+cp -r "$WORK_DIR" "{{ .backupDir }}/"
+echo "Stored backup $BACKUP_NAME in {{ .backupDir }}"
+
+ls -1 "{{ .backupDir }}" | sort | head -n -{{ .retention }} | while read -r expired
+do
+    echo "Removing expired backup $expired"
+    rm -rf "{{ .backupDir }}/$expired"
+done
+{{- end }}
+`
+
+func (b *BackupCronJobBuilder) args(s3Target *common.S3Target) []string {
+	clusterConfig := b.instance.Spec.ClusterConfig
+	backup := clusterConfig.Backup
+
+	httpScheme := "http"
+	var curlOptions []string
+	if common.IsTlsEnabled(clusterConfig) {
+		// only public ids are read from the jmx endpoint
+		httpScheme = "https"
+		curlOptions = append(curlOptions, "--insecure")
+	}
+	if common.IsKerberosEnabled(clusterConfig) {
+		curlOptions = append(curlOptions, "--negotiate", "-u", ":")
+	}
+
+	data := common.CreateExportKrbRealmEnvData(clusterConfig)
+	principal := common.CreateKerberosPrincipal(b.instance.Spec.ClusterConfig, b.instance.Name, b.instance.Namespace, constant.NameNode)
+	maps.Copy(data, common.CreateGetKerberosTicketData(principal))
+	maps.Copy(data, common.CreateJksPasswordData(clusterConfig))
+	maps.Copy(data, map[string]interface{}{
+		"configDir":             path.Join(constants.KubedoopConfigDir, "backup"),
+		"mountConfigDir":        path.Join(constants.KubedoopConfigDirMount, "backup"),
+		"workDir":               backupWorkDir,
+		"nameNodeIds":           strings.Join(common.GetNameNodePodNames(b.instance, b.clusterInfo), " "),
+		"instanceName":          b.instance.Name,
+		"httpScheme":            httpScheme,
+		"curlOptions":           strings.Join(curlOptions, " "),
+		"clusterPropertiesFile": common.BackupClusterPropertiesFileName,
+		"retention":             valueOrDefault(backup.Retention, 7),
+		"backupDir":             common.BackupDir,
+		"s3":                    s3Target != nil,
+	})
+	if s3Target != nil {
+		data["s3Uri"] = s3Target.Uri()
+		data["s3Options"] = s3Target.HadoopOptions()
+		data["exportS3Credentials"] = ""
+		if s3Target.Credentials != nil {
+			data["exportS3Credentials"] = common.ExportS3CredentialsScript(common.S3CredentialsDir)
+		}
+	}
+	return common.ParseTemplate(backupScriptTemplate, data)
+}
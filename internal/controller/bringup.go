@@ -0,0 +1,277 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/constant"
+	pkgclient "github.com/zncdatadev/operator-go/pkg/client"
+	opconstants "github.com/zncdatadev/operator-go/pkg/constants"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var bringUpLog = ctrl.Log.WithName("bring-up")
+
+const (
+	bringUpMinRequeueAfter = 5 * time.Second
+	bringUpMaxRequeueAfter = time.Minute
+)
+
+// bringUpJmx reads the HA state of the namenodes, it is shared by the gates of all clusters
+var bringUpJmx = NewJmxClient()
+
+var _ reconciler.Reconciler = &BringUpGate{}
+
+// BringUpGate orders the initial start of the roles. It is registered after the resources of a role and
+// holds back the resources registered after it until the role is up, by requeueing the reconcile. The
+// requeue backs off with the time the phase has been waiting. Once all roles were up, the bring-up is
+// completed and the gates do not hold back the roles anymore.
+type BringUpGate struct {
+	client   *pkgclient.Client
+	instance *hdfsv1alpha1.HdfsCluster
+	// phase is the phase of the role the gate waits for
+	phase hdfsv1alpha1.BringUpPhase
+	next  hdfsv1alpha1.BringUpPhase
+}
+
+func NewBringUpGate(
+	client *pkgclient.Client,
+	instance *hdfsv1alpha1.HdfsCluster,
+	phase hdfsv1alpha1.BringUpPhase,
+	next hdfsv1alpha1.BringUpPhase,
+) *BringUpGate {
+	return &BringUpGate{
+		client:   client,
+		instance: instance,
+		phase:    phase,
+		next:     next,
+	}
+}
+
+func (g *BringUpGate) GetName() string {
+	return g.instance.Name + "-bring-up-" + strings.ToLower(string(g.phase))
+}
+
+func (g *BringUpGate) GetNamespace() string {
+	return g.instance.Namespace
+}
+
+func (g *BringUpGate) GetClient() *pkgclient.Client {
+	return g.client
+}
+
+// Reconcile requeues until the role of the gate is up, then it moves the bring-up to the next phase
+func (g *BringUpGate) Reconcile(ctx context.Context) (ctrl.Result, error) {
+	if g.instance.Spec.ClusterOperationSpec != nil && g.instance.Spec.ClusterOperationSpec.Stopped {
+		return ctrl.Result{}, nil
+	}
+	completed, err := g.completed(ctx)
+	if err != nil || completed {
+		return ctrl.Result{}, err
+	}
+
+	up, message, err := g.roleUp(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !up {
+		if err := g.setPhase(ctx, g.phase, message); err != nil {
+			return ctrl.Result{}, err
+		}
+		requeueAfter := g.requeueAfter()
+		bringUpLog.Info("Waiting for role", "cluster", g.instance.Name, "phase", g.phase, "message", message,
+			"requeueAfter", requeueAfter)
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+
+	// a later gate may have advanced the phase already
+	if current := g.instance.Status.BringUp; current == nil || current.Phase == g.phase {
+		bringUpLog.Info("Role is up", "cluster", g.instance.Name, "phase", g.phase, "next", g.next)
+		return ctrl.Result{}, g.setPhase(ctx, g.next, "")
+	}
+	return ctrl.Result{}, nil
+}
+
+func (g *BringUpGate) Ready(ctx context.Context) (ctrl.Result, error) {
+	return ctrl.Result{}, nil
+}
+
+// completed reports whether the bring-up was completed. Clusters created before the bring-up was
+// recorded have datanodes already, their bring-up is recorded as completed.
+func (g *BringUpGate) completed(ctx context.Context) (bool, error) {
+	if current := g.instance.Status.BringUp; current != nil {
+		return current.Phase == hdfsv1alpha1.BringUpPhaseCompleted, nil
+	}
+	statefulSets, err := g.statefulSets(ctx, constant.DataNode)
+	if err != nil || len(statefulSets) == 0 {
+		return false, err
+	}
+	return true, g.setPhase(ctx, hdfsv1alpha1.BringUpPhaseCompleted, "")
+}
+
+// roleUp reports whether the role of the phase is up, otherwise the message describes what is missing
+func (g *BringUpGate) roleUp(ctx context.Context) (bool, string, error) {
+	switch g.phase {
+	case hdfsv1alpha1.BringUpPhaseJournalNodes:
+		return g.journalNodesStarted(ctx)
+	case hdfsv1alpha1.BringUpPhaseNameNodes:
+		if g.instance.Spec.NameNode == nil {
+			return true, "", nil
+		}
+		if ready, message, err := g.statefulSetsReady(ctx, constant.NameNode, &g.instance.Spec.NameNode.RoleSpec); err != nil || !ready {
+			return ready, message, err
+		}
+		return g.activeNameNode(ctx)
+	case hdfsv1alpha1.BringUpPhaseDataNodes:
+		if g.instance.Spec.DataNode == nil {
+			return true, "", nil
+		}
+		return g.statefulSetsReady(ctx, constant.DataNode, &g.instance.Spec.DataNode.RoleSpec)
+	default:
+		return true, "", nil
+	}
+}
+
+// statefulSetsReady reports whether the StatefulSets of all role groups of the role have all replicas ready
+func (g *BringUpGate) statefulSetsReady(ctx context.Context, role constant.Role, spec *hdfsv1alpha1.RoleSpec) (bool, string, error) {
+	if spec == nil {
+		return true, "", nil
+	}
+	statefulSets, err := g.statefulSets(ctx, role)
+	if err != nil {
+		return false, "", err
+	}
+	if len(statefulSets) < len(spec.RoleGroups) {
+		return false, fmt.Sprintf("%d of %d %s role groups created", len(statefulSets), len(spec.RoleGroups), role), nil
+	}
+
+	var notReady []string
+	for _, statefulSet := range statefulSets {
+		replicas := ptr.Deref(statefulSet.Spec.Replicas, 1)
+		if statefulSet.Status.ObservedGeneration < statefulSet.Generation || statefulSet.Status.ReadyReplicas < replicas {
+			notReady = append(notReady, fmt.Sprintf("%s %d/%d ready", statefulSet.Name, statefulSet.Status.ReadyReplicas, replicas))
+		}
+	}
+	if len(notReady) > 0 {
+		slices.Sort(notReady)
+		return false, strings.Join(notReady, ", "), nil
+	}
+	return true, "", nil
+}
+
+// journalNodesStarted reports whether all journalnodes are started. A journalnode is ready once a namenode
+// wrote to its journal, so the namenodes are created before the journalnodes are ready.
+func (g *BringUpGate) journalNodesStarted(ctx context.Context) (bool, string, error) {
+	spec := g.instance.Spec.JournalNode
+	if spec == nil {
+		return true, "", nil
+	}
+	statefulSets, err := g.statefulSets(ctx, constant.JournalNode)
+	if err != nil {
+		return false, "", err
+	}
+	if len(statefulSets) < len(spec.RoleGroups) {
+		return false, fmt.Sprintf("%d of %d %s role groups created", len(statefulSets), len(spec.RoleGroups), constant.JournalNode), nil
+	}
+	pods, err := rolePods(ctx, g.client.Client, g.instance, constant.JournalNode)
+	if err != nil {
+		return false, "", err
+	}
+
+	var notStarted []string
+	for _, statefulSet := range statefulSets {
+		replicas := ptr.Deref(statefulSet.Spec.Replicas, 1)
+		started := int32(0)
+		for i := range pods {
+			if metav1.IsControlledBy(&pods[i], &statefulSet) && containerStarted(&pods[i], constant.JournalNodeContainer) {
+				started++
+			}
+		}
+		if started < replicas {
+			notStarted = append(notStarted, fmt.Sprintf("%s %d/%d started", statefulSet.Name, started, replicas))
+		}
+	}
+	if len(notStarted) > 0 {
+		slices.Sort(notStarted)
+		return false, strings.Join(notStarted, ", "), nil
+	}
+	return true, "", nil
+}
+
+// activeNameNode reports whether a namenode is active. The readiness probe of the namenodes
+// only checks they are in a HA state, which is standby until ZKFC elects the active namenode.
+func (g *BringUpGate) activeNameNode(ctx context.Context) (bool, string, error) {
+	pods, err := rolePods(ctx, g.client.Client, g.instance, constant.NameNode)
+	if err != nil {
+		return false, "", err
+	}
+	for i := range pods {
+		namesystem := &FSNamesystem{}
+		if err := bringUpJmx.NameNodeBean(ctx, g.instance.Spec.ClusterConfig, &pods[i], fsNamesystemBean, namesystem); err != nil {
+			bringUpLog.V(1).Info("failed to read namenode state", "pod", pods[i].Name, "reason", err.Error())
+			continue
+		}
+		if namesystem.HAState == "active" {
+			return true, "", nil
+		}
+	}
+	return false, "no active namenode", nil
+}
+
+func (g *BringUpGate) statefulSets(ctx context.Context, role constant.Role) ([]appsv1.StatefulSet, error) {
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := g.client.Client.List(ctx, statefulSets,
+		ctrlclient.InNamespace(g.instance.Namespace),
+		ctrlclient.MatchingLabels{
+			opconstants.LabelKubernetesInstance:  g.instance.Name,
+			opconstants.LabelKubernetesComponent: string(role),
+		},
+	); err != nil {
+		return nil, err
+	}
+	return statefulSets.Items, nil
+}
+
+// requeueAfter backs off with a quarter of the time the phase has been waiting
+func (g *BringUpGate) requeueAfter() time.Duration {
+	current := g.instance.Status.BringUp
+	if current == nil || current.LastTransitionTime == nil {
+		return bringUpMinRequeueAfter
+	}
+	return min(max(time.Since(current.LastTransitionTime.Time)/4, bringUpMinRequeueAfter), bringUpMaxRequeueAfter)
+}
+
+func (g *BringUpGate) setPhase(ctx context.Context, phase hdfsv1alpha1.BringUpPhase, message string) error {
+	if !setBringUpPhase(g.instance, phase, message) {
+		return nil
+	}
+	return g.client.Client.Status().Update(ctx, g.instance)
+}
+
+// setBringUpPhase records the phase of the bring-up in the status of the instance and reports whether the status
+// changed. The transition time is kept while the phase does not change.
+func setBringUpPhase(instance *hdfsv1alpha1.HdfsCluster, phase hdfsv1alpha1.BringUpPhase, message string) bool {
+	current := instance.Status.BringUp
+	if current != nil && current.Phase == phase && current.Message == message {
+		return false
+	}
+	transitionTime := metav1.Now()
+	if current != nil && current.Phase == phase && current.LastTransitionTime != nil {
+		transitionTime = *current.LastTransitionTime
+	}
+	instance.Status.BringUp = &hdfsv1alpha1.BringUpStatus{
+		Phase:              phase,
+		Message:            message,
+		LastTransitionTime: &transitionTime,
+	}
+	return true
+}
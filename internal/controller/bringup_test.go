@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/constant"
+	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// startedJournalNode returns a journalnode pod of the StatefulSet with the started container
+func startedJournalNode(statefulSet *appsv1.StatefulSet, index int, started bool) *corev1.Pod {
+	pod := testPod(constant.JournalNode, index, time.Now())
+	pod.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(statefulSet, appsv1.SchemeGroupVersion.WithKind("StatefulSet"))}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+		{Name: constant.JournalNodeContainer, Started: ptr.To(started)},
+	}
+	return pod
+}
+
+func TestBringUpGate(t *testing.T) {
+	journalNodes := testStatefulSet(constant.JournalNode, 2, 0)
+
+	tests := []struct {
+		name    string
+		stopped bool
+		status  *hdfsv1alpha1.BringUpStatus
+		phase   hdfsv1alpha1.BringUpPhase
+		next    hdfsv1alpha1.BringUpPhase
+		objects []ctrlclient.Object
+		// states are the HA states of the namenode pods
+		states      map[string]string
+		wantPhase   hdfsv1alpha1.BringUpPhase
+		wantMessage string
+		wantRequeue bool
+	}{
+		{
+			name:      "pre-existing cluster",
+			phase:     hdfsv1alpha1.BringUpPhaseJournalNodes,
+			next:      hdfsv1alpha1.BringUpPhaseNameNodes,
+			objects:   []ctrlclient.Object{testStatefulSet(constant.DataNode, 1, 1)},
+			wantPhase: hdfsv1alpha1.BringUpPhaseCompleted,
+		},
+		{
+			name:   "completed",
+			status: &hdfsv1alpha1.BringUpStatus{Phase: hdfsv1alpha1.BringUpPhaseCompleted},
+			phase:  hdfsv1alpha1.BringUpPhaseNameNodes,
+			next:   hdfsv1alpha1.BringUpPhaseDataNodes,
+			// namenodes which are not ready do not hold back a completed bring-up
+			objects:   []ctrlclient.Object{testStatefulSet(constant.NameNode, 2, 0)},
+			wantPhase: hdfsv1alpha1.BringUpPhaseCompleted,
+		},
+		{
+			name:      "stopped",
+			stopped:   true,
+			phase:     hdfsv1alpha1.BringUpPhaseJournalNodes,
+			next:      hdfsv1alpha1.BringUpPhaseNameNodes,
+			wantPhase: "",
+		},
+		{
+			name:        "new cluster without journalnodes",
+			phase:       hdfsv1alpha1.BringUpPhaseJournalNodes,
+			next:        hdfsv1alpha1.BringUpPhaseNameNodes,
+			wantPhase:   hdfsv1alpha1.BringUpPhaseJournalNodes,
+			wantMessage: "0 of 1 journalnode role groups created",
+			wantRequeue: true,
+		},
+		{
+			name:  "journalnodes starting",
+			phase: hdfsv1alpha1.BringUpPhaseJournalNodes,
+			next:  hdfsv1alpha1.BringUpPhaseNameNodes,
+			objects: []ctrlclient.Object{
+				journalNodes,
+				startedJournalNode(journalNodes, 0, true),
+				startedJournalNode(journalNodes, 1, false),
+			},
+			wantPhase:   hdfsv1alpha1.BringUpPhaseJournalNodes,
+			wantMessage: "hdfs-journalnode-default 1/2 started",
+			wantRequeue: true,
+		},
+		{
+			name:  "journalnodes started",
+			phase: hdfsv1alpha1.BringUpPhaseJournalNodes,
+			next:  hdfsv1alpha1.BringUpPhaseNameNodes,
+			objects: []ctrlclient.Object{
+				journalNodes,
+				startedJournalNode(journalNodes, 0, true),
+				startedJournalNode(journalNodes, 1, true),
+			},
+			wantPhase: hdfsv1alpha1.BringUpPhaseNameNodes,
+		},
+		{
+			name:        "namenodes not ready",
+			status:      &hdfsv1alpha1.BringUpStatus{Phase: hdfsv1alpha1.BringUpPhaseNameNodes},
+			phase:       hdfsv1alpha1.BringUpPhaseNameNodes,
+			next:        hdfsv1alpha1.BringUpPhaseDataNodes,
+			objects:     []ctrlclient.Object{testStatefulSet(constant.NameNode, 2, 1)},
+			wantPhase:   hdfsv1alpha1.BringUpPhaseNameNodes,
+			wantMessage: "hdfs-namenode-default 1/2 ready",
+			wantRequeue: true,
+		},
+		{
+			name:   "namenodes ready without an active namenode",
+			status: &hdfsv1alpha1.BringUpStatus{Phase: hdfsv1alpha1.BringUpPhaseNameNodes},
+			phase:  hdfsv1alpha1.BringUpPhaseNameNodes,
+			next:   hdfsv1alpha1.BringUpPhaseDataNodes,
+			objects: []ctrlclient.Object{
+				testStatefulSet(constant.NameNode, 2, 2),
+				testPod(constant.NameNode, 0, time.Now()),
+				testPod(constant.NameNode, 1, time.Now()),
+			},
+			states:      map[string]string{"hdfs-namenode-default-0": "standby", "hdfs-namenode-default-1": "standby"},
+			wantPhase:   hdfsv1alpha1.BringUpPhaseNameNodes,
+			wantMessage: "no active namenode",
+			wantRequeue: true,
+		},
+		{
+			name:   "namenodes ready with an active namenode",
+			status: &hdfsv1alpha1.BringUpStatus{Phase: hdfsv1alpha1.BringUpPhaseNameNodes},
+			phase:  hdfsv1alpha1.BringUpPhaseNameNodes,
+			next:   hdfsv1alpha1.BringUpPhaseDataNodes,
+			objects: []ctrlclient.Object{
+				testStatefulSet(constant.NameNode, 2, 2),
+				testPod(constant.NameNode, 0, time.Now()),
+				testPod(constant.NameNode, 1, time.Now()),
+			},
+			// the standby namenode is not reachable
+			states:    map[string]string{"hdfs-namenode-default-1": "active"},
+			wantPhase: hdfsv1alpha1.BringUpPhaseDataNodes,
+		},
+		{
+			name:   "phase advanced by a later gate",
+			status: &hdfsv1alpha1.BringUpStatus{Phase: hdfsv1alpha1.BringUpPhaseDataNodes},
+			phase:  hdfsv1alpha1.BringUpPhaseJournalNodes,
+			next:   hdfsv1alpha1.BringUpPhaseNameNodes,
+			objects: []ctrlclient.Object{
+				journalNodes,
+				startedJournalNode(journalNodes, 0, true),
+				startedJournalNode(journalNodes, 1, true),
+			},
+			wantPhase: hdfsv1alpha1.BringUpPhaseDataNodes,
+		},
+		{
+			name:      "datanodes ready",
+			status:    &hdfsv1alpha1.BringUpStatus{Phase: hdfsv1alpha1.BringUpPhaseDataNodes},
+			phase:     hdfsv1alpha1.BringUpPhaseDataNodes,
+			next:      hdfsv1alpha1.BringUpPhaseCompleted,
+			objects:   []ctrlclient.Object{testStatefulSet(constant.DataNode, 1, 1)},
+			wantPhase: hdfsv1alpha1.BringUpPhaseCompleted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := newTestCluster(2, 1, 2)
+			instance.Status.BringUp = tt.status
+			if tt.stopped {
+				instance.Spec.ClusterOperationSpec = &commonsv1alpha1.ClusterOperationSpec{Stopped: true}
+			}
+			client := newFakeClient(t, instance, tt.objects...)
+			setFakeJmx(t, &bringUpJmx, tt.states)
+
+			result, err := NewBringUpGate(client, instance, tt.phase, tt.next).Reconcile(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if requeue := result.RequeueAfter > 0; requeue != tt.wantRequeue {
+				t.Errorf("requeue after %v, want requeue %v", result.RequeueAfter, tt.wantRequeue)
+			}
+
+			var phase hdfsv1alpha1.BringUpPhase
+			var message string
+			if current := getCluster(t, client).Status.BringUp; current != nil {
+				phase, message = current.Phase, current.Message
+			}
+			if phase != tt.wantPhase || message != tt.wantMessage {
+				t.Errorf("got phase %q with message %q, want %q with %q", phase, message, tt.wantPhase, tt.wantMessage)
+			}
+		})
+	}
+}
@@ -9,7 +9,6 @@ import (
 	"github.com/zncdatadev/hdfs-operator/internal/controller/data"
 	"github.com/zncdatadev/hdfs-operator/internal/controller/journal"
 	"github.com/zncdatadev/hdfs-operator/internal/controller/name"
-	"github.com/zncdatadev/hdfs-operator/internal/util/version"
 	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
 	"github.com/zncdatadev/operator-go/pkg/builder"
 	resourceClient "github.com/zncdatadev/operator-go/pkg/client"
@@ -28,6 +27,7 @@ type Reconciler struct {
 	ClusterOperation *commonsv1alpha1.ClusterOperationSpec
 
 	instance *hdfsv1alpha1.HdfsCluster
+	recorder *EventRecorder
 }
 
 // NewClusterReconciler creates a new cluster reconciler for HdfsCluster resources
@@ -35,6 +35,7 @@ func NewClusterReconciler(
 	client *resourceClient.Client,
 	clusterInfo reconciler.ClusterInfo,
 	instance *hdfsv1alpha1.HdfsCluster,
+	recorder *EventRecorder,
 ) *Reconciler {
 	spec := &instance.Spec
 	return &Reconciler{
@@ -47,37 +48,25 @@ func NewClusterReconciler(
 		ClusterConfig:    spec.ClusterConfig,
 		ClusterOperation: spec.ClusterOperationSpec,
 		instance:         instance,
+		recorder:         recorder,
 	}
 }
 
-// GetImage returns the image configuration for HDFS components
+// GetImage returns the image of a role, the image of the cluster with the image override of the role
 func (r *Reconciler) GetImage(roleType constant.Role) *util.Image {
-	productVersion := r.Spec.Image.ProductVersion
-	if productVersion == "" {
-		productVersion = hdfsv1alpha1.DefaultProductVersion
-	}
-
-	image := util.NewImage(
-		hdfsv1alpha1.DefaultProductName,
-		version.BuildVersion,
-		productVersion,
-		func(options *util.ImageOptions) {
-			options.Custom = r.Spec.Image.Custom
-			options.Repo = r.Spec.Image.Repo
-			options.PullPolicy = r.Spec.Image.PullPolicy
-		},
-	)
-
-	if r.Spec.Image.KubedoopVersion != "" {
-		image.KubedoopVersion = r.Spec.Image.KubedoopVersion
-	}
-
-	return image
+	return common.RoleImage(r.instance, roleType)
 }
 
 // RegisterResources registers all resources for the HdfsCluster
 func (r *Reconciler) RegisterResources(
 	ctx context.Context) error {
+	if err := common.ValidatePrincipals(r.ClusterConfig, r.instance.Name, r.instance.Namespace); err != nil {
+		return err
+	}
+	if err := common.ValidateDataTransfer(r.ClusterConfig); err != nil {
+		return err
+	}
+
 	// Optional: Create service account for the cluster if needed
 	sa := NewServiceAccountReconciler(r.Client, r.instance, func(o *builder.Options) {
 		o.ClusterName = r.ClusterInfo.ClusterName
@@ -86,6 +75,9 @@ func (r *Reconciler) RegisterResources(
 	})
 	r.AddResource(sa)
 
+	// The graceful stop decides which roles are stopped, it runs before the roles
+	r.AddResource(NewClusterStopReconciler(r.Client, r.instance, r.ClusterInfo, r.GetImage(constant.NameNode), r.recorder))
+
 	clusterComponent := &common.ClusterComponentsInfo{
 		InstanceName:  r.instance.Name,
 		Namespace:     r.instance.Namespace,
@@ -96,33 +88,14 @@ func (r *Reconciler) RegisterResources(
 	}
 	common.PopulateClusterComponents(r.instance, clusterComponent, &r.ClusterInfo)
 
-	// NameNode role
-	if r.instance.Spec.NameNode != nil {
-		nameNodeRoleInfo := reconciler.RoleInfo{
-			ClusterInfo: r.ClusterInfo,
-			RoleName:    string(constant.NameNode),
-		}
-
-		// Create NameNode reconciler with base image
-		nameNodeImage := r.GetImage(constant.NameNode)
-		nameNodeReconciler := name.NewNameNodeRole(
-			r.Client,
-			nameNodeRoleInfo,
-			*r.Spec.NameNode,
-			nameNodeImage,
-			r.instance,
-			clusterComponent,
-		)
-
-		if err := nameNodeReconciler.RegisterResources(ctx); err != nil {
-			return err
-		}
-		r.AddResource(nameNodeReconciler)
-		clusterLogger.Info("Registered NameNode role")
-	}
+	// The roles are started in order: the journalnodes are ready before the namenodes format,
+	// a namenode is active before the datanodes start, see BringUpGate. The start after a stop
+	// is ordered the same way.
 
 	// JournalNode role
 	if r.instance.Spec.JournalNode != nil {
+		r.AddResource(NewJournalNodeRecreateReconciler(r.Client, r.instance, r.recorder))
+
 		journalNodeRoleInfo := reconciler.RoleInfo{
 			ClusterInfo: r.ClusterInfo,
 			RoleName:    string(constant.JournalNode),
@@ -145,8 +118,41 @@ func (r *Reconciler) RegisterResources(
 		clusterLogger.Info("Registered JournalNode role")
 	}
 
+	// The namenodes are created once the journalnodes are ready
+	r.AddResource(NewBringUpGate(r.Client, r.instance, hdfsv1alpha1.BringUpPhaseJournalNodes, hdfsv1alpha1.BringUpPhaseNameNodes))
+
+	// NameNode role
+	if r.instance.Spec.NameNode != nil {
+		nameNodeRoleInfo := reconciler.RoleInfo{
+			ClusterInfo: r.ClusterInfo,
+			RoleName:    string(constant.NameNode),
+		}
+
+		// Create NameNode reconciler with base image
+		nameNodeImage := r.GetImage(constant.NameNode)
+		nameNodeReconciler := name.NewNameNodeRole(
+			r.Client,
+			nameNodeRoleInfo,
+			r.Spec.NameNode.RoleSpec,
+			nameNodeImage,
+			r.instance,
+			clusterComponent,
+		)
+
+		if err := nameNodeReconciler.RegisterResources(ctx); err != nil {
+			return err
+		}
+		r.AddResource(nameNodeReconciler)
+		clusterLogger.Info("Registered NameNode role")
+	}
+
+	// The datanodes are created once a namenode is active
+	r.AddResource(NewBringUpGate(r.Client, r.instance, hdfsv1alpha1.BringUpPhaseNameNodes, hdfsv1alpha1.BringUpPhaseDataNodes))
+
 	// DataNode role
 	if r.instance.Spec.DataNode != nil {
+		r.AddResource(NewDataNodeRecreateReconciler(r.Client, r.instance, r.recorder))
+
 		dataNodeRoleInfo := reconciler.RoleInfo{
 			ClusterInfo: r.ClusterInfo,
 			RoleName:    string(constant.DataNode),
@@ -156,7 +162,7 @@ func (r *Reconciler) RegisterResources(
 		dataNodeReconciler := data.NewDataNodeRole(
 			r.Client,
 			dataNodeRoleInfo,
-			r.Spec.DataNode,
+			&r.Spec.DataNode.RoleSpec,
 			dataNodeImage,
 			r.instance,
 			clusterComponent,
@@ -168,14 +174,79 @@ func (r *Reconciler) RegisterResources(
 		clusterLogger.Info("Registered DataNode role")
 	}
 
+	// The bring-up is completed once the datanodes are ready
+	r.AddResource(NewBringUpGate(r.Client, r.instance, hdfsv1alpha1.BringUpPhaseDataNodes, hdfsv1alpha1.BringUpPhaseCompleted))
+
 	// Discovery
 	discoveryReconciler := NewHdfsDiscovery(
 		r.Client,
 		r.instance,
 		r.ClusterInfo,
+		r.recorder,
 	)
 	r.AddResource(discoveryReconciler)
 	clusterLogger.Info("Registered Discovery role")
 
+	// Backup
+	if common.IsBackupEnabled(r.instance.Spec.ClusterConfig) {
+		backupReconciler := NewBackupReconciler(
+			r.Client,
+			r.instance,
+			r.ClusterInfo,
+			r.GetImage(constant.NameNode),
+		)
+		r.AddResource(backupReconciler)
+		clusterLogger.Info("Registered Backup")
+	}
+
+	// ServiceAclRefresh refreshes the ACLs of the running namenodes when hadoop-policy.xml changes
+	if common.IsServiceAuthorizationEnabled(r.instance.Spec.ClusterConfig) {
+		serviceAclRefreshReconciler := NewNameNodeRefreshReconciler(
+			r.Client,
+			r.instance,
+			r.ClusterInfo,
+			r.GetImage(constant.NameNode),
+			ServiceAclRefresh,
+		)
+		r.AddResource(serviceAclRefreshReconciler)
+		clusterLogger.Info("Registered ServiceAclRefresh")
+	}
+
+	// ProxyUsersRefresh refreshes the proxy users of the running namenodes when they change
+	if common.IsProxyUsersEnabled(r.instance.Spec.ClusterConfig) {
+		proxyUsersRefreshReconciler := NewNameNodeRefreshReconciler(
+			r.Client,
+			r.instance,
+			r.ClusterInfo,
+			r.GetImage(constant.NameNode),
+			ProxyUsersRefresh,
+		)
+		r.AddResource(proxyUsersRefreshReconciler)
+		clusterLogger.Info("Registered ProxyUsersRefresh")
+	}
+
+	// Monitoring, the ServiceMonitors are registered with the role groups
+	if common.IsMonitoringEnabled(r.instance.Spec.ClusterConfig) {
+		r.AddResource(common.NewPrometheusRule(r.Client, r.instance, r.ClusterInfo))
+		clusterLogger.Info("Registered PrometheusRule")
+	} else {
+		r.AddResource(common.NewMonitoringCleanup(r.Client, r.instance))
+	}
+
+	// DiskBalancer runs after all roles are ready, it requeues until the current run is finished
+	if r.instance.Spec.DiskBalancer != nil {
+		diskBalancerReconciler := NewDiskBalancerReconciler(
+			r.Client,
+			r.instance,
+			r.ClusterInfo,
+			r.GetImage(constant.DataNode),
+		)
+		r.AddResource(diskBalancerReconciler)
+		clusterLogger.Info("Registered DiskBalancer")
+	}
+
+	// RollingRestart restarts the role groups with a restart request, it requeues until the restart is finished
+	r.AddResource(NewRollingRestartReconciler(r.Client, r.instance, r.ClusterInfo, r.GetImage(constant.NameNode), r.recorder))
+
 	return nil
 }
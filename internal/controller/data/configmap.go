@@ -2,7 +2,6 @@ package data
 
 import (
 	"context"
-	"fmt"
 
 	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
 	"github.com/zncdatadev/hdfs-operator/internal/common"
@@ -10,7 +9,6 @@ import (
 	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
 	"github.com/zncdatadev/operator-go/pkg/builder"
 	"github.com/zncdatadev/operator-go/pkg/client"
-	"github.com/zncdatadev/operator-go/pkg/constants"
 	"github.com/zncdatadev/operator-go/pkg/reconciler"
 )
 
@@ -62,7 +60,7 @@ func (b *DataNodeConfigMapBuilder) BuildConfig() (map[string]string, error) {
 	data := map[string]string{
 		hdfsv1alpha1.CoreSiteFileName:     b.makeCoreSiteData(),
 		hdfsv1alpha1.HdfsSiteFileName:     b.makeHdfsSiteData(),
-		hdfsv1alpha1.HadoopPolicyFileName: common.MakeHadoopPolicyData(),
+		hdfsv1alpha1.HadoopPolicyFileName: common.MakeHadoopPolicyData(b.instance.Spec.ClusterConfig),
 		hdfsv1alpha1.SecurityFileName:     common.MakeSecurityPropertiesData(),
 		hdfsv1alpha1.SslClientFileName:    common.MakeSslClientData(b.instance.Spec.ClusterConfig),
 		hdfsv1alpha1.SslServerFileName:    common.MakeSslServerData(b.instance.Spec.ClusterConfig),
@@ -81,7 +79,12 @@ func (b *DataNodeConfigMapBuilder) GetConfigOverrides() map[string]map[string]st
 // make core-site.xml data
 func (b *DataNodeConfigMapBuilder) makeCoreSiteData() string {
 	generator := &common.CoreSiteXmlGenerator{InstanceName: b.instance.GetName()}
-	return generator.EnableKerberos(b.instance.Spec.ClusterConfig, b.instance.Namespace).HaZookeeperQuorum().Generate()
+	return generator.EnableKerberos(b.instance.Spec.ClusterConfig, b.instance.Namespace).
+		EnableServiceAuthorization(b.instance.Spec.ClusterConfig).
+		EnableProxyUsers(b.instance.Spec.ClusterConfig).
+		EnableMonitoring(b.instance.Spec.ClusterConfig).
+		HaZookeeperQuorum().
+		Generate()
 }
 
 // make hdfs-site.xml data
@@ -93,12 +96,14 @@ func (b *DataNodeConfigMapBuilder) makeHdfsSiteData() string {
 		b.dataNodeConfig(),
 		b.clusterComponentInfo,
 	)
-	return generator.EnablerKerberos(clusterSpec).EnableHttps().Generate()
+	// EnablerKerberos and EnableHttps return the embedded namenode generator,
+	// so Generate must be called on the datanode generator to keep the datanode properties.
+	generator.EnablerKerberos(clusterSpec).EnableHttps()
+	return generator.EnableDiskBalancer(b.instance.Spec.DiskBalancer).Generate()
 }
 
 func (c *DataNodeConfigMapBuilder) dataNodeConfig() map[string]string {
-	dataDir := fmt.Sprintf("[DISK]%s/%s/%s", constants.KubedoopDataDir, hdfsv1alpha1.DataVolumeMountName, string(constant.DataNode))
 	return map[string]string{
-		"dfs.datanode.data.dir": dataDir,
+		"dfs.datanode.data.dir": common.DataNodeDataDirs(c.instance),
 	}
 }
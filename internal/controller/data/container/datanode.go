@@ -51,7 +51,8 @@ func (b *DataNodeContainerBuilder) Build() *corev1.Container {
 	)
 
 	// Create datanode component and build container
-	component := newDataNodeComponent(b.instance.Name, b.instance.Spec.ClusterConfig)
+	component := newDataNodeComponent(b.instance.Name, b.instance.Namespace, b.instance.Spec.ClusterConfig,
+		common.GetRoleMetrics(b.instance, constant.DataNode), common.DataNodeDataVolumes(b.instance))
 
 	return builder.BuildWithComponent(component)
 }
@@ -59,18 +60,31 @@ func (b *DataNodeContainerBuilder) Build() *corev1.Container {
 // DataNodeComponent implements the component interface for DataNode
 type DataNodeComponent struct {
 	clusterName   string
+	namespace     string
 	clusterConfig *hdfsv1alpha1.ClusterConfigSpec
+	metrics       *hdfsv1alpha1.MetricsSpec
+	dataVolumes   []hdfsv1alpha1.DataVolumeSpec
 }
 
 // Compile-time check to ensure DataNodeComponent implements ContainerComponentInterface
 var _ common.ContainerComponentInterface = &DataNodeComponent{}
 var _ common.ContainerPortsProvider = &DataNodeComponent{}
 var _ common.ContainerHealthCheckProvider = &DataNodeComponent{}
-
-func newDataNodeComponent(clusterName string, clusterConfig *hdfsv1alpha1.ClusterConfigSpec) *DataNodeComponent {
+var _ common.ContainerStartupProbeProvider = &DataNodeComponent{}
+
+func newDataNodeComponent(
+	clusterName string,
+	namespace string,
+	clusterConfig *hdfsv1alpha1.ClusterConfigSpec,
+	metrics *hdfsv1alpha1.MetricsSpec,
+	dataVolumes []hdfsv1alpha1.DataVolumeSpec,
+) *DataNodeComponent {
 	return &DataNodeComponent{
 		clusterName:   clusterName,
+		namespace:     namespace,
 		clusterConfig: clusterConfig,
+		metrics:       metrics,
+		dataVolumes:   dataVolumes,
 	}
 }
 
@@ -87,6 +101,11 @@ func (c *DataNodeComponent) GetArgs() []string {
 	args = append(args, `mkdir -p /kubedoop/config/datanode
 cp /kubedoop/mount/config/datanode/*.xml /kubedoop/config/datanode
 cp /kubedoop/mount/config/datanode/datanode.log4j.properties /kubedoop/config/datanode/log4j.properties`)
+
+	// Substitute the keystore password if TLS is enabled
+	if common.IsTlsEnabled(c.clusterConfig) {
+		args = append(args, common.SubstituteJksPasswordScript(c.clusterConfig.Authentication.Tls))
+	}
 	if common.IsKerberosEnabled(c.clusterConfig) {
 		args = append(args, `{{ if .kerberosEnabled}}
 {{- .kerberosEnv}}
@@ -110,7 +129,7 @@ cp /kubedoop/mount/config/datanode/datanode.log4j.properties /kubedoop/config/da
 }
 
 func (c *DataNodeComponent) GetEnvVars() []corev1.EnvVar {
-	return common.GetCommonContainerEnv(c.clusterConfig, constant.DataNodeComponent, ptr.To(constant.DataNode))
+	return common.GetCommonContainerEnv(c.clusterConfig, constant.DataNodeComponent, ptr.To(constant.DataNode), c.metrics)
 }
 
 func (c *DataNodeComponent) GetVolumeMounts() []corev1.VolumeMount {
@@ -128,10 +147,12 @@ func (c *DataNodeComponent) GetVolumeMounts() []corev1.VolumeMount {
 			Name:      hdfsv1alpha1.ListenerVolumeName,
 			MountPath: constants.KubedoopListenerDir,
 		},
-		{
-			Name:      hdfsv1alpha1.DataVolumeMountName,
-			MountPath: path.Join(hdfsv1alpha1.DataNodeRootDataDirPrefix, hdfsv1alpha1.DataVolumeMountName), // !!! the last "data" is pvc name
-		},
+	}
+	for _, volume := range c.dataVolumes {
+		datanodeMounts = append(datanodeMounts, corev1.VolumeMount{
+			Name:      volume.Name,
+			MountPath: common.DataNodeDataVolumeMountPath(volume),
+		})
 	}
 	return append(mounts, datanodeMounts...)
 }
@@ -165,7 +186,8 @@ func (c *DataNodeComponent) GetLivenessProbe() *corev1.Probe {
 		SuccessThreshold:    1,
 		TimeoutSeconds:      1,
 		ProbeHandler: corev1.ProbeHandler{
-			HTTPGet: common.TlsHttpGetAction(c.clusterConfig, "/datanode.html"),
+			// the liveness does not depend on the KDC, a datanode which can not get a ticket is only not ready
+			TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromString(hdfsv1alpha1.IpcName)},
 		},
 	}
 }
@@ -176,9 +198,10 @@ func (c *DataNodeComponent) GetReadinessProbe() *corev1.Probe {
 		InitialDelaySeconds: 10,
 		PeriodSeconds:       10,
 		SuccessThreshold:    1,
-		TimeoutSeconds:      1,
+		TimeoutSeconds:      10,
 		ProbeHandler: corev1.ProbeHandler{
-			TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromString(hdfsv1alpha1.IpcName)},
+			// ready once the datanode is registered at a namenode
+			Exec: c.jmxProbeAction(`grep -q 'ActorState\\":\\"RUNNING' <<< "$JMX"`),
 		},
 	}
 }
@@ -190,7 +213,14 @@ func (c *DataNodeComponent) GetStartupProbe() *corev1.Probe {
 		PeriodSeconds:       10,
 		TimeoutSeconds:      5,
 		ProbeHandler: corev1.ProbeHandler{
-			HTTPGet: common.TlsHttpGetAction(c.clusterConfig, "/datanode.html"),
+			TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromString(hdfsv1alpha1.IpcName)},
 		},
 	}
 }
+
+// jmxProbeAction reads the DataNodeInfo bean with the keytab of the datanode
+func (c *DataNodeComponent) jmxProbeAction(condition string) *corev1.ExecAction {
+	port := common.HttpPort(c.clusterConfig, hdfsv1alpha1.DataNodeHttpsPort, hdfsv1alpha1.DataNodeHttpPort).ContainerPort
+	check := common.JmxCheck(c.clusterConfig, c.clusterName, c.namespace, port, "Hadoop:service=DataNode,name=DataNodeInfo", condition)
+	return common.NewExecProbeAction(c.clusterConfig, c.clusterName, c.namespace, constant.DataNode, check)
+}
@@ -3,6 +3,7 @@ package container
 import (
 	"maps"
 	"path"
+	"slices"
 	"strings"
 
 	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
@@ -13,6 +14,7 @@ import (
 	"github.com/zncdatadev/operator-go/pkg/reconciler"
 	oputil "github.com/zncdatadev/operator-go/pkg/util"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
 )
 
 // WaitForNameNodesContainerBuilder builds wait-for-namenodes init containers
@@ -20,6 +22,7 @@ type WaitForNameNodesContainerBuilder struct {
 	instance        *hdfsv1alpha1.HdfsCluster
 	roleGroupInfo   *reconciler.RoleGroupInfo
 	roleGroupConfig *commonsv1alpha1.RoleGroupConfigSpec
+	waitSpec        *hdfsv1alpha1.WaitForNameNodesSpec
 	image           *oputil.Image
 }
 
@@ -28,12 +31,14 @@ func NewWaitForNameNodesContainerBuilder(
 	instance *hdfsv1alpha1.HdfsCluster,
 	roleGroupInfo *reconciler.RoleGroupInfo,
 	roleGroupConfig *commonsv1alpha1.RoleGroupConfigSpec,
+	waitSpec *hdfsv1alpha1.WaitForNameNodesSpec,
 	image *oputil.Image,
 ) *WaitForNameNodesContainerBuilder {
 	return &WaitForNameNodesContainerBuilder{
 		instance:        instance,
 		roleGroupInfo:   roleGroupInfo,
 		roleGroupConfig: roleGroupConfig,
+		waitSpec:        waitSpec,
 		image:           image,
 	}
 }
@@ -50,7 +55,7 @@ func (b *WaitForNameNodesContainerBuilder) Build() *corev1.Container {
 	)
 
 	// Create wait-for-namenodes component and build container
-	component := newWaitForNameNodesComponent(b.instance, b.roleGroupInfo)
+	component := newWaitForNameNodesComponent(b.instance, b.roleGroupInfo, b.waitSpec)
 
 	return builder.BuildWithComponent(component)
 }
@@ -59,15 +64,21 @@ func (b *WaitForNameNodesContainerBuilder) Build() *corev1.Container {
 type WaitForNameNodesComponent struct {
 	instance      *hdfsv1alpha1.HdfsCluster
 	roleGroupInfo *reconciler.RoleGroupInfo
+	waitSpec      *hdfsv1alpha1.WaitForNameNodesSpec
 }
 
 // Compile-time check to ensure WaitForNameNodesComponent implements ContainerComponentInterface
 var _ common.ContainerComponentInterface = &WaitForNameNodesComponent{}
 
-func newWaitForNameNodesComponent(instance *hdfsv1alpha1.HdfsCluster, roleGroupInfo *reconciler.RoleGroupInfo) *WaitForNameNodesComponent {
+func newWaitForNameNodesComponent(
+	instance *hdfsv1alpha1.HdfsCluster,
+	roleGroupInfo *reconciler.RoleGroupInfo,
+	waitSpec *hdfsv1alpha1.WaitForNameNodesSpec,
+) *WaitForNameNodesComponent {
 	return &WaitForNameNodesComponent{
 		instance:      instance,
 		roleGroupInfo: roleGroupInfo,
+		waitSpec:      waitSpec,
 	}
 }
 
@@ -83,19 +94,18 @@ func (c *WaitForNameNodesComponent) GetArgs() []string {
 	tmpl := `mkdir -p /kubedoop/config/wait-for-namenodes
 cp /kubedoop/mount/config/wait-for-namenodes/*.xml /kubedoop/config/wait-for-namenodes
 cp /kubedoop/mount/config/wait-for-namenodes/wait-for-namenodes.log4j.properties /kubedoop/config/wait-for-namenodes/log4j.properties
-
+{{ .substituteJksPassword }}
 {{ if .kerberosEnabled }}
 {{- .kerberosEnv }}
 
 {{- .kinitScript }}
 {{- end }}
 
-echo "Waiting for namenodes to get ready:"
-n=0
-while [ ${n} -lt 12 ];
-do
-    ALL_NODES_READY=true
-    for namenode_id in ` + c.nameNodeIds() + `;
+# check_namenodes succeeds if a namenode is active and not in a safe mode turned on manually,
+# the standby namenodes are not required, the datanode registers with them when they are up
+check_namenodes() {
+    ACTIVE_NAMENODE=""
+    for namenode_id in {{ .nameNodeIds }}
     do
         echo -n "Checking pod $namenode_id... "
         SERVICE_STATE=$(/kubedoop/hadoop/bin/hdfs haadmin -getServiceState $namenode_id | tail -n1 || true)
@@ -103,26 +113,90 @@ do
             echo "$SERVICE_STATE"
         else
             echo "not ready"
-            ALL_NODES_READY=false
+        fi
+        if [ "$SERVICE_STATE" = "active" ]; then
+            ACTIVE_NAMENODE=$namenode_id
         fi
     done
-    if [ "$ALL_NODES_READY" == "true" ]; then
-        echo "All namenodes ready!"
+    if [ -z "$ACTIVE_NAMENODE" ]; then
+        echo "No active namenode"
+        return 1
+    fi
+{{- if .waitForSafeModeExit }}
+    HTTP_ADDRESS=$(/kubedoop/hadoop/bin/hdfs getconf -confKey "{{ .httpAddressKey }}.$ACTIVE_NAMENODE")
+    SAFE_MODE=$(curl -sSf --max-time 5 --insecure "{{ .httpScheme }}://$HTTP_ADDRESS/jmx?qry=Hadoop:service=NameNode,name=NameNodeInfo" \
+        | grep -o '"Safemode" *: *"[^"]*"' || true)
+    if [[ "$SAFE_MODE" == *"turned on manually"* ]]; then
+        echo "Namenode $ACTIVE_NAMENODE is in safe mode turned on manually"
+        return 1
+    fi
+{{- end }}
+    echo "Namenode $ACTIVE_NAMENODE is active!"
+}
+
+echo "Waiting for namenodes to get ready:"
+START=$(date +%s)
+until check_namenodes
+do
+{{- if ne .onTimeout "Block" }}
+    if [ $(( $(date +%s) - START )) -ge {{ .timeoutSeconds }} ]; then
+{{- if eq .onTimeout "Fail" }}
+        echo "Namenodes not ready after {{ .timeoutSeconds }}s, failing"
+        exit 1
+{{- else }}
+        echo "Namenodes not ready after {{ .timeoutSeconds }}s, starting anyway"
         break
+{{- end }}
     fi
+{{- end }}
     echo ""
-    n=$((n + 1))
-    sleep 5
+    sleep {{ .intervalSeconds }}
 done
 `
 	data := common.CreateExportKrbRealmEnvData(c.instance.Spec.ClusterConfig)
-	principal := common.CreateKerberosPrincipal(c.instance.Name, c.instance.Namespace, constant.DataNode)
+	principal := common.CreateKerberosPrincipal(c.instance.Spec.ClusterConfig, c.instance.Name, c.instance.Namespace, constant.DataNode)
 	maps.Copy(data, common.CreateGetKerberosTicketData(principal))
+	maps.Copy(data, common.CreateJksPasswordData(c.instance.Spec.ClusterConfig))
+	maps.Copy(data, c.waitData())
 	return common.ParseTemplate(tmpl, data)
 }
 
+// waitData returns the settings of the wait, unset fields have their defaults
+func (c *WaitForNameNodesComponent) waitData() map[string]interface{} {
+	timeoutSeconds := hdfsv1alpha1.DefaultWaitForNameNodesTimeoutSeconds
+	intervalSeconds := hdfsv1alpha1.DefaultWaitForNameNodesIntervalSeconds
+	onTimeout := hdfsv1alpha1.WaitForNameNodesFail
+	waitForSafeModeExit := true
+	if spec := c.waitSpec; spec != nil {
+		if spec.TimeoutSeconds > 0 {
+			timeoutSeconds = spec.TimeoutSeconds
+		}
+		if spec.IntervalSeconds > 0 {
+			intervalSeconds = spec.IntervalSeconds
+		}
+		if spec.OnTimeout != "" {
+			onTimeout = spec.OnTimeout
+		}
+		waitForSafeModeExit = ptr.Deref(spec.WaitForSafeModeExit, true)
+	}
+
+	httpScheme, httpAddressKey := "http", "dfs.namenode.http-address"
+	if common.IsTlsEnabled(c.instance.Spec.ClusterConfig) {
+		httpScheme, httpAddressKey = "https", "dfs.namenode.https-address"
+	}
+	return map[string]interface{}{
+		"nameNodeIds":         c.nameNodeIds(),
+		"timeoutSeconds":      timeoutSeconds,
+		"intervalSeconds":     intervalSeconds,
+		"onTimeout":           string(onTimeout),
+		"waitForSafeModeExit": waitForSafeModeExit,
+		"httpScheme":          httpScheme,
+		"httpAddressKey":      httpAddressKey + "." + c.instance.Name,
+	}
+}
+
 func (c *WaitForNameNodesComponent) GetEnvVars() []corev1.EnvVar {
-	return common.GetCommonContainerEnv(c.instance.Spec.ClusterConfig, constant.WaitForNameNodesComponent, nil)
+	return common.GetCommonContainerEnv(c.instance.Spec.ClusterConfig, constant.WaitForNameNodesComponent, nil, nil)
 }
 
 func (c *WaitForNameNodesComponent) GetVolumeMounts() []corev1.VolumeMount {
@@ -140,7 +214,11 @@ func (c *WaitForNameNodesComponent) GetVolumeMounts() []corev1.VolumeMount {
 	return append(mounts, waitNameNodeMounts...)
 }
 
+// nameNodeIds returns the pods of all namenode role groups, sorted so the pod template is stable
 func (c *WaitForNameNodesComponent) nameNodeIds() string {
+	if c.instance.Spec.NameNode == nil {
+		return ""
+	}
 	// Get namenode role group info from the cluster
 	nameNodeRoleGroups := c.instance.Spec.NameNode.RoleGroups
 	podNames := make([]string, 0, len(nameNodeRoleGroups))
@@ -154,9 +232,10 @@ func (c *WaitForNameNodesComponent) nameNodeIds() string {
 			RoleGroupName: groupName,
 		}
 		statefulSetName := nnRoleGroupInfo.GetFullName()
-		replicas := *roleGroupSpec.Replicas
+		replicas := ptr.Deref(roleGroupSpec.Replicas, 1)
 		groupPodNames := common.CreatePodNamesByReplicas(replicas, statefulSetName)
 		podNames = append(podNames, groupPodNames...)
 	}
+	slices.Sort(podNames)
 	return strings.Join(podNames, " ")
 }
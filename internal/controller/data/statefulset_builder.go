@@ -15,6 +15,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -141,10 +142,12 @@ func (b *DataNodeStatefulSetBuilder) GetVolumes() []corev1.Volume {
 
 // GetVolumeClaimTemplates returns PVCs for datanode
 func (b *DataNodeStatefulSetBuilder) GetVolumeClaimTemplates() []corev1.PersistentVolumeClaim {
-	return []corev1.PersistentVolumeClaim{
-		b.createDataPvcTemplate(),
-		// b.createListenPvcTemplate(),
+	volumes := common.DataNodeDataVolumes(b.GetInstance())
+	templates := make([]corev1.PersistentVolumeClaim, 0, len(volumes))
+	for _, volume := range volumes {
+		templates = append(templates, b.createDataPvcTemplate(volume))
 	}
+	return templates
 }
 
 // GetServiceAccountName returns the service account name for datanode
@@ -169,22 +172,31 @@ func (b *DataNodeStatefulSetBuilder) makeWaitForNameNodesContainer() corev1.Cont
 		b.GetInstance(),
 		b.GetRoleGroupInfo(),
 		b.config.RoleGroupConfigSpec,
+		b.GetInstance().Spec.DataNode.WaitForNameNodes,
 		b.image,
 	)
 	return *waitForNameNodes.Build()
 }
 
-// TODO: extract this to a common method if needed in other builders(nn,jn,dn)
-func (b *DataNodeStatefulSetBuilder) createDataPvcTemplate() corev1.PersistentVolumeClaim {
-	// storageSize := b.config.Config.Resources.Storage.Capacity
+// createDataPvcTemplate returns the PVC template of a data volume, with the storage capacity of the role group
+// if the volume has no capacity
+func (b *DataNodeStatefulSetBuilder) createDataPvcTemplate(volume hdfsv1alpha1.DataVolumeSpec) corev1.PersistentVolumeClaim {
 	storageSize := b.config.Resources.Storage.Capacity
+	if volume.Capacity != nil {
+		storageSize = *volume.Capacity
+	}
+	var storageClassName *string
+	if volume.StorageClass != "" {
+		storageClassName = ptr.To(volume.StorageClass)
+	}
 	return corev1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: hdfsv1alpha1.DataVolumeMountName,
+			Name: volume.Name,
 		},
 		Spec: corev1.PersistentVolumeClaimSpec{
-			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
-			VolumeMode:  func() *corev1.PersistentVolumeMode { v := corev1.PersistentVolumeFilesystem; return &v }(),
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: storageClassName,
+			VolumeMode:       func() *corev1.PersistentVolumeMode { v := corev1.PersistentVolumeFilesystem; return &v }(),
 			Resources: corev1.VolumeResourceRequirements{
 				Requests: corev1.ResourceList{
 					corev1.ResourceStorage: storageSize,
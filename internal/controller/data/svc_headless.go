@@ -25,7 +25,11 @@ func NewDataNodeServiceBuilder(
 	instance *hdfsv1alpha1.HdfsCluster,
 	roleGroupInfo *reconciler.RoleGroupInfo,
 ) *DataNodeServiceBuilder {
-	dnBulder := &DataNodeServiceBuilder{}
+	// the ports depend on the instance, it is set before the ports are read
+	dnBulder := &DataNodeServiceBuilder{
+		instance:      instance,
+		roleGroupInfo: roleGroupInfo,
+	}
 	dnBulder.HdfsServiceBuilder = *common.NewHdfsServiceBuilder(
 		client,
 		roleGroupInfo,
@@ -33,28 +37,32 @@ func NewDataNodeServiceBuilder(
 		true,                      // not a headless service
 		dnBulder,                  // Use self as ServicePortProvider
 	)
-	dnBulder.instance = instance
-	dnBulder.roleGroupInfo = roleGroupInfo
 	return dnBulder
 }
 
 // GetServicePorts returns the service ports for DataNode
 func (b *DataNodeServiceBuilder) GetServicePorts() []corev1.ContainerPort {
-	return []corev1.ContainerPort{
+	clusterConfig := b.instance.Spec.ClusterConfig
+	ports := []corev1.ContainerPort{
 		{
 			Name:          "data",
 			ContainerPort: 9866,
 			Protocol:      corev1.ProtocolTCP,
 		},
-		{
-			Name:          "http",
-			ContainerPort: 9864,
-			Protocol:      corev1.ProtocolTCP,
-		},
+		common.HttpPort(clusterConfig, hdfsv1alpha1.DataNodeHttpsPort, hdfsv1alpha1.DataNodeHttpPort),
 		{
 			Name:          "ipc",
 			ContainerPort: 9867,
 			Protocol:      corev1.ProtocolTCP,
 		},
 	}
+	// the web UI is reached through the oauth2-proxy sidecar
+	if common.IsOidcEnabled(clusterConfig) {
+		ports = append(ports, corev1.ContainerPort{
+			Name:          "oidc",
+			ContainerPort: 4180,
+			Protocol:      corev1.ProtocolTCP,
+		})
+	}
+	return ports
 }
@@ -29,9 +29,37 @@ var (
 func NewHdfsDiscovery(
 	client *pkgclient.Client,
 	instance *hdfsv1alpha1.HdfsCluster,
-	clusterInfo reconciler.ClusterInfo) reconciler.ResourceReconciler[builder.ConfigBuilder] {
+	clusterInfo reconciler.ClusterInfo,
+	recorder *EventRecorder,
+) reconciler.ResourceReconciler[builder.ConfigBuilder] {
 	discoveryBuilder := NewDiscoveryConfigMapBuilder(client, instance, clusterInfo)
-	return reconciler.NewGenericResourceReconciler(client, discoveryBuilder)
+	return &DiscoveryReconciler{
+		GenericResourceReconciler: reconciler.NewGenericResourceReconciler(client, discoveryBuilder),
+		instance:                  instance,
+		recorder:                  recorder,
+	}
+}
+
+// DiscoveryReconciler creates or updates the discovery ConfigMap, clients of the cluster are notified
+// of a change with an event
+type DiscoveryReconciler struct {
+	*reconciler.GenericResourceReconciler[builder.ConfigBuilder]
+	instance *hdfsv1alpha1.HdfsCluster
+	recorder *EventRecorder
+}
+
+func (r *DiscoveryReconciler) Reconcile(ctx context.Context) (ctrl.Result, error) {
+	resource, err := r.GetBuilder().Build(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	result, err := r.ResourceReconcile(ctx, resource)
+	// the resource reconciler requeues only after a create or update
+	if err == nil && !result.IsZero() {
+		r.recorder.Normal(r.instance, EventReasonDiscoveryUpdated, "Update",
+			"Discovery ConfigMap %s created or updated", resource.GetName())
+	}
+	return result, err
 }
 
 // DiscoveryConfigMapBuilder implements discovery-specific ConfigMap logic
@@ -97,7 +125,7 @@ func (b *DiscoveryConfigMapBuilder) makeHdfsSiteXmlData(ctx context.Context) (st
 		return "", err
 	}
 	if common.IsKerberosEnabled(b.instance.Spec.ClusterConfig) {
-		properties = append(properties, common.SecurityDiscoveryHdfsSiteXml()...)
+		properties = append(properties, common.SecurityDiscoveryHdfsSiteXml(b.instance.Spec.ClusterConfig.Authentication.Kerberos)...)
 	}
 	return xml.String(properties), nil
 }
@@ -0,0 +1,484 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/common"
+	"github.com/zncdatadev/hdfs-operator/internal/constant"
+	listenerv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/listeners/v1alpha1"
+	pkgclient "github.com/zncdatadev/operator-go/pkg/client"
+	"github.com/zncdatadev/operator-go/pkg/constants"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	"github.com/zncdatadev/operator-go/pkg/util"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var diskBalancerLog = ctrl.Log.WithName("disk-balancer")
+
+const (
+	diskBalancerConfigVolumeName = "disk-balancer-config"
+	diskBalancerPlanRootDir      = "/system/diskbalancer"
+	// diskBalancerRunIDLabel is set on every disk balancer job, used to find the jobs of the current run
+	diskBalancerRunIDLabel = "hdfs.kubedoop.dev/disk-balancer-run-id"
+	diskBalancerPodLabel   = "hdfs.kubedoop.dev/disk-balancer-pod"
+
+	diskBalancerReadyRequeueAfter = 10 * time.Second
+	// diskBalancerJobTTL is the time a finished job is kept, the result of the run is kept in the status
+	diskBalancerJobTTL = 86400
+)
+
+var _ reconciler.Reconciler = &DiskBalancerReconciler{}
+
+// DiskBalancerReconciler runs `hdfs diskbalancer -plan/-execute/-query` against the selected dataNode pods.
+// One job is created per pod and run, its progress is written to the cluster status.
+type DiskBalancerReconciler struct {
+	client      *pkgclient.Client
+	instance    *hdfsv1alpha1.HdfsCluster
+	clusterInfo reconciler.ClusterInfo
+	image       *util.Image
+}
+
+func NewDiskBalancerReconciler(
+	client *pkgclient.Client,
+	instance *hdfsv1alpha1.HdfsCluster,
+	clusterInfo reconciler.ClusterInfo,
+	image *util.Image,
+) *DiskBalancerReconciler {
+	return &DiskBalancerReconciler{
+		client:      client,
+		instance:    instance,
+		clusterInfo: clusterInfo,
+		image:       image,
+	}
+}
+
+func (r *DiskBalancerReconciler) GetName() string {
+	return r.instance.Name + "-diskbalancer"
+}
+
+func (r *DiskBalancerReconciler) GetNamespace() string {
+	return r.instance.Namespace
+}
+
+func (r *DiskBalancerReconciler) GetClient() *pkgclient.Client {
+	return r.client
+}
+
+func (r *DiskBalancerReconciler) spec() *hdfsv1alpha1.DiskBalancerSpec {
+	return r.instance.Spec.DiskBalancer
+}
+
+// Reconcile create a job for every selected dataNode pod which has no job in the current run yet.
+// Jobs are immutable, so existing jobs are never updated. The pods which finished the run are skipped,
+// their jobs are deleted after the TTL.
+func (r *DiskBalancerReconciler) Reconcile(ctx context.Context) (ctrl.Result, error) {
+	spec := r.spec()
+	if spec == nil || spec.RunID == "" {
+		return ctrl.Result{}, nil
+	}
+
+	finished := r.finishedPods()
+	var requeue bool
+	for _, podName := range r.targetPods() {
+		if finished[podName] {
+			continue
+		}
+		job := &batchv1.Job{}
+		err := r.client.Get(ctx, ctrlclient.ObjectKey{Namespace: r.GetNamespace(), Name: r.jobName(podName)}, job)
+		if err == nil {
+			continue
+		}
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+
+		address, ipcPort, err := r.getDataNodeAddress(ctx, podName)
+		if err != nil {
+			// the pod may not be scheduled yet, try again later
+			diskBalancerLog.Info("datanode address not available, retry later", "pod", podName, "reason", err.Error())
+			requeue = true
+			continue
+		}
+
+		if err := r.client.CreateDoesNotExist(ctx, r.buildJob(podName, address, ipcPort)); err != nil {
+			return ctrl.Result{}, err
+		}
+		diskBalancerLog.Info("Created disk balancer job", "pod", podName, "runId", spec.RunID)
+	}
+
+	if requeue {
+		return ctrl.Result{RequeueAfter: diskBalancerReadyRequeueAfter}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// Ready collect the state of the disk balancer jobs into the cluster status.
+// It requeues until every pod in the current run has finished.
+func (r *DiskBalancerReconciler) Ready(ctx context.Context) (ctrl.Result, error) {
+	spec := r.spec()
+	if spec == nil || spec.RunID == "" {
+		return ctrl.Result{}, nil
+	}
+
+	jobs := &batchv1.JobList{}
+	if err := r.client.Client.List(ctx, jobs,
+		ctrlclient.InNamespace(r.GetNamespace()),
+		ctrlclient.MatchingLabels{
+			common.LabelCrName:     r.instance.Name,
+			diskBalancerRunIDLabel: spec.RunID,
+		},
+	); err != nil {
+		return ctrl.Result{}, err
+	}
+	jobsByPod := make(map[string]*batchv1.Job, len(jobs.Items))
+	for i := range jobs.Items {
+		jobsByPod[jobs.Items[i].Labels[diskBalancerPodLabel]] = &jobs.Items[i]
+	}
+
+	previous := make(map[string]hdfsv1alpha1.DiskBalancerPodStatus)
+	if current := r.instance.Status.DiskBalancer; current != nil && current.RunID == spec.RunID {
+		for _, podStatus := range current.Pods {
+			previous[podStatus.Pod] = podStatus
+		}
+	}
+
+	newStatus := &hdfsv1alpha1.DiskBalancerStatus{RunID: spec.RunID}
+	finished := true
+	for _, podName := range r.targetPods() {
+		podStatus := diskBalancerPodStatus(podName, jobsByPod[podName])
+		if old, ok := previous[podName]; ok && jobsByPod[podName] == nil && diskBalancerPodFinished(old) {
+			// the job of a finished pod was deleted after its TTL
+			podStatus = old
+		}
+		if old, ok := previous[podName]; ok && old.Phase == podStatus.Phase && old.Message == podStatus.Message {
+			podStatus.LastTransitionTime = old.LastTransitionTime
+		}
+		if podStatus.Phase == hdfsv1alpha1.DiskBalancerPhasePending || podStatus.Phase == hdfsv1alpha1.DiskBalancerPhaseRunning {
+			finished = false
+		}
+		newStatus.Pods = append(newStatus.Pods, podStatus)
+	}
+
+	if !diskBalancerStatusEqual(r.instance.Status.DiskBalancer, newStatus) {
+		r.instance.Status.DiskBalancer = newStatus
+		if err := r.client.Client.Status().Update(ctx, r.instance); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if !finished {
+		diskBalancerLog.Info("Disk balancer run is in progress", "runId", spec.RunID)
+		return ctrl.Result{RequeueAfter: diskBalancerReadyRequeueAfter}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// targetPods returns the dataNode pods selected by the disk balancer spec, sorted by name.
+func (r *DiskBalancerReconciler) targetPods() []string {
+	spec := r.spec()
+	if r.instance.Spec.DataNode == nil {
+		return nil
+	}
+
+	var pods []string
+	for groupName, roleGroup := range r.instance.Spec.DataNode.RoleGroups {
+		if len(spec.RoleGroups) > 0 && !slices.Contains(spec.RoleGroups, groupName) {
+			continue
+		}
+		roleGroupInfo := reconciler.RoleGroupInfo{
+			RoleInfo: reconciler.RoleInfo{
+				ClusterInfo: r.clusterInfo,
+				RoleName:    string(constant.DataNode),
+			},
+			RoleGroupName: groupName,
+		}
+		replicas := ptr.Deref(roleGroup.Replicas, 1)
+		for _, podName := range common.CreatePodNamesByReplicas(replicas, roleGroupInfo.GetFullName()) {
+			if len(spec.Pods) > 0 && !slices.Contains(spec.Pods, podName) {
+				continue
+			}
+			pods = append(pods, podName)
+		}
+	}
+	sort.Strings(pods)
+	return pods
+}
+
+// finishedPods returns the pods which finished the current run, read from the status
+func (r *DiskBalancerReconciler) finishedPods() map[string]bool {
+	finished := make(map[string]bool)
+	current := r.instance.Status.DiskBalancer
+	if current == nil || current.RunID != r.spec().RunID {
+		return finished
+	}
+	for _, podStatus := range current.Pods {
+		if diskBalancerPodFinished(podStatus) {
+			finished[podStatus.Pod] = true
+		}
+	}
+	return finished
+}
+
+func diskBalancerPodFinished(podStatus hdfsv1alpha1.DiskBalancerPodStatus) bool {
+	return podStatus.Phase == hdfsv1alpha1.DiskBalancerPhaseSucceeded || podStatus.Phase == hdfsv1alpha1.DiskBalancerPhaseFailed
+}
+
+func (r *DiskBalancerReconciler) jobName(podName string) string {
+	return fmt.Sprintf("%s-db-%s", podName, r.spec().RunID)
+}
+
+// getDataNodeAddress returns the address and ipc port registered by the datanode,
+// they are taken from the listener mounted into the pod.
+func (r *DiskBalancerReconciler) getDataNodeAddress(ctx context.Context, podName string) (string, int32, error) {
+	pod := &corev1.Pod{}
+	if err := r.client.Get(ctx, ctrlclient.ObjectKey{Namespace: r.GetNamespace(), Name: podName}, pod); err != nil {
+		return "", 0, err
+	}
+
+	var listenerName string
+	for key, value := range pod.Labels {
+		if strings.HasPrefix(key, "listeners.kubedoop.dev/mnt") {
+			listenerName = value
+			break
+		}
+	}
+	if listenerName == "" {
+		return "", 0, ErrListenerNotFound
+	}
+
+	listener := &listenerv1alpha1.Listener{}
+	if err := r.client.Get(ctx, ctrlclient.ObjectKey{Namespace: r.GetNamespace(), Name: listenerName}, listener); err != nil {
+		return "", 0, err
+	}
+	if len(listener.Status.IngressAddresses) == 0 {
+		return "", 0, ErrListenerAddressesNotFound
+	}
+	address := listener.Status.IngressAddresses[0]
+	ipcPort, ok := address.Ports[hdfsv1alpha1.IpcName]
+	if !ok {
+		return "", 0, errors.Errorf("not found port %s in listener %s", hdfsv1alpha1.IpcName, listenerName)
+	}
+	return address.Address, ipcPort, nil
+}
+
+func (r *DiskBalancerReconciler) buildJob(podName, address string, ipcPort int32) *batchv1.Job {
+	labels := map[string]string{
+		common.LabelCrName:     r.instance.Name,
+		common.LabelManagedBy:  "hdfs-operator",
+		common.LabelComponent:  "disk-balancer",
+		diskBalancerRunIDLabel: r.spec().RunID,
+		diskBalancerPodLabel:   podName,
+	}
+	maps.Copy(labels, r.clusterInfo.GetLabels())
+
+	var pullSecrets []corev1.LocalObjectReference
+	if r.image.PullSecretName != "" {
+		pullSecrets = []corev1.LocalObjectReference{{Name: r.image.PullSecretName}}
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      r.jobName(podName),
+			Namespace: r.GetNamespace(),
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            ptr.To[int32](0),
+			TTLSecondsAfterFinished: ptr.To[int32](diskBalancerJobTTL),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ServiceAccountName: common.CreateServiceAccountName(r.instance.Name),
+					ImagePullSecrets:   pullSecrets,
+					SecurityContext:    &corev1.PodSecurityContext{FSGroup: ptr.To[int64](1000)},
+					Containers:         []corev1.Container{r.buildContainer(podName, address, ipcPort)},
+					Volumes:            r.volumes(),
+				},
+			},
+		},
+	}
+}
+
+func (r *DiskBalancerReconciler) buildContainer(podName, address string, ipcPort int32) corev1.Container {
+	clusterConfig := r.instance.Spec.ClusterConfig
+	configDir := path.Join(constants.KubedoopConfigDir, constant.DiskBalancerContainer)
+	envs := []corev1.EnvVar{
+		{Name: "HADOOP_CONF_DIR", Value: configDir},
+		{Name: "HADOOP_HOME", Value: hdfsv1alpha1.HadoopHome},
+		{Name: "DATANODE_POD", Value: podName},
+		{Name: "DATANODE_ADDRESS", Value: address},
+		{Name: "DATANODE_IPC_PORT", Value: fmt.Sprintf("%d", ipcPort)},
+	}
+	mounts := []corev1.VolumeMount{
+		{Name: diskBalancerConfigVolumeName, MountPath: path.Join(constants.KubedoopConfigDirMount, constant.DiskBalancerContainer)},
+	}
+	if common.IsKerberosEnabled(clusterConfig) {
+		var jvmArgs []string
+		envs = append(envs, common.SecurityEnvs(constant.ContainerComponent(constant.DiskBalancerContainer), &jvmArgs)...)
+		mounts = append(mounts, common.SecurityVolumeMounts()...)
+	}
+
+	return corev1.Container{
+		Name:            constant.DiskBalancerContainer,
+		Image:           r.image.String(),
+		ImagePullPolicy: r.image.GetPullPolicy(),
+		Command:         []string{"/bin/bash", "-x", "-euo", "pipefail", "-c"},
+		Args:            r.args(),
+		Env:             envs,
+		VolumeMounts:    mounts,
+	}
+}
+
+func (r *DiskBalancerReconciler) volumes() []corev1.Volume {
+	volumes := []corev1.Volume{
+		{
+			// discovery config map contains the client config of the cluster
+			Name: diskBalancerConfigVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: r.instance.Name},
+				},
+			},
+		},
+	}
+	clusterConfig := r.instance.Spec.ClusterConfig
+	if common.IsKerberosEnabled(clusterConfig) {
+		// diskbalancer commands require hdfs superuser, which is the namenode principal
+		volumes = append(volumes, common.CreateKerberosSecretPvc(clusterConfig.Authentication.Kerberos, r.instance.Name, constant.NameNode))
+	}
+	return volumes
+}
+
+const diskBalancerScriptTemplate = `mkdir -p {{ .configDir }}
+cp {{ .mountConfigDir }}/*.xml {{ .configDir }}
+
+{{ if .kerberosEnabled }}
+{{- .kerberosEnv }}
+
+{{- .kinitScript }}
+
+{{- end }}
+
+PLAN_DIR={{ .planDir }}
+echo "Planning disk balancer for $DATANODE_POD ($DATANODE_ADDRESS)"
+/kubedoop/hadoop/bin/hdfs diskbalancer -plan "$DATANODE_ADDRESS" -out "$PLAN_DIR" -thresholdPercentage {{ .threshold }} -bandwidth {{ .bandwidth }}
+
+PLAN_FILE=$(/kubedoop/hadoop/bin/hdfs dfs -ls -C "$PLAN_DIR" 2>/dev/null | grep '\.plan\.json$' | head -n1 || true)
+if [ -z "$PLAN_FILE" ]
+then
+    echo "No plan generated for $DATANODE_POD, disks are balanced. Skipping..."
+    exit 0
+fi
+
+echo "Executing plan $PLAN_FILE"
+/kubedoop/hadoop/bin/hdfs diskbalancer -execute "$PLAN_FILE"
+
+while true
+do
+    RESULT=$(/kubedoop/hadoop/bin/hdfs diskbalancer -query "$DATANODE_ADDRESS:$DATANODE_IPC_PORT" | grep -oE 'PLAN_[A-Z_]+|NO_PLAN' | tail -n1 || true)
+    echo "Disk balancer state of $DATANODE_POD: $RESULT"
+    case "$RESULT" in
+        PLAN_DONE|NO_PLAN)
+            exit 0
+            ;;
+        PLAN_CANCELLED)
+            exit 1
+            ;;
+    esac
+    sleep {{ .queryInterval }}
+done
+`
+
+func (r *DiskBalancerReconciler) args() []string {
+	spec := r.spec()
+	data := common.CreateExportKrbRealmEnvData(r.instance.Spec.ClusterConfig)
+	principal := common.CreateKerberosPrincipal(r.instance.Spec.ClusterConfig, r.instance.Name, r.instance.Namespace, constant.NameNode)
+	maps.Copy(data, common.CreateGetKerberosTicketData(principal))
+	maps.Copy(data, map[string]interface{}{
+		"configDir":      path.Join(constants.KubedoopConfigDir, constant.DiskBalancerContainer),
+		"mountConfigDir": path.Join(constants.KubedoopConfigDirMount, constant.DiskBalancerContainer),
+		"planDir":        path.Join(diskBalancerPlanRootDir, spec.RunID, "$DATANODE_POD"),
+		"threshold":      valueOrDefault(spec.ThresholdPercentage, 10),
+		"bandwidth":      valueOrDefault(spec.Bandwidth, 10),
+		"queryInterval":  valueOrDefault(spec.QueryIntervalSeconds, 30),
+	})
+	return common.ParseTemplate(diskBalancerScriptTemplate, data)
+}
+
+func valueOrDefault(v, defaultValue int32) int32 {
+	if v > 0 {
+		return v
+	}
+	return defaultValue
+}
+
+// diskBalancerPodStatus derive the pod status of a run from the state of its job
+func diskBalancerPodStatus(podName string, job *batchv1.Job) hdfsv1alpha1.DiskBalancerPodStatus {
+	podStatus := hdfsv1alpha1.DiskBalancerPodStatus{
+		Pod:                podName,
+		Phase:              hdfsv1alpha1.DiskBalancerPhasePending,
+		LastTransitionTime: metav1.Now(),
+	}
+	if job == nil {
+		podStatus.Message = "waiting for the datanode to be available"
+		return podStatus
+	}
+
+	podStatus.JobName = job.Name
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			podStatus.Phase = hdfsv1alpha1.DiskBalancerPhaseSucceeded
+			podStatus.Message = "plan executed"
+			return podStatus
+		case batchv1.JobFailed:
+			podStatus.Phase = hdfsv1alpha1.DiskBalancerPhaseFailed
+			podStatus.Message = condition.Message
+			return podStatus
+		}
+	}
+	if job.Status.Active > 0 {
+		podStatus.Phase = hdfsv1alpha1.DiskBalancerPhaseRunning
+		podStatus.Message = "plan is executing"
+	}
+	return podStatus
+}
+
+func diskBalancerStatusEqual(a, b *hdfsv1alpha1.DiskBalancerStatus) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.RunID != b.RunID || len(a.Pods) != len(b.Pods) {
+		return false
+	}
+	for i := range a.Pods {
+		if a.Pods[i].Pod != b.Pods[i].Pod ||
+			a.Pods[i].JobName != b.Pods[i].JobName ||
+			a.Pods[i].Phase != b.Pods[i].Phase ||
+			a.Pods[i].Message != b.Pods[i].Message {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/events"
+)
+
+// Reasons of the events emitted on the HdfsCluster
+const (
+	EventReasonNameNodeFormatted            = "NameNodeFormatted"
+	EventReasonZooKeeperFormatted           = "ZooKeeperFormatted"
+	EventReasonNameNodeFailover             = "NameNodeFailover"
+	EventReasonDataNodeDecommissionStarted  = "DataNodeDecommissionStarted"
+	EventReasonDataNodeDecommissionFinished = "DataNodeDecommissionFinished"
+	EventReasonRolloutStarted               = "RolloutStarted"
+	EventReasonDiscoveryUpdated             = "DiscoveryConfigMapUpdated"
+	EventReasonReconcileFailed              = "ReconcileFailed"
+	EventReasonClusterStopping              = "ClusterStopping"
+	EventReasonClusterStopped               = "ClusterStopped"
+	EventReasonClusterStarting              = "ClusterStarting"
+	EventReasonSaveNamespaceFailed          = "SaveNamespaceFailed"
+	EventReasonLeaveSafeModeFailed          = "LeaveSafeModeFailed"
+	EventReasonReconciliationPaused         = "ReconciliationPaused"
+	EventReasonRestartStarted               = "RestartStarted"
+	EventReasonRestartFinished              = "RestartFinished"
+	EventReasonRestartFailed                = "RestartFailed"
+	EventReasonStatefulSetRecreated         = "StatefulSetRecreated"
+)
+
+// maxEventNoteLength is the maximal length of the note of an event accepted by the API server
+const maxEventNoteLength = 1024
+
+// DefaultEventInterval is the minimal interval between two events with the same reason and note on an object
+const DefaultEventInterval = 10 * time.Minute
+
+// EventRecorder emits events on the HdfsCluster. An event with the same reason and note as a previous one
+// on the same object is dropped within the interval, so a failing reconcile does not flood the events.
+// A nil EventRecorder drops all events.
+type EventRecorder struct {
+	recorder events.EventRecorder
+	interval time.Duration
+
+	mu      sync.Mutex
+	emitted map[string]time.Time
+}
+
+func NewEventRecorder(recorder events.EventRecorder, interval time.Duration) *EventRecorder {
+	return &EventRecorder{
+		recorder: recorder,
+		interval: interval,
+		emitted:  make(map[string]time.Time),
+	}
+}
+
+// Normal emits an event of type Normal
+func (r *EventRecorder) Normal(object runtime.Object, reason string, action string, note string, args ...any) {
+	r.eventf(object, corev1.EventTypeNormal, reason, action, note, args...)
+}
+
+// Warning emits an event of type Warning
+func (r *EventRecorder) Warning(object runtime.Object, reason string, action string, note string, args ...any) {
+	r.eventf(object, corev1.EventTypeWarning, reason, action, note, args...)
+}
+
+func (r *EventRecorder) eventf(object runtime.Object, eventType string, reason string, action string, note string, args ...any) {
+	if r == nil {
+		return
+	}
+	note = fmt.Sprintf(note, args...)
+	if len(note) > maxEventNoteLength {
+		note = note[:maxEventNoteLength-3] + "..."
+	}
+	accessor, err := meta.Accessor(object)
+	if err != nil {
+		return
+	}
+	if !r.allow(fmt.Sprintf("%s/%s/%s", accessor.GetUID(), reason, note)) {
+		return
+	}
+	r.recorder.Eventf(object, nil, eventType, reason, action, "%s", note)
+}
+
+// allow reports whether the event with the key was not emitted within the interval
+func (r *EventRecorder) allow(key string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for k, t := range r.emitted {
+		if now.Sub(t) >= r.interval {
+			delete(r.emitted, k)
+		}
+	}
+	if _, ok := r.emitted[key]; ok {
+		return false
+	}
+	r.emitted[key] = now
+	return true
+}
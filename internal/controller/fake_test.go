@@ -0,0 +1,243 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/common"
+	"github.com/zncdatadev/hdfs-operator/internal/constant"
+	pkgclient "github.com/zncdatadev/operator-go/pkg/client"
+	opconstants "github.com/zncdatadev/operator-go/pkg/constants"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testNamespace = "default"
+
+// newTestCluster returns a cluster with a role group of each role
+func newTestCluster(nameNodes, dataNodes, journalNodes int32) *hdfsv1alpha1.HdfsCluster {
+	roleSpec := func(replicas int32) hdfsv1alpha1.RoleSpec {
+		return hdfsv1alpha1.RoleSpec{
+			RoleGroups: map[string]hdfsv1alpha1.RoleGroupSpec{"default": {Replicas: ptr.To(replicas)}},
+		}
+	}
+	journalNode := roleSpec(journalNodes)
+	return &hdfsv1alpha1.HdfsCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "hdfs", Namespace: testNamespace, UID: types.UID("hdfs-uid")},
+		Spec: hdfsv1alpha1.HdfsClusterSpec{
+			ClusterConfig: &hdfsv1alpha1.ClusterConfigSpec{
+				ZookeeperConfigMapName: "zk",
+				ClusterDomain:          "cluster.local",
+			},
+			NameNode:    &hdfsv1alpha1.NameNodeSpec{RoleSpec: roleSpec(nameNodes)},
+			DataNode:    &hdfsv1alpha1.DataNodeSpec{RoleSpec: roleSpec(dataNodes)},
+			JournalNode: &journalNode,
+		},
+	}
+}
+
+// newFakeClient returns a client with the cluster and the objects, the status of the cluster is a subresource
+func newFakeClient(t *testing.T, instance *hdfsv1alpha1.HdfsCluster, objects ...ctrlclient.Object) *pkgclient.Client {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := hdfsv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	client := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(append([]ctrlclient.Object{instance}, objects...)...).
+		WithStatusSubresource(&hdfsv1alpha1.HdfsCluster{}).
+		Build()
+	return pkgclient.NewClient(client, instance)
+}
+
+func newTestRecorder() (*EventRecorder, *events.FakeRecorder) {
+	fakeRecorder := events.NewFakeRecorder(20)
+	return NewEventRecorder(fakeRecorder, DefaultEventInterval), fakeRecorder
+}
+
+// eventReasons returns the reasons of the events recorded so far
+func eventReasons(recorder *events.FakeRecorder) []string {
+	var reasons []string
+	for {
+		select {
+		case event := <-recorder.Events:
+			// the events are recorded as "<type> <reason> <note>"
+			reasons = append(reasons, strings.Fields(event)[1])
+		default:
+			return reasons
+		}
+	}
+}
+
+func testClusterInfo(instance *hdfsv1alpha1.HdfsCluster) reconciler.ClusterInfo {
+	return reconciler.ClusterInfo{
+		GVK: &metav1.GroupVersionKind{
+			Group:   hdfsv1alpha1.GroupVersion.Group,
+			Version: hdfsv1alpha1.GroupVersion.Version,
+			Kind:    "HdfsCluster",
+		},
+		ClusterName: instance.Name,
+	}
+}
+
+// testPod returns a running and ready pod of the default role group, created at the given time.
+// The address is used by the fake JMX client.
+func testPod(role constant.Role, index int, created time.Time) *corev1.Pod {
+	name := fmt.Sprintf("hdfs-%s-default-%d", role, index)
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         testNamespace,
+			UID:               types.UID(name),
+			CreationTimestamp: metav1.NewTime(created),
+			Labels: map[string]string{
+				opconstants.LabelKubernetesInstance:  "hdfs",
+				opconstants.LabelKubernetesComponent: string(role),
+				opconstants.LabelKubernetesRoleGroup: "default",
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			PodIP: testPodIP(name),
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+}
+
+func testPodIP(name string) string {
+	index := name[strings.LastIndex(name, "-")+1:]
+	switch {
+	case strings.Contains(name, string(constant.NameNode)):
+		return "10.0.1." + index
+	case strings.Contains(name, string(constant.DataNode)):
+		return "10.0.2." + index
+	default:
+		return "10.0.3." + index
+	}
+}
+
+// testStatefulSet returns the StatefulSet of the default role group of the role with the ready replicas
+func testStatefulSet(role constant.Role, replicas, ready int32) *appsv1.StatefulSet {
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("hdfs-%s-default", role),
+			Namespace: testNamespace,
+			UID:       types.UID(fmt.Sprintf("hdfs-%s-default", role)),
+			Labels: map[string]string{
+				opconstants.LabelKubernetesInstance:  "hdfs",
+				opconstants.LabelKubernetesComponent: string(role),
+				opconstants.LabelKubernetesRoleGroup: "default",
+			},
+		},
+		Spec:   appsv1.StatefulSetSpec{Replicas: ptr.To(replicas)},
+		Status: appsv1.StatefulSetStatus{ReadyReplicas: ready},
+	}
+}
+
+// testJob returns a job which completed or failed at the given time, it is running if the time is zero
+func testJob(name string, failed bool, finished time.Time) *batchv1.Job {
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace}}
+	if finished.IsZero() {
+		job.Status.Active = 1
+		return job
+	}
+	condition := batchv1.JobCondition{
+		Type:               batchv1.JobComplete,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(finished),
+	}
+	if failed {
+		condition.Type = batchv1.JobFailed
+		condition.Message = "BackoffLimitExceeded"
+	} else {
+		job.Status.CompletionTime = ptr.To(metav1.NewTime(finished))
+	}
+	job.Status.Conditions = []batchv1.JobCondition{condition}
+	return job
+}
+
+// getCluster reads the cluster back from the client
+func getCluster(t *testing.T, client *pkgclient.Client) *hdfsv1alpha1.HdfsCluster {
+	t.Helper()
+	instance := &hdfsv1alpha1.HdfsCluster{}
+	if err := client.Client.Get(context.Background(), ctrlclient.ObjectKey{Namespace: testNamespace, Name: "hdfs"}, instance); err != nil {
+		t.Fatal(err)
+	}
+	return instance
+}
+
+// jobExists reports whether the job was created
+func jobExists(t *testing.T, client *pkgclient.Client, name string) bool {
+	t.Helper()
+	err := client.Client.Get(context.Background(), ctrlclient.ObjectKey{Namespace: testNamespace, Name: name}, &batchv1.Job{})
+	if err != nil && ctrlclient.IgnoreNotFound(err) != nil {
+		t.Fatal(err)
+	}
+	return err == nil
+}
+
+// podExists reports whether the pod was not deleted
+func podExists(t *testing.T, client *pkgclient.Client, name string) bool {
+	t.Helper()
+	err := client.Client.Get(context.Background(), ctrlclient.ObjectKey{Namespace: testNamespace, Name: name}, &corev1.Pod{})
+	if err != nil && ctrlclient.IgnoreNotFound(err) != nil {
+		t.Fatal(err)
+	}
+	return err == nil
+}
+
+// fakeJmx answers the FSNamesystem bean of the namenodes with their HA state, by the address of the pod.
+// Pods without a state are not reachable.
+type fakeJmx map[string]string
+
+func (f fakeJmx) RoundTrip(request *http.Request) (*http.Response, error) {
+	host, _, err := net.SplitHostPort(request.URL.Host)
+	if err != nil {
+		return nil, err
+	}
+	for name, state := range f {
+		if testPodIP(name) == host {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Status:     "200 OK",
+				Body:       io.NopCloser(strings.NewReader(fmt.Sprintf(`{"beans":[{"tag.HAState":%q}]}`, state))),
+				Request:    request,
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("connection refused")
+}
+
+// setFakeJmx replaces the JMX client with one answering the HA states of the namenodes by pod name
+func setFakeJmx(t *testing.T, jmx **JmxClient, states map[string]string) {
+	t.Helper()
+	previous := *jmx
+	*jmx = &JmxClient{httpClient: &http.Client{Transport: fakeJmx(states)}}
+	t.Cleanup(func() { *jmx = previous })
+}
+
+// testImage is the image of the jobs
+var testImage = common.SpecImage(nil)
@@ -20,12 +20,18 @@ import (
 	"context"
 
 	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/common"
 	"github.com/zncdatadev/operator-go/pkg/client"
 	"github.com/zncdatadev/operator-go/pkg/reconciler"
 )
@@ -35,8 +41,9 @@ var logger = ctrl.Log.WithName("hdfscluster-controller")
 // HdfsClusterReconciler reconciles a HdfsCluster object
 type HdfsClusterReconciler struct {
 	ctrlclient.Client
-	Scheme *runtime.Scheme
-	Log    logr.Logger
+	Scheme   *runtime.Scheme
+	Log      logr.Logger
+	Recorder *EventRecorder
 }
 
 // +kubebuilder:rbac:groups=listeners.kubedoop.dev,resources=listeners,verbs=get;list;watch;create;update;patch;delete
@@ -44,12 +51,17 @@ type HdfsClusterReconciler struct {
 // +kubebuilder:rbac:groups=hdfs.kubedoop.dev,resources=hdfsclusters/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=hdfs.kubedoop.dev,resources=hdfsclusters/finalizers,verbs=update
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;delete
 // +kubebuilder:rbac:groups=authentication.kubedoop.dev,resources=authenticationclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=s3.kubedoop.dev,resources=s3buckets;s3connections,verbs=get;list;watch
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors;prometheusrules,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -92,12 +104,21 @@ func (r *HdfsClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 			ClusterName: instance.Name,
 		},
 		instance,
+		r.Recorder,
 	)
+	// a paused cluster is left as it is, for manual maintenance of the managed objects
+	if clusterReconciler.IsPaused(ctx) {
+		r.Recorder.Normal(instance, EventReasonReconciliationPaused, "Reconcile", "Reconciliation is paused")
+		return ctrl.Result{}, nil
+	}
+
 	if err := clusterReconciler.RegisterResources(ctx); err != nil {
+		r.reconcileFailed(instance, "register resources", err)
 		return ctrl.Result{}, err
 	}
 
 	if result, err := clusterReconciler.Reconcile(ctx); err != nil {
+		r.reconcileFailed(instance, "reconcile resources", err)
 		return ctrl.Result{}, err
 	} else if !result.IsZero() {
 		return result, nil
@@ -106,6 +127,7 @@ func (r *HdfsClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	logger.Info("Cluster resource reconciled, checking if ready.", "cluster", instance.Name, "namespace", instance.Namespace)
 
 	if result, err := clusterReconciler.Ready(ctx); err != nil {
+		r.reconcileFailed(instance, "check readiness", err)
 		return ctrl.Result{}, err
 	} else if !result.IsZero() {
 		return result, nil
@@ -116,9 +138,43 @@ func (r *HdfsClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	return ctrl.Result{}, nil
 }
 
+// reconcileFailed emits an event with the cause of a failed reconcile. Conflicts are retried
+// immediately and are not reported.
+func (r *HdfsClusterReconciler) reconcileFailed(instance *hdfsv1alpha1.HdfsCluster, step string, err error) {
+	if apierrors.IsConflict(err) {
+		return
+	}
+	r.Recorder.Warning(instance, EventReasonReconcileFailed, "Reconcile", "Failed to %s: %s", step, err)
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *HdfsClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&hdfsv1alpha1.HdfsCluster{}).
+		Owns(&batchv1.Job{}).
+		Owns(&batchv1.CronJob{}).
+		// the pods are restarted when the keystore password of jksPasswordSecretRef is rotated,
+		// only the metadata of the Secrets is cached
+		WatchesMetadata(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.jksPasswordSecretRequests)).
 		Complete(r)
 }
+
+// jksPasswordSecretRequests maps a Secret to the clusters in its namespace which read the keystore password from it
+func (r *HdfsClusterReconciler) jksPasswordSecretRequests(ctx context.Context, obj ctrlclient.Object) []reconcile.Request {
+	clusters := &hdfsv1alpha1.HdfsClusterList{}
+	if err := r.List(ctx, clusters, ctrlclient.InNamespace(obj.GetNamespace())); err != nil {
+		logger.Error(err, "failed to list clusters of secret", "namespace", obj.GetNamespace(), "name", obj.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, cluster := range clusters.Items {
+		clusterConfig := cluster.Spec.ClusterConfig
+		if clusterConfig == nil || !common.IsTlsEnabled(clusterConfig) {
+			continue
+		}
+		if secretRef := clusterConfig.Authentication.Tls.JksPasswordSecretRef; secretRef != nil && secretRef.Name == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: ctrlclient.ObjectKeyFromObject(&cluster)})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2024 zncdatadev.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/common"
+	"github.com/zncdatadev/hdfs-operator/internal/controller/replication"
+	"github.com/zncdatadev/operator-go/pkg/client"
+)
+
+var replicationLogger = ctrl.Log.WithName("hdfsreplication-controller")
+
+// HdfsReplicationReconciler reconciles a HdfsReplication object
+type HdfsReplicationReconciler struct {
+	ctrlclient.Client
+	Scheme *runtime.Scheme
+	Log    logr.Logger
+}
+
+// +kubebuilder:rbac:groups=hdfs.kubedoop.dev,resources=hdfsreplications,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=hdfs.kubedoop.dev,resources=hdfsreplications/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=hdfs.kubedoop.dev,resources=hdfsreplications/finalizers,verbs=update
+
+// Reconcile creates the DistCp CronJob of a HdfsReplication and reports the progress of its jobs.
+func (r *HdfsReplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	replicationLogger.V(1).Info("Reconciling HdfsReplication")
+
+	instance := &hdfsv1alpha1.HdfsReplication{}
+	if err := r.Get(ctx, req.NamespacedName, instance); err != nil {
+		if ctrlclient.IgnoreNotFound(err) == nil {
+			replicationLogger.V(1).Info("HdfsReplication not found, may have been deleted")
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	replicationLogger.V(1).Info("HdfsReplication found", "namespace", instance.Namespace, "name", instance.Name)
+
+	resourceClient := &client.Client{
+		Client:         r.Client,
+		OwnerReference: instance,
+	}
+
+	return replication.NewReconciler(resourceClient, instance, common.SpecImage(instance.Spec.Image)).Reconcile(ctx)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *HdfsReplicationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&hdfsv1alpha1.HdfsReplication{}).
+		Owns(&corev1.ConfigMap{}).
+		Owns(&batchv1.CronJob{}).
+		// the jobs are owned by the CronJob, they are mapped to their replication by its label
+		Watches(&batchv1.Job{}, handler.EnqueueRequestsFromMapFunc(replicationJobRequests)).
+		Complete(r)
+}
+
+// replicationJobRequests maps a job of the CronJob of a replication to the replication
+func replicationJobRequests(_ context.Context, obj ctrlclient.Object) []reconcile.Request {
+	name, ok := obj.GetLabels()[replication.LabelReplication]
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: name}}}
+}
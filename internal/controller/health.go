@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/prometheus/client_golang/prometheus"
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	healthProbeSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hdfs_cluster_health_probe_success",
+		Help: "Whether the last health probe read the metrics of the active namenode of the cluster.",
+	}, []string{"namespace", "cluster"})
+	healthDataNodes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hdfs_cluster_datanodes",
+		Help: "Number of datanodes of the cluster by state, live or dead.",
+	}, []string{"namespace", "cluster", "state"})
+	healthMissingBlocks = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hdfs_cluster_missing_blocks",
+		Help: "Number of blocks of the cluster without any replica.",
+	}, []string{"namespace", "cluster"})
+	healthUnderReplicatedBlocks = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hdfs_cluster_under_replicated_blocks",
+		Help: "Number of blocks of the cluster with too few replicas.",
+	}, []string{"namespace", "cluster"})
+	healthCapacityTotal = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hdfs_cluster_capacity_total_bytes",
+		Help: "Configured capacity of the datanodes of the cluster.",
+	}, []string{"namespace", "cluster"})
+	healthCapacityUsed = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hdfs_cluster_capacity_used_bytes",
+		Help: "DFS used space of the datanodes of the cluster.",
+	}, []string{"namespace", "cluster"})
+	healthManualSafeMode = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hdfs_cluster_manual_safe_mode",
+		Help: "Whether the active namenode of the cluster is in a safe mode turned on manually.",
+	}, []string{"namespace", "cluster"})
+	healthNameNodeState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hdfs_cluster_namenode_ha_state",
+		Help: "HA state of the namenode pods of the cluster, 1 for the current state of a pod.",
+	}, []string{"namespace", "cluster", "pod", "state"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		healthProbeSuccess,
+		healthDataNodes,
+		healthMissingBlocks,
+		healthUnderReplicatedBlocks,
+		healthCapacityTotal,
+		healthCapacityUsed,
+		healthManualSafeMode,
+		healthNameNodeState,
+	)
+}
+
+// ClusterHealth exports the health of the clusters read by the ClusterObserver as metrics of the operator
+// and records it in the status of the clusters, so a single scrape of the operator covers all clusters.
+type ClusterHealth struct {
+	client ctrlclient.Client
+}
+
+func NewClusterHealth(client ctrlclient.Client) *ClusterHealth {
+	return &ClusterHealth{client: client}
+}
+
+// record exports the HA states of the namenode pods and the namesystem of the active namenode.
+// The namesystem is nil when no active namenode was read, probeErr is the last failed read. The info
+// of the active namenode is nil if it was not read.
+func (h *ClusterHealth) record(
+	ctx context.Context,
+	instance *hdfsv1alpha1.HdfsCluster,
+	states map[string]string,
+	active *corev1.Pod,
+	namesystem *FSNamesystem,
+	info *NameNodeInfo,
+	probeErr error,
+) error {
+	clusterLabels := prometheus.Labels{"namespace": instance.Namespace, "cluster": instance.Name}
+
+	healthNameNodeState.DeletePartialMatch(clusterLabels)
+	for pod, state := range states {
+		healthNameNodeState.WithLabelValues(instance.Namespace, instance.Name, pod, state).Set(1)
+	}
+
+	health := &hdfsv1alpha1.HealthStatus{}
+	if len(states) > 0 {
+		health.NameNodes = states
+	}
+	if namesystem == nil {
+		healthProbeSuccess.With(clusterLabels).Set(0)
+		h.forgetNamesystem(clusterLabels)
+		health.Message = "no active namenode"
+		if probeErr != nil {
+			health.Message += ": " + probeErr.Error()
+		}
+	} else {
+		healthProbeSuccess.With(clusterLabels).Set(1)
+		healthDataNodes.WithLabelValues(instance.Namespace, instance.Name, "live").Set(float64(namesystem.NumLiveDataNodes))
+		healthDataNodes.WithLabelValues(instance.Namespace, instance.Name, "dead").Set(float64(namesystem.NumDeadDataNodes))
+		healthMissingBlocks.With(clusterLabels).Set(float64(namesystem.MissingBlocks))
+		healthUnderReplicatedBlocks.With(clusterLabels).Set(float64(namesystem.UnderReplicatedBlocks))
+		healthCapacityTotal.With(clusterLabels).Set(float64(namesystem.CapacityTotal))
+		healthCapacityUsed.With(clusterLabels).Set(float64(namesystem.CapacityUsed))
+		if info != nil {
+			manualSafeMode := 0.0
+			if info.ManualSafeMode() {
+				manualSafeMode = 1
+			}
+			healthManualSafeMode.With(clusterLabels).Set(manualSafeMode)
+		}
+
+		health.ActiveNameNode = active.Name
+		health.LiveDataNodes = namesystem.NumLiveDataNodes
+		health.DeadDataNodes = namesystem.NumDeadDataNodes
+		health.MissingBlocks = namesystem.MissingBlocks
+		health.UnderReplicatedBlocks = namesystem.UnderReplicatedBlocks
+		if namesystem.CapacityTotal > 0 {
+			health.CapacityUsedPercent = int32(namesystem.CapacityUsed * 100 / namesystem.CapacityTotal)
+		}
+	}
+
+	return h.updateStatus(ctx, instance, health)
+}
+
+// updateStatus records the health in the status of the cluster if it changed. The used capacity is recorded
+// in percent, so the status is not updated on every probe.
+func (h *ClusterHealth) updateStatus(ctx context.Context, instance *hdfsv1alpha1.HdfsCluster, health *hdfsv1alpha1.HealthStatus) error {
+	if current := instance.Status.Health; current != nil {
+		health.LastTransitionTime = current.LastTransitionTime
+		if reflect.DeepEqual(current, health) {
+			return nil
+		}
+	}
+	now := metav1.Now()
+	health.LastTransitionTime = &now
+
+	updated := instance.DeepCopy()
+	updated.Status.Health = health
+	return h.client.Status().Patch(ctx, updated, ctrlclient.MergeFrom(instance))
+}
+
+// forget removes the metrics of a deleted cluster
+func (h *ClusterHealth) forget(namespace string, name string) {
+	clusterLabels := prometheus.Labels{"namespace": namespace, "cluster": name}
+	healthProbeSuccess.DeletePartialMatch(clusterLabels)
+	healthNameNodeState.DeletePartialMatch(clusterLabels)
+	h.forgetNamesystem(clusterLabels)
+}
+
+func (h *ClusterHealth) forgetNamesystem(clusterLabels prometheus.Labels) {
+	healthDataNodes.DeletePartialMatch(clusterLabels)
+	healthMissingBlocks.DeletePartialMatch(clusterLabels)
+	healthUnderReplicatedBlocks.DeletePartialMatch(clusterLabels)
+	healthCapacityTotal.DeletePartialMatch(clusterLabels)
+	healthCapacityUsed.DeletePartialMatch(clusterLabels)
+	healthManualSafeMode.DeletePartialMatch(clusterLabels)
+}
@@ -73,7 +73,7 @@ func (b *JournalnodeConfigMapBuilder) BuildConfig() (map[string]string, error) {
 	data := map[string]string{
 		hdfsv1alpha1.CoreSiteFileName:     b.makeCoreSiteData(),
 		hdfsv1alpha1.HdfsSiteFileName:     b.makeHdfsSiteData(),
-		hdfsv1alpha1.HadoopPolicyFileName: common.MakeHadoopPolicyData(),
+		hdfsv1alpha1.HadoopPolicyFileName: common.MakeHadoopPolicyData(b.instance.Spec.ClusterConfig),
 		hdfsv1alpha1.SecurityFileName:     common.MakeSecurityPropertiesData(),
 		hdfsv1alpha1.SslClientFileName:    common.MakeSslClientData(b.instance.Spec.ClusterConfig),
 		hdfsv1alpha1.SslServerFileName:    common.MakeSslServerData(b.instance.Spec.ClusterConfig),
@@ -95,7 +95,12 @@ func (b *JournalnodeConfigMapBuilder) GetConfigOverrides() map[string]map[string
 // makeCoreSiteData generates core-site.xml data for journalnode
 func (b *JournalnodeConfigMapBuilder) makeCoreSiteData() string {
 	generator := &common.CoreSiteXmlGenerator{InstanceName: b.instance.GetName()}
-	return generator.EnableKerberos(b.instance.Spec.ClusterConfig, b.instance.Namespace).HaZookeeperQuorum().Generate()
+	return generator.EnableKerberos(b.instance.Spec.ClusterConfig, b.instance.Namespace).
+		EnableServiceAuthorization(b.instance.Spec.ClusterConfig).
+		EnableProxyUsers(b.instance.Spec.ClusterConfig).
+		EnableMonitoring(b.instance.Spec.ClusterConfig).
+		HaZookeeperQuorum().
+		Generate()
 }
 
 // makeHdfsSiteData generates hdfs-site.xml data for journalnode
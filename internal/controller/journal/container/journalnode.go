@@ -51,7 +51,8 @@ func (b *JournalNodeContainerBuilder) Build() *corev1.Container {
 	)
 
 	// Create journalnode component and build container
-	component := newJournalNodeComponent(b.instance.Name, b.instance.Spec.ClusterConfig)
+	component := newJournalNodeComponent(b.instance.Name, b.instance.Namespace, b.instance.Spec.ClusterConfig,
+		common.GetRoleMetrics(b.instance, constant.JournalNode))
 
 	return builder.BuildWithComponent(component)
 }
@@ -59,18 +60,28 @@ func (b *JournalNodeContainerBuilder) Build() *corev1.Container {
 // journalNodeComponent implements ContainerComponentInterface for JournalNode
 type journalNodeComponent struct {
 	clusterName   string
+	namespace     string
 	clusterConfig *hdfsv1alpha1.ClusterConfigSpec
+	metrics       *hdfsv1alpha1.MetricsSpec
 }
 
 // Ensure journalNodeComponent implements all required interfaces
 var _ common.ContainerComponentInterface = &journalNodeComponent{}
 var _ common.ContainerPortsProvider = &journalNodeComponent{}
 var _ common.ContainerHealthCheckProvider = &journalNodeComponent{}
-
-func newJournalNodeComponent(clusterName string, clusterConfig *hdfsv1alpha1.ClusterConfigSpec) *journalNodeComponent {
+var _ common.ContainerStartupProbeProvider = &journalNodeComponent{}
+
+func newJournalNodeComponent(
+	clusterName string,
+	namespace string,
+	clusterConfig *hdfsv1alpha1.ClusterConfigSpec,
+	metrics *hdfsv1alpha1.MetricsSpec,
+) *journalNodeComponent {
 	return &journalNodeComponent{
 		clusterName:   clusterName,
+		namespace:     namespace,
 		clusterConfig: clusterConfig,
+		metrics:       metrics,
 	}
 }
 
@@ -89,6 +100,11 @@ cp /kubedoop/mount/config/journalnode/*.xml /kubedoop/config/journalnode
 cp /kubedoop/mount/config/journalnode/journalnode.log4j.properties /kubedoop/config/journalnode/log4j.properties`,
 	}
 
+	// Substitute the keystore password if TLS is enabled
+	if common.IsTlsEnabled(c.clusterConfig) {
+		args = append(args, common.SubstituteJksPasswordScript(c.clusterConfig.Authentication.Tls))
+	}
+
 	// Add Kerberos configuration if enabled
 	if common.IsKerberosEnabled(c.clusterConfig) {
 		args = append(args, `{{ if .kerberosEnabled}}
@@ -115,7 +131,7 @@ cp /kubedoop/mount/config/journalnode/journalnode.log4j.properties /kubedoop/con
 
 // GetEnvVars returns environment variables for journalnode
 func (c *journalNodeComponent) GetEnvVars() []corev1.EnvVar {
-	return common.GetCommonContainerEnv(c.clusterConfig, constant.JournalNodeComponent, ptr.To(constant.JournalNode))
+	return common.GetCommonContainerEnv(c.clusterConfig, constant.JournalNodeComponent, ptr.To(constant.JournalNode), c.metrics)
 }
 
 // GetVolumeMounts returns volume mounts for journalnode
@@ -164,7 +180,8 @@ func (c *journalNodeComponent) GetLivenessProbe() *corev1.Probe {
 		SuccessThreshold:    1,
 		TimeoutSeconds:      1,
 		ProbeHandler: corev1.ProbeHandler{
-			HTTPGet: common.TlsHttpGetAction(c.clusterConfig, "/journalnode.html"),
+			// the liveness does not depend on the KDC, a journalnode which can not get a ticket is only not ready
+			TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromString(hdfsv1alpha1.RpcName)},
 		},
 	}
 }
@@ -175,9 +192,31 @@ func (c *journalNodeComponent) GetReadinessProbe() *corev1.Probe {
 		InitialDelaySeconds: 10,
 		PeriodSeconds:       10,
 		SuccessThreshold:    1,
-		TimeoutSeconds:      1,
+		TimeoutSeconds:      10,
+		ProbeHandler: corev1.ProbeHandler{
+			// the journal is loaded once a namenode wrote to it, its last written txid must be readable
+			Exec: c.jmxProbeAction("Hadoop:service=JournalNode,name=Journal-"+c.clusterName, `grep -qP '"LastWrittenTxId"\s*:\s*\d+' <<< "$JMX"`),
+		},
+	}
+}
+
+// GetStartupProbe reports the journalnode as started once it accepts connections, the bring-up waits for the
+// journalnodes to be started, as they are not ready before the namenodes wrote to their journals
+func (c *journalNodeComponent) GetStartupProbe() *corev1.Probe {
+	return &corev1.Probe{
+		FailureThreshold:    30,
+		InitialDelaySeconds: 10,
+		PeriodSeconds:       10,
+		TimeoutSeconds:      5,
 		ProbeHandler: corev1.ProbeHandler{
 			TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromString(hdfsv1alpha1.RpcName)},
 		},
 	}
 }
+
+// jmxProbeAction reads a bean of the journalnode with its keytab
+func (c *journalNodeComponent) jmxProbeAction(bean string, condition string) *corev1.ExecAction {
+	port := common.HttpPort(c.clusterConfig, hdfsv1alpha1.JournalNodeHttpsPort, hdfsv1alpha1.JournalNodeHttpPort).ContainerPort
+	check := common.JmxCheck(c.clusterConfig, c.clusterName, c.namespace, port, bean, condition)
+	return common.NewExecProbeAction(c.clusterConfig, c.clusterName, c.namespace, constant.JournalNode, check)
+}
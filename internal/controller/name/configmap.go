@@ -6,6 +6,7 @@ import (
 	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
 	"github.com/zncdatadev/hdfs-operator/internal/common"
 	"github.com/zncdatadev/hdfs-operator/internal/constant"
+	authv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/authentication/v1alpha1"
 	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
 	"github.com/zncdatadev/operator-go/pkg/builder"
 	"github.com/zncdatadev/operator-go/pkg/client"
@@ -19,6 +20,8 @@ var _ common.ConfigMapComponentBuilder = &NamenodeConfigMapBuilder{}
 // NamenodeConfigMapBuilder implements namenode-specific ConfigMap logic
 type NamenodeConfigMapBuilder struct {
 	*common.ConfigMapBuilder
+	ctx                  context.Context
+	client               *client.Client
 	instance             *hdfsv1alpha1.HdfsCluster
 	groupName            string
 	replicas             *int32
@@ -39,6 +42,8 @@ func NewNamenodeConfigMapBuilder(
 	clusterComponentInfo *common.ClusterComponentsInfo,
 ) builder.ConfigBuilder {
 	configMapBuilder := &NamenodeConfigMapBuilder{
+		ctx:                  ctx,
+		client:               client,
 		instance:             instance,
 		groupName:            roleGroupInfo.GetGroupName(),
 		replicas:             replicas,
@@ -70,10 +75,23 @@ func (b *NamenodeConfigMapBuilder) Build(ctx context.Context) (ctrlclient.Object
 
 // BuildConfig returns namenode-specific configuration content
 func (b *NamenodeConfigMapBuilder) BuildConfig() (map[string]string, error) {
+	var opaUrl string
+	clusterConfig := b.instance.Spec.ClusterConfig
+	if common.IsOpaEnabled(clusterConfig) {
+		var err error
+		if opaUrl, err = common.ResolveOpaUrl(b.ctx, b.client, b.instance.Namespace, clusterConfig.Authorization.Opa); err != nil {
+			return nil, err
+		}
+	}
+	ldapProvider, err := common.ResolveLdapGroupMapping(b.ctx, b.client, clusterConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	data := map[string]string{
-		hdfsv1alpha1.CoreSiteFileName:     b.makeCoreSiteData(),
-		hdfsv1alpha1.HdfsSiteFileName:     b.makeHdfsSiteData(),
-		hdfsv1alpha1.HadoopPolicyFileName: common.MakeHadoopPolicyData(),
+		hdfsv1alpha1.CoreSiteFileName:     b.makeCoreSiteData(opaUrl, ldapProvider),
+		hdfsv1alpha1.HdfsSiteFileName:     b.makeHdfsSiteData(opaUrl),
+		hdfsv1alpha1.HadoopPolicyFileName: common.MakeHadoopPolicyData(b.instance.Spec.ClusterConfig),
 		hdfsv1alpha1.SecurityFileName:     common.MakeSecurityPropertiesData(),
 		hdfsv1alpha1.SslClientFileName:    common.MakeSslClientData(b.instance.Spec.ClusterConfig),
 		hdfsv1alpha1.SslServerFileName:    common.MakeSslServerData(b.instance.Spec.ClusterConfig),
@@ -83,6 +101,13 @@ func (b *NamenodeConfigMapBuilder) BuildConfig() (map[string]string, error) {
 		common.CreateComponentLog4jPropertiesName(constant.FormatNameNodeComponent):  common.MakeLog4jPropertiesData(constant.FormatNameNodeComponent),
 		common.CreateComponentLog4jPropertiesName(constant.FormatZookeeperComponent): common.MakeLog4jPropertiesData(constant.FormatZookeeperComponent),
 	}
+	if common.IsProxyUsersEnabled(clusterConfig) {
+		data[hdfsv1alpha1.ProxyUsersFileName] = common.MakeProxyUsersData(clusterConfig)
+	}
+	if auditLog := common.GetAuditLog(b.instance); auditLog != nil {
+		log4jName := common.CreateComponentLog4jPropertiesName(constant.NameNodeComponent)
+		data[log4jName] += common.MakeAuditLog4jProperties(auditLog)
+	}
 
 	return data, nil
 }
@@ -98,17 +123,24 @@ func (b *NamenodeConfigMapBuilder) GetConfigOverrides() map[string]map[string]st
 // Helper methods for configuration generation
 
 // make core-site.xml data
-func (b *NamenodeConfigMapBuilder) makeCoreSiteData() string {
+func (b *NamenodeConfigMapBuilder) makeCoreSiteData(opaUrl string, ldapProvider *authv1alpha1.LDAPProvider) string {
 	generator := &common.CoreSiteXmlGenerator{InstanceName: b.instance.GetName()}
-	return generator.EnableKerberos(b.instance.Spec.ClusterConfig, b.instance.Namespace).HaZookeeperQuorum().Generate()
+	return generator.EnableKerberos(b.instance.Spec.ClusterConfig, b.instance.Namespace).
+		EnableOpa(b.instance.Spec.ClusterConfig, opaUrl).
+		EnableLdapGroupMapping(b.instance.Spec.ClusterConfig, ldapProvider).
+		EnableServiceAuthorization(b.instance.Spec.ClusterConfig).
+		IncludeProxyUsers(b.instance.Spec.ClusterConfig).
+		EnableMonitoring(b.instance.Spec.ClusterConfig).
+		HaZookeeperQuorum().
+		Generate()
 }
 
 // make hdfs-site.xml data
-func (b *NamenodeConfigMapBuilder) makeHdfsSiteData() string {
+func (b *NamenodeConfigMapBuilder) makeHdfsSiteData(opaUrl string) string {
 	clusterSpec := b.instance.Spec.ClusterConfig
 	// Create ClusterComponentsInfo for the updated generator
 	generator := common.NewNameNodeHdfsSiteXmlGenerator(b.instance.GetName(), b.groupName,
 		*b.replicas, b.instance.Namespace, b.instance.Spec.ClusterConfig, clusterSpec.ClusterDomain,
 		clusterSpec.DfsReplication, b.clusterComponentInfo)
-	return generator.EnablerKerberos(clusterSpec).EnableHttps().Generate()
+	return generator.EnablerKerberos(clusterSpec).EnableHttps().EnableOpa(opaUrl).Generate()
 }
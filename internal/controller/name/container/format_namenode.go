@@ -26,6 +26,8 @@ type FormatNameNodeContainerBuilder struct {
 	image              *oputil.Image
 	nameNodeReplicates int32
 	statefulSetName    string
+	s3Target           *common.S3Target
+	bootstrapS3Target  *common.S3Target
 }
 
 // NewFormatNameNodeContainerBuilder creates a new format namenode container builder
@@ -36,6 +38,8 @@ func NewFormatNameNodeContainerBuilder(
 	image *oputil.Image,
 	nameNodeReplicates int32,
 	namenodeStsName string,
+	s3Target *common.S3Target,
+	bootstrapS3Target *common.S3Target,
 ) *FormatNameNodeContainerBuilder {
 	return &FormatNameNodeContainerBuilder{
 		instance:           instance,
@@ -44,6 +48,8 @@ func NewFormatNameNodeContainerBuilder(
 		image:              image,
 		nameNodeReplicates: nameNodeReplicates,
 		statefulSetName:    namenodeStsName,
+		s3Target:           s3Target,
+		bootstrapS3Target:  bootstrapS3Target,
 	}
 }
 
@@ -59,7 +65,7 @@ func (b *FormatNameNodeContainerBuilder) Build() *corev1.Container {
 	)
 
 	// Create format namenode component and build container
-	component := newFormatNameNodeComponent(b.instance, b.nameNodeReplicates, b.statefulSetName)
+	component := newFormatNameNodeComponent(b.instance, b.nameNodeReplicates, b.statefulSetName, b.s3Target, b.bootstrapS3Target)
 
 	return builder.BuildWithComponent(component)
 }
@@ -69,16 +75,26 @@ type formatNameNodeComponent struct {
 	instance           *hdfsv1alpha1.HdfsCluster
 	nameNodeReplicates int32
 	statefulSetName    string
+	s3Target           *common.S3Target
+	bootstrapS3Target  *common.S3Target
 }
 
 // Only implement the required interface - no ports or health checks needed
 var _ common.ContainerComponentInterface = &formatNameNodeComponent{}
 
-func newFormatNameNodeComponent(instance *hdfsv1alpha1.HdfsCluster, nameNodeReplicates int32, statefulSetName string) *formatNameNodeComponent {
+func newFormatNameNodeComponent(
+	instance *hdfsv1alpha1.HdfsCluster,
+	nameNodeReplicates int32,
+	statefulSetName string,
+	s3Target *common.S3Target,
+	bootstrapS3Target *common.S3Target,
+) *formatNameNodeComponent {
 	return &formatNameNodeComponent{
 		instance:           instance,
 		nameNodeReplicates: nameNodeReplicates,
 		statefulSetName:    statefulSetName,
+		s3Target:           s3Target,
+		bootstrapS3Target:  bootstrapS3Target,
 	}
 }
 
@@ -96,7 +112,7 @@ func (c *formatNameNodeComponent) GetArgs() []string {
 	tmpl := `mkdir -p /kubedoop/config/format-namenodes
 cp /kubedoop/mount/config/format-namenodes/*.xml /kubedoop/config/format-namenodes
 cp /kubedoop/mount/config/format-namenodes/format-namenodes.log4j.properties /kubedoop/config/format-namenodes/log4j.properties
-
+{{ .substituteJksPassword }}
 {{ if .kerberosEnabled }}
 {{- .kerberosEnv }}
 
@@ -104,7 +120,13 @@ cp /kubedoop/mount/config/format-namenodes/format-namenodes.log4j.properties /ku
 
 {{- end }}
 
+{{ if .bootstrapEnabled }}
+{{- .bootstrapScript }}
+{{- else if .restoreEnabled }}
+{{- .restoreScript }}
+{{- end }}
 
+# the termination message reports a format to the operator, which emits an event for it
 echo "Start formatting namenode $POD_NAME. Checking for active namenodes:"
 ` + fmt.Sprintf("for namenode_id in %s", namenodeIds) + "\n" +
 		`do
@@ -123,11 +145,23 @@ if [ ! -f "/kubedoop/data/namenode/current/VERSION" ]
 then
     if [ -z ${ACTIVE_NAMENODE+x} ]
     then
+{{- if .bootstrapEnabled }}
+        echo "Bootstrap pod $POD_NAME as active namenode from existing metadata."
+        bootstrap_from_image
+        echo "bootstrapped as active namenode from existing metadata" > /dev/termination-log
+{{- else if .restoreEnabled }}
+        echo "Restore pod $POD_NAME as active namenode from backup."
+        restore_namenode
+        echo "restored as active namenode from backup" > /dev/termination-log
+{{- else }}
         echo "Create pod $POD_NAME as active namenode."
         /kubedoop/hadoop/bin/hdfs namenode -format -noninteractive
+        echo "formatted as active namenode" > /dev/termination-log
+{{- end }}
     else
         echo "Create pod $POD_NAME as standby namenode."
         /kubedoop/hadoop/bin/hdfs namenode -bootstrapStandby -nonInteractive
+        echo "formatted as standby namenode of $ACTIVE_NAMENODE" > /dev/termination-log
     fi
 else
     cat "/kubedoop/data/namenode/current/VERSION"
@@ -135,11 +169,120 @@ else
 fi
 `
 	data := common.CreateExportKrbRealmEnvData(c.instance.Spec.ClusterConfig)
-	principal := common.CreateKerberosPrincipal(c.instance.Name, c.instance.Namespace, constant.NameNode)
+	principal := common.CreateKerberosPrincipal(c.instance.Spec.ClusterConfig, c.instance.Name, c.instance.Namespace, constant.NameNode)
 	maps.Copy(data, common.CreateGetKerberosTicketData(principal))
+	maps.Copy(data, common.CreateJksPasswordData(c.instance.Spec.ClusterConfig))
+	restoreEnabled := common.IsRestoreEnabled(c.instance.Spec.ClusterConfig)
+	data["restoreEnabled"] = restoreEnabled
+	if restoreEnabled {
+		data["restoreScript"] = c.restoreScript()
+	}
+	// bootstrapping from an existing image takes precedence over restoring a backup
+	fromImage := common.GetBootstrapFromImage(c.instance)
+	data["bootstrapEnabled"] = fromImage != nil
+	if fromImage != nil {
+		data["bootstrapScript"] = c.bootstrapScript(fromImage)
+	}
 	return common.ParseTemplate(tmpl, data)
 }
 
+const restoreNameNodeTemplate = `# restore_namenode formats the namenode with the ids recorded in the backup
+# and replaces the empty fsimage with the one from the backup
+restore_namenode() {
+{{ .fetchBackupScript }}
+    FSIMAGE=$(ls -1 "$RESTORE_DIR" | grep -E '^fsimage_[0-9]+$' | sort -t _ -k 2 -n | tail -n1 || true)
+    if [ -z "$FSIMAGE" ]
+    then
+        echo "No fsimage found in backup $BACKUP"
+        exit 1
+    fi
+    TXID=${FSIMAGE#fsimage_}
+
+    CLUSTER_ID=""
+    BLOCK_POOL_ID=""
+    if [ -f "$RESTORE_DIR/{{ .clusterPropertiesFile }}" ]
+    then
+        source "$RESTORE_DIR/{{ .clusterPropertiesFile }}"
+    fi
+{{- if .clusterId }}
+    CLUSTER_ID={{ .clusterId }}
+{{- end }}
+{{- if .blockPoolId }}
+    BLOCK_POOL_ID={{ .blockPoolId }}
+{{- end }}
+    if [ -z "$CLUSTER_ID" ]
+    then
+        echo "Backup $BACKUP does not record a cluster id, set clusterConfig.backup.restore.clusterId"
+        exit 1
+    fi
+
+    /kubedoop/hadoop/bin/hdfs namenode -format -force -nonInteractive -clusterid "$CLUSTER_ID"
+
+    /kubedoop/hadoop/bin/hdfs oiv -p XML -i "$RESTORE_DIR/$FSIMAGE" -o "$RESTORE_DIR/fsimage.xml"
+    NAMESPACE_ID=$(grep -m1 -oP '<namespaceId>\K[0-9]+' "$RESTORE_DIR/fsimage.xml")
+
+    CURRENT_DIR=/kubedoop/data/namenode/current
+    rm -f "$CURRENT_DIR"/fsimage_*
+    cp "$RESTORE_DIR/$FSIMAGE" "$CURRENT_DIR/$FSIMAGE"
+    (cd "$CURRENT_DIR" && md5sum -b "$FSIMAGE" > "$FSIMAGE.md5")
+    echo "$TXID" > "$CURRENT_DIR/seen_txid"
+    sed -i "s/^namespaceID=.*/namespaceID=$NAMESPACE_ID/" "$CURRENT_DIR/VERSION"
+    if [ -n "$BLOCK_POOL_ID" ]
+    then
+        sed -i "s/^blockpoolID=.*/blockpoolID=$BLOCK_POOL_ID/" "$CURRENT_DIR/VERSION"
+    fi
+    cat "$CURRENT_DIR/VERSION"
+
+    # the journalnodes were formatted with a new namespace id, format them again from the restored storage
+    /kubedoop/hadoop/bin/hdfs namenode -initializeSharedEdits -force -nonInteractive
+    rm -rf "$RESTORE_DIR"
+    echo "Restored backup $BACKUP at transaction $TXID"
+}
+`
+
+const bootstrapFromImageTemplate = `# bootstrap_from_image copies the metadata of an existing namenode, the VERSION file is kept
+# as is so that the cluster id and block pool id of the existing datanodes are still valid
+bootstrap_from_image() {
+{{ .fetchFsImageScript }}
+    if [ ! -f "$SOURCE_DIR/current/VERSION" ]
+    then
+        echo "No VERSION file found in the namenode metadata to bootstrap from"
+        exit 1
+    fi
+    cat "$SOURCE_DIR/current/VERSION"
+
+    NAME_DIR=/kubedoop/data/namenode
+    mkdir -p "$NAME_DIR"
+    rm -rf "$NAME_DIR/current"
+    mv "$SOURCE_DIR/current" "$NAME_DIR/current"
+    rm -f "$NAME_DIR/current/in_use.lock"
+    rm -rf "$SOURCE_DIR"
+
+    # seed the journalnodes with the edits of the existing namenode
+    /kubedoop/hadoop/bin/hdfs namenode -initializeSharedEdits -force -nonInteractive
+    echo "Bootstrapped namenode metadata at transaction $(cat "$NAME_DIR/current/seen_txid")"
+}
+`
+
+func (c *formatNameNodeComponent) bootstrapScript(fromImage *hdfsv1alpha1.FromImageSpec) string {
+	data := map[string]interface{}{
+		"fetchFsImageScript": common.FetchFsImageScript(fromImage, c.bootstrapS3Target, path.Join(constants.KubedoopDataDir, "namenode-bootstrap")),
+	}
+	return common.ParseTemplate(bootstrapFromImageTemplate, data)[0]
+}
+
+func (c *formatNameNodeComponent) restoreScript() string {
+	clusterConfig := c.instance.Spec.ClusterConfig
+	restore := clusterConfig.Backup.Restore
+	data := map[string]interface{}{
+		"fetchBackupScript":     common.FetchBackupScript(clusterConfig, c.s3Target, path.Join(constants.KubedoopDataDir, "namenode-restore")),
+		"clusterPropertiesFile": common.BackupClusterPropertiesFileName,
+		"clusterId":             restore.ClusterID,
+		"blockPoolId":           restore.BlockPoolID,
+	}
+	return common.ParseTemplate(restoreNameNodeTemplate, data)[0]
+}
+
 // func (f *FormatNameNodeContainerBuilder) CommandArgs() []string {
 // 	namenodeIds := strings.Join(f.PodNames(), " ")
 // 	tmpl := `mkdir -p /kubedoop/config/format-namenodes
@@ -189,7 +332,13 @@ fi
 // }
 
 func (c *formatNameNodeComponent) GetEnvVars() []corev1.EnvVar {
-	return common.GetCommonContainerEnv(c.instance.Spec.ClusterConfig, constant.FormatNameNodeComponent, nil)
+	envs := common.GetCommonContainerEnv(c.instance.Spec.ClusterConfig, constant.FormatNameNodeComponent, nil, nil)
+	if fromImage := common.GetBootstrapFromImage(c.instance); fromImage != nil {
+		envs = append(envs, common.BootstrapImageEnvVars(fromImage)...)
+	} else if common.IsRestoreEnabled(c.instance.Spec.ClusterConfig) {
+		envs = append(envs, common.BackupEnvVars(c.instance.Spec.ClusterConfig)...)
+	}
+	return envs
 }
 
 func (c *formatNameNodeComponent) GetVolumeMounts() []corev1.VolumeMount {
@@ -208,7 +357,14 @@ func (c *formatNameNodeComponent) GetVolumeMounts() []corev1.VolumeMount {
 			MountPath: constants.KubedoopDataDir,
 		},
 	}
-	return append(mounts, formatNameNodeMounts...)
+	mounts = append(mounts, formatNameNodeMounts...)
+	if common.IsRestoreEnabled(c.instance.Spec.ClusterConfig) {
+		mounts = append(mounts, common.BackupVolumeMounts(c.instance.Spec.ClusterConfig, c.s3Target, true)...)
+	}
+	if fromImage := common.GetBootstrapFromImage(c.instance); fromImage != nil {
+		mounts = append(mounts, common.BootstrapImageVolumeMounts(fromImage, c.bootstrapS3Target)...)
+	}
+	return mounts
 }
 
 func (c *formatNameNodeComponent) podNames() []string {
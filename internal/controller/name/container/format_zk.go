@@ -1,6 +1,7 @@
 package container
 
 import (
+	"maps"
 	"path"
 
 	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
@@ -75,7 +76,7 @@ func (c *formatZookeeperComponent) GetArgs() []string {
 	tmpl := `mkdir -p /kubedoop/config/format-zookeeper
 cp /kubedoop/mount/config/format-zookeeper/*.xml /kubedoop/config/format-zookeeper
 cp /kubedoop/mount/config/format-zookeeper/format-zookeeper.log4j.properties /kubedoop/config/format-zookeeper/log4j.properties
-
+{{ .substituteJksPassword }}
 {{ if .kerberosEnabled }}
 {{- .kerberosEnv }}
 {{- end }}
@@ -88,6 +89,8 @@ if [[ "0" -eq "$(echo $POD_NAME | sed -e 's/.*-//')" ]] ; then
     set -e
     if [[ $EXITCODE -eq 0 ]]; then
         echo "Successfully formatted"
+        # the termination message reports the format to the operator, which emits an event for it
+        echo "formatted the HA state in ZooKeeper" > /dev/termination-log
     elif [[ $EXITCODE -eq 2 ]]; then
         echo "ZNode already existed, did nothing"
     else
@@ -99,11 +102,13 @@ else
     echo "ZooKeeper already formatted!"
 fi
 `
-	return common.ParseTemplate(tmpl, common.CreateExportKrbRealmEnvData(c.clusterConfig))
+	data := common.CreateExportKrbRealmEnvData(c.clusterConfig)
+	maps.Copy(data, common.CreateJksPasswordData(c.clusterConfig))
+	return common.ParseTemplate(tmpl, data)
 }
 
 func (c *formatZookeeperComponent) GetEnvVars() []corev1.EnvVar {
-	return common.GetCommonContainerEnv(c.clusterConfig, constant.FormatZookeeperComponent, nil)
+	return common.GetCommonContainerEnv(c.clusterConfig, constant.FormatZookeeperComponent, nil, nil)
 }
 
 func (c *formatZookeeperComponent) GetVolumeMounts() []corev1.VolumeMount {
@@ -51,7 +51,8 @@ func (b *NameNodeContainerBuilder) Build() *corev1.Container {
 	)
 
 	// Create namenode component and build container
-	component := newNameNodeComponent(b.instance.Name, b.instance.Spec.ClusterConfig)
+	component := newNameNodeComponent(b.instance.Name, b.instance.Namespace, b.instance.Spec.ClusterConfig,
+		common.GetRoleMetrics(b.instance, constant.NameNode))
 
 	return builder.BuildWithComponent(component)
 }
@@ -59,7 +60,9 @@ func (b *NameNodeContainerBuilder) Build() *corev1.Container {
 // nameNodeComponent implements ContainerComponentInterface for NameNode
 type nameNodeComponent struct {
 	clusterName   string
+	namespace     string
 	clusterConfig *hdfsv1alpha1.ClusterConfigSpec
+	metrics       *hdfsv1alpha1.MetricsSpec
 }
 
 // Ensure nameNodeComponent implements all required interfaces
@@ -67,10 +70,17 @@ var _ common.ContainerComponentInterface = &nameNodeComponent{}
 var _ common.ContainerPortsProvider = &nameNodeComponent{}
 var _ common.ContainerHealthCheckProvider = &nameNodeComponent{}
 
-func newNameNodeComponent(clusterName string, clusterConfig *hdfsv1alpha1.ClusterConfigSpec) *nameNodeComponent {
+func newNameNodeComponent(
+	clusterName string,
+	namespace string,
+	clusterConfig *hdfsv1alpha1.ClusterConfigSpec,
+	metrics *hdfsv1alpha1.MetricsSpec,
+) *nameNodeComponent {
 	return &nameNodeComponent{
 		clusterName:   clusterName,
+		namespace:     namespace,
 		clusterConfig: clusterConfig,
+		metrics:       metrics,
 	}
 }
 
@@ -89,6 +99,27 @@ cp /kubedoop/mount/config/namenode/*.xml /kubedoop/config/namenode
 cp /kubedoop/mount/config/namenode/namenode.log4j.properties /kubedoop/config/namenode/log4j.properties`,
 	}
 
+	// Read hadoop-policy.xml from the mounted ConfigMap, so `dfsadmin -refreshServiceAcl` loads the updated ACLs
+	if common.IsServiceAuthorizationEnabled(c.clusterConfig) {
+		args = append(args, "ln -sf /kubedoop/mount/config/namenode/hadoop-policy.xml /kubedoop/config/namenode/hadoop-policy.xml")
+	}
+
+	// Read proxy-users.xml, included by core-site.xml, from the mounted ConfigMap, so
+	// `dfsadmin -refreshSuperUserGroupsConfiguration` loads the updated proxy users
+	if common.IsProxyUsersEnabled(c.clusterConfig) {
+		args = append(args, "ln -sf /kubedoop/mount/config/namenode/proxy-users.xml /kubedoop/config/namenode/proxy-users.xml")
+	}
+
+	// Export the bind user of the LDAP group mapping
+	if common.IsLdapGroupMappingEnabled(c.clusterConfig) {
+		args = append(args, common.ExportLdapBindUserScript())
+	}
+
+	// Substitute the keystore password if TLS is enabled
+	if common.IsTlsEnabled(c.clusterConfig) {
+		args = append(args, common.SubstituteJksPasswordScript(c.clusterConfig.Authentication.Tls))
+	}
+
 	// Add Kerberos configuration if enabled
 	if common.IsKerberosEnabled(c.clusterConfig) {
 		args = append(args, `{{ if .kerberosEnabled}}
@@ -114,7 +145,7 @@ cp /kubedoop/mount/config/namenode/namenode.log4j.properties /kubedoop/config/na
 }
 
 func (c *nameNodeComponent) GetEnvVars() []corev1.EnvVar {
-	return common.GetCommonContainerEnv(c.clusterConfig, constant.NameNodeComponent, ptr.To(constant.NameNode))
+	return common.GetCommonContainerEnv(c.clusterConfig, constant.NameNodeComponent, ptr.To(constant.NameNode), c.metrics)
 }
 
 func (c *nameNodeComponent) GetVolumeMounts() []corev1.VolumeMount {
@@ -166,7 +197,8 @@ func (c *nameNodeComponent) GetLivenessProbe() *corev1.Probe {
 		SuccessThreshold:    1,
 		TimeoutSeconds:      1,
 		ProbeHandler: corev1.ProbeHandler{
-			HTTPGet: common.TlsHttpGetAction(c.clusterConfig, "dfshealth.html"),
+			// the liveness does not depend on the KDC, a namenode which can not get a ticket is only not ready
+			TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromString(hdfsv1alpha1.RpcName)},
 		},
 	}
 }
@@ -175,11 +207,12 @@ func (c *nameNodeComponent) GetReadinessProbe() *corev1.Probe {
 	return &corev1.Probe{
 		FailureThreshold:    3,
 		InitialDelaySeconds: 10,
-		PeriodSeconds:       10,
+		PeriodSeconds:       20,
 		SuccessThreshold:    1,
-		TimeoutSeconds:      1,
+		TimeoutSeconds:      15,
 		ProbeHandler: corev1.ProbeHandler{
-			TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromString(hdfsv1alpha1.RpcName)},
+			// haadmin starts a JVM, so the state is probed less often
+			Exec: common.NewExecProbeAction(c.clusterConfig, c.clusterName, c.namespace, constant.NameNode, common.HaServiceStateCheck()),
 		},
 	}
 }
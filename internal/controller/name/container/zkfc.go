@@ -1,6 +1,7 @@
 package container
 
 import (
+	"maps"
 	"path"
 
 	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
@@ -74,18 +75,20 @@ func (c *zkfcComponent) GetArgs() []string {
 	tmpl := `mkdir -p /kubedoop/config/zkfc
 cp /kubedoop/mount/config/zkfc/*.xml /kubedoop/config/zkfc
 cp /kubedoop/mount/config/zkfc/zkfc.log4j.properties /kubedoop/config/zkfc/log4j.properties
-
+{{ .substituteJksPassword }}
 {{ if .kerberosEnabled }}
 {{- .kerberosEnv }}
 {{- end }}
 
 /kubedoop/hadoop/bin/hdfs zkfc
 `
-	return common.ParseTemplate(tmpl, common.CreateExportKrbRealmEnvData(c.clusterConfig))
+	data := common.CreateExportKrbRealmEnvData(c.clusterConfig)
+	maps.Copy(data, common.CreateJksPasswordData(c.clusterConfig))
+	return common.ParseTemplate(tmpl, data)
 }
 
 func (c *zkfcComponent) GetEnvVars() []corev1.EnvVar {
-	return common.GetCommonContainerEnv(c.clusterConfig, constant.ZkfcComponent, nil)
+	return common.GetCommonContainerEnv(c.clusterConfig, constant.ZkfcComponent, nil, nil)
 }
 
 func (c *zkfcComponent) GetVolumeMounts() []corev1.VolumeMount {
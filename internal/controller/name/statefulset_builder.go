@@ -7,6 +7,7 @@ import (
 	"github.com/zncdatadev/hdfs-operator/internal/common"
 	"github.com/zncdatadev/hdfs-operator/internal/constant"
 	"github.com/zncdatadev/hdfs-operator/internal/controller/name/container"
+	authv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/authentication/v1alpha1"
 	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
 	opClient "github.com/zncdatadev/operator-go/pkg/client"
 	"github.com/zncdatadev/operator-go/pkg/constants"
@@ -29,6 +30,12 @@ type NamenodeStatefulSetBuilder struct {
 	roleGroupConfig *commonsv1alpha1.RoleGroupConfigSpec
 	image           *util.Image
 	roleGroupInfo   *reconciler.RoleGroupInfo
+	// s3Target is the resolved backup location when restoring from a S3 backup
+	s3Target *common.S3Target
+	// bootstrapS3Target is the resolved location of the namenode metadata when bootstrapping from S3
+	bootstrapS3Target *common.S3Target
+	// ldapProvider is the LDAP server of the group mapping, all containers need its bind credentials
+	ldapProvider *authv1alpha1.LDAPProvider
 }
 
 // NewNamenodeStatefulSetBuilder creates a new NamenodeStatefulSetBuilder that inherits from common StatefulSetBuilder
@@ -69,6 +76,26 @@ func NewNamenodeStatefulSetBuilder(
 
 // Build constructs the StatefulSet using the inherited common builder and namenode-specific component
 func (b *NamenodeStatefulSetBuilder) Build(ctx context.Context) (ctrlclient.Object, error) {
+	clusterConfig := b.GetInstance().Spec.ClusterConfig
+	if common.IsRestoreEnabled(clusterConfig) && clusterConfig.Backup.S3 != nil {
+		s3Target, err := common.ResolveS3Target(ctx, b.GetClient(), b.GetInstance().Namespace, clusterConfig.Backup.S3.Bucket, clusterConfig.Backup.S3.Prefix)
+		if err != nil {
+			return nil, err
+		}
+		b.s3Target = s3Target
+	}
+	if fromImage := common.GetBootstrapFromImage(b.GetInstance()); fromImage != nil && fromImage.S3 != nil {
+		s3Target, err := common.ResolveS3Target(ctx, b.GetClient(), b.GetInstance().Namespace, fromImage.S3.Bucket, fromImage.S3.Path)
+		if err != nil {
+			return nil, err
+		}
+		b.bootstrapS3Target = s3Target
+	}
+	ldapProvider, err := common.ResolveLdapGroupMapping(ctx, b.GetClient(), clusterConfig)
+	if err != nil {
+		return nil, err
+	}
+	b.ldapProvider = ldapProvider
 	// Use the inherited common builder's Build method, passing self as the component builder
 	return b.StatefulSetBuilder.Build(ctx)
 }
@@ -86,23 +113,33 @@ func (b *NamenodeStatefulSetBuilder) GetHttpPort() int32 {
 
 // GetMainContainers returns the main containers for namenode
 func (b *NamenodeStatefulSetBuilder) GetMainContainers() []corev1.Container {
-	return []corev1.Container{
+	return b.withLdapBindCredentials(
 		b.makeNameNodeContainer(),
 		b.makeZkfcContainer(),
-	}
+	)
 }
 
 // GetInitContainers returns init containers for namenode
 func (b *NamenodeStatefulSetBuilder) GetInitContainers() []corev1.Container {
-	return []corev1.Container{
+	return b.withLdapBindCredentials(
 		b.makeFormatNameNodeContainer(),
 		b.makeFormatZookeeperContainer(),
+	)
+}
+
+// withLdapBindCredentials mounts the LDAP bind credentials, the group mapping of the namenode
+// configuration reads the password file whenever hadoop starts
+func (b *NamenodeStatefulSetBuilder) withLdapBindCredentials(containers ...corev1.Container) []corev1.Container {
+	mounts := common.LdapBindCredentialsVolumeMounts(b.ldapProvider)
+	for i := range containers {
+		containers[i].VolumeMounts = append(containers[i].VolumeMounts, mounts...)
 	}
+	return containers
 }
 
 // GetVolumes returns namenode-specific volumes
 func (b *NamenodeStatefulSetBuilder) GetVolumes() []corev1.Volume {
-	return []corev1.Volume{
+	volumes := []corev1.Volume{
 		{
 			Name: hdfsv1alpha1.HdfsConfigVolumeMountName,
 			VolumeSource: corev1.VolumeSource{
@@ -140,6 +177,14 @@ func (b *NamenodeStatefulSetBuilder) GetVolumes() []corev1.Volume {
 			},
 		},
 	}
+	if clusterConfig := b.GetInstance().Spec.ClusterConfig; common.IsRestoreEnabled(clusterConfig) {
+		volumes = append(volumes, common.BackupVolumes(clusterConfig, b.s3Target, true)...)
+	}
+	if fromImage := common.GetBootstrapFromImage(b.GetInstance()); fromImage != nil {
+		volumes = append(volumes, common.BootstrapImageVolumes(fromImage, b.bootstrapS3Target)...)
+	}
+	volumes = append(volumes, common.LdapBindCredentialsVolumes(b.ldapProvider)...)
+	return volumes
 }
 
 // GetVolumeClaimTemplates returns PVCs for namenode
@@ -186,6 +231,8 @@ func (b *NamenodeStatefulSetBuilder) makeFormatNameNodeContainer() corev1.Contai
 		b.image,
 		*b.GetReplicas(),
 		b.roleGroupInfo.GetFullName(),
+		b.s3Target,
+		b.bootstrapS3Target,
 	)
 	return *formatNameNode.Build()
 }
@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"maps"
+	"path"
+
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/common"
+	"github.com/zncdatadev/hdfs-operator/internal/constant"
+	authv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/authentication/v1alpha1"
+	"github.com/zncdatadev/operator-go/pkg/constants"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	"github.com/zncdatadev/operator-go/pkg/util"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+const nameNodeAdminConfigVolumeName = "namenode-config"
+
+// nameNodeAdminJob is a job running `dfsadmin` commands against the namenodes of the cluster. It uses the
+// client configuration of the namenode ConfigMap and, with Kerberos, the principal of the namenodes.
+type nameNodeAdminJob struct {
+	instance    *hdfsv1alpha1.HdfsCluster
+	clusterInfo reconciler.ClusterInfo
+	image       *util.Image
+	// container is the name of the container, it is also the component label of the job
+	container string
+}
+
+const nameNodeAdminSetupTemplate = `mkdir -p {{ .configDir }}
+cp {{ .mountConfigDir }}/*.xml {{ .configDir }}
+
+{{ if .kerberosEnabled }}
+{{- .kerberosEnv }}
+
+{{- .kinitScript }}
+
+{{- end }}
+`
+
+// build builds the job, the script template is rendered with data after the setup of the configuration
+// and the Kerberos ticket
+func (j *nameNodeAdminJob) build(
+	name string,
+	labels map[string]string,
+	script string,
+	data map[string]any,
+	ldapProvider *authv1alpha1.LDAPProvider,
+) (*batchv1.Job, error) {
+	roleGroupInfo, err := firstNameNodeRoleGroupInfo(j.instance, j.clusterInfo)
+	if err != nil {
+		return nil, err
+	}
+	jobLabels := j.clusterInfo.GetLabels()
+	jobLabels[common.LabelComponent] = j.container
+	maps.Copy(jobLabels, labels)
+
+	var pullSecrets []corev1.LocalObjectReference
+	if j.image.PullSecretName != "" {
+		pullSecrets = []corev1.LocalObjectReference{{Name: j.image.PullSecretName}}
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: j.instance.Namespace,
+			Labels:    jobLabels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            ptr.To[int32](3),
+			TTLSecondsAfterFinished: ptr.To[int32](86400),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: jobLabels},
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ServiceAccountName: common.CreateServiceAccountName(j.instance.Name),
+					ImagePullSecrets:   pullSecrets,
+					SecurityContext:    &corev1.PodSecurityContext{FSGroup: ptr.To[int64](1000)},
+					Containers:         []corev1.Container{j.buildContainer(script, data, ldapProvider)},
+					Volumes:            j.volumes(roleGroupInfo, ldapProvider),
+				},
+			},
+		},
+	}, nil
+}
+
+func (j *nameNodeAdminJob) buildContainer(script string, data map[string]any, ldapProvider *authv1alpha1.LDAPProvider) corev1.Container {
+	clusterConfig := j.instance.Spec.ClusterConfig
+	envs := []corev1.EnvVar{
+		{Name: "HADOOP_CONF_DIR", Value: path.Join(constants.KubedoopConfigDir, j.container)},
+		{Name: "HADOOP_HOME", Value: hdfsv1alpha1.HadoopHome},
+	}
+	mounts := []corev1.VolumeMount{
+		{Name: nameNodeAdminConfigVolumeName, MountPath: path.Join(constants.KubedoopConfigDirMount, j.container)},
+	}
+	if common.IsKerberosEnabled(clusterConfig) {
+		var jvmArgs []string
+		envs = append(envs, common.SecurityEnvs(constant.NameNodeComponent, &jvmArgs)...)
+		mounts = append(mounts, common.SecurityVolumeMounts()...)
+	}
+	mounts = append(mounts, common.LdapBindCredentialsVolumeMounts(ldapProvider)...)
+
+	return corev1.Container{
+		Name:            j.container,
+		Image:           j.image.String(),
+		ImagePullPolicy: j.image.GetPullPolicy(),
+		Command:         []string{"/bin/bash", "-x", "-euo", "pipefail", "-c"},
+		Args:            j.args(script, data),
+		Env:             envs,
+		VolumeMounts:    mounts,
+	}
+}
+
+func (j *nameNodeAdminJob) volumes(roleGroupInfo *reconciler.RoleGroupInfo, ldapProvider *authv1alpha1.LDAPProvider) []corev1.Volume {
+	clusterConfig := j.instance.Spec.ClusterConfig
+	volumes := []corev1.Volume{
+		{
+			// the namenode config map contains the client configuration of the namenodes
+			Name: nameNodeAdminConfigVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: roleGroupInfo.GetFullName(),
+					},
+				},
+			},
+		},
+	}
+	if common.IsKerberosEnabled(clusterConfig) {
+		volumes = append(volumes, common.CreateKerberosSecretPvc(clusterConfig.Authentication.Kerberos, j.instance.Name, constant.NameNode))
+	}
+	volumes = append(volumes, common.LdapBindCredentialsVolumes(ldapProvider)...)
+	return volumes
+}
+
+func (j *nameNodeAdminJob) args(script string, scriptData map[string]any) []string {
+	clusterConfig := j.instance.Spec.ClusterConfig
+	data := common.CreateExportKrbRealmEnvData(clusterConfig)
+	principal := common.CreateKerberosPrincipal(clusterConfig, j.instance.Name, j.instance.Namespace, constant.NameNode)
+	maps.Copy(data, common.CreateGetKerberosTicketData(principal))
+	maps.Copy(data, map[string]interface{}{
+		"configDir":      path.Join(constants.KubedoopConfigDir, j.container),
+		"mountConfigDir": path.Join(constants.KubedoopConfigDirMount, j.container),
+	})
+	maps.Copy(data, scriptData)
+	return common.ParseTemplate(nameNodeAdminSetupTemplate+script, data)
+}
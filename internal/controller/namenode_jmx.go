@@ -0,0 +1,176 @@
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/common"
+	"github.com/zncdatadev/hdfs-operator/internal/constant"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	fsNamesystemBean = "Hadoop:service=NameNode,name=FSNamesystem"
+	nameNodeInfoBean = "Hadoop:service=NameNode,name=NameNodeInfo"
+	dataNodeInfoBean = "Hadoop:service=DataNode,name=DataNodeInfo"
+
+	jmxRequestTimeout = 5 * time.Second
+)
+
+// FSNamesystem is the FSNamesystem bean of a namenode, the metrics of the namespace are only
+// up to date on the active namenode
+type FSNamesystem struct {
+	HAState               string `json:"tag.HAState"`
+	NumLiveDataNodes      int32  `json:"NumLiveDataNodes"`
+	NumDeadDataNodes      int32  `json:"NumDeadDataNodes"`
+	MissingBlocks         int64  `json:"MissingBlocks"`
+	UnderReplicatedBlocks int64  `json:"UnderReplicatedBlocks"`
+	CapacityTotal         int64  `json:"CapacityTotal"`
+	CapacityUsed          int64  `json:"CapacityUsed"`
+}
+
+// NameNodeInfo is the NameNodeInfo bean of a namenode, the nodes are JSON objects keyed by the
+// transfer address of the datanodes
+type NameNodeInfo struct {
+	LiveNodes  string `json:"LiveNodes"`
+	DeadNodes  string `json:"DeadNodes"`
+	DecomNodes string `json:"DecomNodes"`
+	// Safemode describes the safe mode of the namenode, it is empty when the namenode is not in safe mode
+	Safemode string `json:"Safemode"`
+}
+
+// ManualSafeMode reports whether the safe mode was turned on manually, e.g. with `hdfs dfsadmin -safemode enter`
+func (i *NameNodeInfo) ManualSafeMode() bool {
+	return strings.Contains(i.Safemode, "turned on manually")
+}
+
+// DataNodeInfo is a datanode of the nodes of NameNodeInfo
+type DataNodeInfo struct {
+	AdminState     string `json:"adminState"`
+	Decommissioned bool   `json:"decommissioned"`
+}
+
+// parseDataNodes parses the nodes of a NameNodeInfo attribute
+func parseDataNodes(nodes string) (map[string]DataNodeInfo, error) {
+	result := make(map[string]DataNodeInfo)
+	if nodes == "" {
+		return result, nil
+	}
+	if err := json.Unmarshal([]byte(nodes), &result); err != nil {
+		return nil, errors.WrapIf(err, "failed to parse the nodes of NameNodeInfo")
+	}
+	return result, nil
+}
+
+// DataNodeActors is the DataNodeInfo bean of a datanode, the actors are a JSON list of the connections
+// of the datanode to the namenodes
+type DataNodeActors struct {
+	BPServiceActorInfo string `json:"BPServiceActorInfo"`
+}
+
+// BPServiceActor is the connection of a datanode to a namenode
+type BPServiceActor struct {
+	NamenodeAddress string `json:"NamenodeAddress"`
+	ActorState      string `json:"ActorState"`
+	// MaxBlockReportSize is 0 until the datanode sent its first block report to the namenode
+	MaxBlockReportSize string `json:"maxBlockReportSize"`
+}
+
+// parseActors parses the actors of DataNodeActors
+func parseActors(actors string) ([]BPServiceActor, error) {
+	var result []BPServiceActor
+	if actors == "" {
+		return result, nil
+	}
+	if err := json.Unmarshal([]byte(actors), &result); err != nil {
+		return nil, errors.WrapIf(err, "failed to parse the actors of DataNodeInfo")
+	}
+	return result, nil
+}
+
+// JmxClient reads beans from the JMX servlet of the web UI of the namenodes and datanodes. The web UI is reached at the pod
+// address, with TLS the certificate is issued for the FQDN of the pod and is not verified, like in the probes.
+type JmxClient struct {
+	httpClient *http.Client
+}
+
+func NewJmxClient() *JmxClient {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec
+	return &JmxClient{
+		httpClient: &http.Client{Transport: transport, Timeout: jmxRequestTimeout},
+	}
+}
+
+// NameNodeBean reads a bean of the namenode in the pod into out
+func (c *JmxClient) NameNodeBean(
+	ctx context.Context,
+	clusterConfig *hdfsv1alpha1.ClusterConfigSpec,
+	pod *corev1.Pod,
+	bean string,
+	out any,
+) error {
+	return c.Bean(ctx, clusterConfig, constant.NameNode, pod, bean, out)
+}
+
+// Bean reads a bean of the role in the pod into out
+func (c *JmxClient) Bean(
+	ctx context.Context,
+	clusterConfig *hdfsv1alpha1.ClusterConfigSpec,
+	role constant.Role,
+	pod *corev1.Pod,
+	bean string,
+	out any,
+) error {
+	if pod.Status.PodIP == "" {
+		return fmt.Errorf("pod %s has no address", pod.Name)
+	}
+	port, err := common.GetNativeMetricsPort(role, clusterConfig)
+	if err != nil {
+		return err
+	}
+	scheme := "http"
+	if common.IsTlsEnabled(clusterConfig) {
+		scheme = "https"
+	}
+	jmxUrl := url.URL{
+		Scheme:   scheme,
+		Host:     net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(int(port))),
+		Path:     "/jmx",
+		RawQuery: url.Values{"qry": []string{bean}}.Encode(),
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, jmxUrl.String(), nil)
+	if err != nil {
+		return err
+	}
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return errors.WrapIfWithDetails(err, "failed to read jmx", "pod", pod.Name, "bean", bean)
+	}
+	defer func() { _ = response.Body.Close() }()
+	if response.StatusCode != http.StatusOK {
+		return errors.NewWithDetails("unexpected status of jmx", "pod", pod.Name, "bean", bean, "status", response.Status)
+	}
+
+	var beans struct {
+		Beans []json.RawMessage `json:"beans"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&beans); err != nil {
+		return errors.WrapIfWithDetails(err, "failed to decode jmx", "pod", pod.Name, "bean", bean)
+	}
+	if len(beans.Beans) == 0 {
+		return errors.NewWithDetails("bean not found", "pod", pod.Name, "bean", bean)
+	}
+	return json.Unmarshal(beans.Beans[0], out)
+}
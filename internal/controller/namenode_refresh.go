@@ -0,0 +1,323 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/common"
+	authv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/authentication/v1alpha1"
+	pkgclient "github.com/zncdatadev/operator-go/pkg/client"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	"github.com/zncdatadev/operator-go/pkg/util"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var nameNodeRefreshLog = ctrl.Log.WithName("namenode-refresh")
+
+const (
+	nameNodeRefreshContainerName = "refresh"
+	nameNodeRefreshHashLabel     = "hdfs.kubedoop.dev/config-hash"
+
+	// nameNodeRefreshPropagationDelay is the time in seconds the job waits before refreshing the namenodes.
+	// Updates of a ConfigMap reach the volumes of running pods within the kubelet sync period and the cache TTL.
+	nameNodeRefreshPropagationDelay = 90
+
+	nameNodeRefreshReadyRequeueAfter = 10 * time.Second
+
+	// a failed refresh is retried with a new job after a backoff, doubled after every failed attempt
+	nameNodeRefreshMaxAttempts    = 5
+	nameNodeRefreshInitialBackoff = 30 * time.Second
+)
+
+// NameNodeRefresh is configuration the namenodes reload with a `dfsadmin` command.
+// The namenodes read the file directly from their ConfigMap, see the namenode container.
+type NameNodeRefresh struct {
+	// Name is used in the names of the reconciler and the jobs
+	Name string
+	// Command is the `dfsadmin` command reloading the configuration
+	Command string
+	// Config extracts the configuration from the namenode ConfigMap, a change triggers a refresh
+	Config func(configMap *corev1.ConfigMap) (string, error)
+	// Status returns the status field recording the loaded configuration
+	Status func(status *hdfsv1alpha1.HdfsClusterStatus) **hdfsv1alpha1.ConfigRefreshStatus
+}
+
+// ServiceAclRefresh reloads the ACLs of hadoop-policy.xml
+var ServiceAclRefresh = NameNodeRefresh{
+	Name:    "acl",
+	Command: "-refreshServiceAcl",
+	Config: func(configMap *corev1.ConfigMap) (string, error) {
+		return configMap.Data[hdfsv1alpha1.HadoopPolicyFileName], nil
+	},
+	Status: func(status *hdfsv1alpha1.HdfsClusterStatus) **hdfsv1alpha1.ConfigRefreshStatus {
+		return &status.ServiceAuthorization
+	},
+}
+
+// ProxyUsersRefresh reloads the proxy users of proxy-users.xml, which is included by core-site.xml
+var ProxyUsersRefresh = NameNodeRefresh{
+	Name:    "proxy-users",
+	Command: "-refreshSuperUserGroupsConfiguration",
+	Config: func(configMap *corev1.ConfigMap) (string, error) {
+		return common.ProxyUserConfig(configMap.Data[hdfsv1alpha1.ProxyUsersFileName])
+	},
+	Status: func(status *hdfsv1alpha1.HdfsClusterStatus) **hdfsv1alpha1.ConfigRefreshStatus {
+		return &status.ProxyUsers
+	},
+}
+
+var _ reconciler.Reconciler = &NameNodeRefreshReconciler{}
+
+// NameNodeRefreshReconciler runs a `dfsadmin` refresh command when the configuration of the namenodes changes,
+// so the namenodes load it without a restart. The hash of the loaded configuration is kept in the status.
+type NameNodeRefreshReconciler struct {
+	client      *pkgclient.Client
+	instance    *hdfsv1alpha1.HdfsCluster
+	clusterInfo reconciler.ClusterInfo
+	image       *util.Image
+	refresh     NameNodeRefresh
+}
+
+func NewNameNodeRefreshReconciler(
+	client *pkgclient.Client,
+	instance *hdfsv1alpha1.HdfsCluster,
+	clusterInfo reconciler.ClusterInfo,
+	image *util.Image,
+	refresh NameNodeRefresh,
+) *NameNodeRefreshReconciler {
+	return &NameNodeRefreshReconciler{
+		client:      client,
+		instance:    instance,
+		clusterInfo: clusterInfo,
+		image:       image,
+		refresh:     refresh,
+	}
+}
+
+func (r *NameNodeRefreshReconciler) GetName() string {
+	return r.instance.Name + "-refresh-" + r.refresh.Name
+}
+
+func (r *NameNodeRefreshReconciler) GetNamespace() string {
+	return r.instance.Namespace
+}
+
+func (r *NameNodeRefreshReconciler) GetClient() *pkgclient.Client {
+	return r.client
+}
+
+// Reconcile creates a refresh job for the current configuration if it differs from the loaded one.
+// The namenodes load the configuration on start, so the first configuration is recorded without a job.
+func (r *NameNodeRefreshReconciler) Reconcile(ctx context.Context) (ctrl.Result, error) {
+	hash, err := r.configHash(ctx)
+	if err != nil || hash == "" {
+		return ctrl.Result{}, err
+	}
+
+	status := r.refresh.Status(&r.instance.Status)
+	if *status == nil || (*status).Hash == "" {
+		*status = &hdfsv1alpha1.ConfigRefreshStatus{Hash: hash}
+		return ctrl.Result{}, r.client.Client.Status().Update(ctx, r.instance)
+	}
+	if (*status).Hash == hash {
+		return ctrl.Result{}, nil
+	}
+
+	job, attempt, err := r.lastJob(ctx, hash)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if job != nil {
+		failedAt := jobFailedTime(job)
+		if failedAt == nil {
+			// the job is running or has succeeded
+			return ctrl.Result{}, nil
+		}
+		if attempt+1 >= nameNodeRefreshMaxAttempts {
+			return ctrl.Result{}, nil
+		}
+		if wait := time.Until(failedAt.Add(refreshBackoff(attempt))); wait > 0 {
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+		attempt++
+	}
+
+	ldapProvider, err := common.ResolveLdapGroupMapping(ctx, r.client, r.instance.Spec.ClusterConfig)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	job, err = r.buildJob(hash, attempt, ldapProvider)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.client.CreateDoesNotExist(ctx, job); err != nil {
+		return ctrl.Result{}, err
+	}
+	nameNodeRefreshLog.Info("Configuration changed, refreshing namenodes", "command", r.refresh.Command, "job", job.Name,
+		"attempt", attempt+1)
+	return ctrl.Result{}, nil
+}
+
+// Ready records the hash once a refresh job has succeeded, it requeues while the job is running.
+// A failed job is retried by Reconcile after the backoff, after the last attempt the refresh is not retried
+// until the configuration changes again or the jobs are deleted.
+func (r *NameNodeRefreshReconciler) Ready(ctx context.Context) (ctrl.Result, error) {
+	hash, err := r.configHash(ctx)
+	if err != nil || hash == "" {
+		return ctrl.Result{}, err
+	}
+	status := *r.refresh.Status(&r.instance.Status)
+	if status == nil || status.Hash == hash {
+		return ctrl.Result{}, nil
+	}
+
+	job, attempt, err := r.lastJob(ctx, hash)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if job == nil {
+		return ctrl.Result{RequeueAfter: nameNodeRefreshReadyRequeueAfter}, nil
+	}
+
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			status.Hash = hash
+			status.LastRefreshTime = ptr.To(metav1.Now())
+			if err := r.client.Client.Status().Update(ctx, r.instance); err != nil {
+				return ctrl.Result{}, err
+			}
+			nameNodeRefreshLog.Info("Refreshed namenodes", "command", r.refresh.Command, "job", job.Name)
+			return ctrl.Result{}, nil
+		case batchv1.JobFailed:
+			if attempt+1 >= nameNodeRefreshMaxAttempts {
+				nameNodeRefreshLog.Info("Failed to refresh namenodes, giving up, delete the jobs to retry",
+					"command", r.refresh.Command, "job", job.Name, "attempts", attempt+1, "reason", condition.Message)
+				return ctrl.Result{}, nil
+			}
+			backoff := refreshBackoff(attempt)
+			nameNodeRefreshLog.Info("Failed to refresh namenodes, retrying",
+				"command", r.refresh.Command, "job", job.Name, "backoff", backoff, "reason", condition.Message)
+			return ctrl.Result{RequeueAfter: max(time.Until(condition.LastTransitionTime.Add(backoff)), 0) + time.Second}, nil
+		}
+	}
+	return ctrl.Result{RequeueAfter: nameNodeRefreshReadyRequeueAfter}, nil
+}
+
+// lastJob returns the refresh job of the last attempt to load the configuration with the hash,
+// it is nil if no job was created yet
+func (r *NameNodeRefreshReconciler) lastJob(ctx context.Context, hash string) (*batchv1.Job, int, error) {
+	return lastAttemptJob(ctx, r.client.Client, r.GetNamespace(), nameNodeRefreshMaxAttempts, func(attempt int) string {
+		return r.jobName(hash, attempt)
+	})
+}
+
+// lastAttemptJob returns the job of the last attempt of a job retried with a new name per attempt,
+// it is nil if no job was created yet
+func lastAttemptJob(
+	ctx context.Context,
+	client ctrlclient.Reader,
+	namespace string,
+	maxAttempts int,
+	name func(attempt int) string,
+) (*batchv1.Job, int, error) {
+	var last *batchv1.Job
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		job := &batchv1.Job{}
+		if err := client.Get(ctx, ctrlclient.ObjectKey{Namespace: namespace, Name: name(attempt)}, job); err != nil {
+			if apierrors.IsNotFound(err) {
+				return last, attempt - 1, nil
+			}
+			return nil, 0, err
+		}
+		last = job
+	}
+	return last, maxAttempts - 1, nil
+}
+
+// attemptJobName returns the name of the job of an attempt, the first attempt has no suffix
+func attemptJobName(name string, attempt int) string {
+	if attempt > 0 {
+		return fmt.Sprintf("%s-%d", name, attempt)
+	}
+	return name
+}
+
+// jobFailedTime returns the time the job failed, it is nil if the job has not failed
+func jobFailedTime(job *batchv1.Job) *time.Time {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return &condition.LastTransitionTime.Time
+		}
+	}
+	return nil
+}
+
+// refreshBackoff returns the time to wait before the attempt after the given failed attempt
+func refreshBackoff(attempt int) time.Duration {
+	return nameNodeRefreshInitialBackoff << attempt
+}
+
+// configHash returns the hash of the refreshed configuration in the config map of the namenodes,
+// it is empty if the config map does not exist yet.
+func (r *NameNodeRefreshReconciler) configHash(ctx context.Context) (string, error) {
+	configMap := &corev1.ConfigMap{}
+	roleGroupInfo, err := firstNameNodeRoleGroupInfo(r.instance, r.clusterInfo)
+	if err != nil {
+		return "", err
+	}
+	if err := r.client.Get(ctx, ctrlclient.ObjectKey{Namespace: r.GetNamespace(), Name: roleGroupInfo.GetFullName()}, configMap); err != nil {
+		return "", ctrlclient.IgnoreNotFound(err)
+	}
+	config, err := r.refresh.Config(configMap)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(config))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// jobName returns the name of the refresh job, the first attempt has no suffix
+func (r *NameNodeRefreshReconciler) jobName(hash string, attempt int) string {
+	return attemptJobName(fmt.Sprintf("%s-refresh-%s-%s", r.instance.Name, r.refresh.Name, hash[:10]), attempt)
+}
+
+func (r *NameNodeRefreshReconciler) buildJob(hash string, attempt int, ldapProvider *authv1alpha1.LDAPProvider) (*batchv1.Job, error) {
+	job := &nameNodeAdminJob{
+		instance:    r.instance,
+		clusterInfo: r.clusterInfo,
+		image:       r.image,
+		container:   nameNodeRefreshContainerName,
+	}
+	return job.build(
+		r.jobName(hash, attempt),
+		map[string]string{nameNodeRefreshHashLabel: hash[:16]},
+		nameNodeRefreshScriptTemplate,
+		map[string]any{
+			"propagationDelay": nameNodeRefreshPropagationDelay,
+			"command":          r.refresh.Command,
+		},
+		ldapProvider,
+	)
+}
+
+const nameNodeRefreshScriptTemplate = `
+echo "Waiting {{ .propagationDelay }}s for the namenodes to receive the updated configuration"
+sleep {{ .propagationDelay }}
+
+# refreshes all namenodes of the nameservice
+/kubedoop/hadoop/bin/hdfs dfsadmin {{ .command }}
+`
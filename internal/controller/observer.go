@@ -0,0 +1,298 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/constant"
+	opconstants "github.com/zncdatadev/operator-go/pkg/constants"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+var observerLog = ctrl.Log.WithName("cluster-observer")
+
+// DefaultObserveInterval is the interval between two observations of the clusters
+const DefaultObserveInterval = 30 * time.Second
+
+// decommissionedAdminState is the admin state of a live datanode which finished its decommission
+const decommissionedAdminState = "Decommissioned"
+
+var _ manager.LeaderElectionRunnable = &ClusterObserver{}
+
+// ClusterObserver periodically observes the running clusters and emits events for the transitions
+// which happen inside HDFS and not in the reconciler: the format of the namenodes and ZooKeeper,
+// failovers of the active namenode, decommissions of datanodes and rollouts of the StatefulSets.
+// Transitions are detected against the previous observation, which is kept in memory. The first
+// observation of a cluster after the start of the operator only records its state.
+// With a ClusterHealth, the health read from the namenodes is exported as well.
+type ClusterObserver struct {
+	client   ctrlclient.Client
+	recorder *EventRecorder
+	health   *ClusterHealth
+	jmx      *JmxClient
+	interval time.Duration
+
+	states map[types.UID]*observedState
+}
+
+// observedState is the state of a cluster at the previous observation
+type observedState struct {
+	namespace      string
+	name           string
+	activeNameNode string
+	// decommissioning is nil until the datanodes are read from an active namenode
+	decommissioning map[string]bool
+	// revisions are the update revisions of the StatefulSets
+	revisions map[string]string
+}
+
+// NewClusterObserver creates an observer, health is optional
+func NewClusterObserver(
+	client ctrlclient.Client,
+	recorder *EventRecorder,
+	health *ClusterHealth,
+	interval time.Duration,
+) *ClusterObserver {
+	return &ClusterObserver{
+		client:   client,
+		recorder: recorder,
+		health:   health,
+		jmx:      NewJmxClient(),
+		interval: interval,
+		states:   make(map[types.UID]*observedState),
+	}
+}
+
+// NeedLeaderElection observes the clusters only on the leader, like the reconcilers
+func (o *ClusterObserver) NeedLeaderElection() bool {
+	return true
+}
+
+func (o *ClusterObserver) Start(ctx context.Context) error {
+	ticker := time.NewTicker(o.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			o.observeAll(ctx)
+		}
+	}
+}
+
+func (o *ClusterObserver) observeAll(ctx context.Context) {
+	clusters := &hdfsv1alpha1.HdfsClusterList{}
+	if err := o.client.List(ctx, clusters); err != nil {
+		observerLog.Error(err, "failed to list clusters")
+		return
+	}
+
+	observed := make(map[types.UID]*observedState, len(clusters.Items))
+	for i := range clusters.Items {
+		instance := &clusters.Items[i]
+		state, ok := o.states[instance.UID]
+		if !ok {
+			state = &observedState{
+				namespace: instance.Namespace,
+				name:      instance.Name,
+				revisions: make(map[string]string),
+			}
+		}
+		observed[instance.UID] = state
+		if err := o.observe(ctx, instance, state); err != nil {
+			observerLog.V(1).Info("failed to observe cluster", "cluster", instance.Name, "namespace", instance.Namespace,
+				"reason", err.Error())
+		}
+	}
+	// forget deleted clusters
+	for uid, state := range o.states {
+		if _, ok := observed[uid]; !ok && o.health != nil {
+			o.health.forget(state.namespace, state.name)
+		}
+	}
+	o.states = observed
+}
+
+func (o *ClusterObserver) observe(ctx context.Context, instance *hdfsv1alpha1.HdfsCluster, state *observedState) error {
+	if err := o.observeRollouts(ctx, instance, state); err != nil {
+		return err
+	}
+
+	pods := &corev1.PodList{}
+	if err := o.client.List(ctx, pods,
+		ctrlclient.InNamespace(instance.Namespace),
+		ctrlclient.MatchingLabels{
+			opconstants.LabelKubernetesInstance:  instance.Name,
+			opconstants.LabelKubernetesComponent: string(constant.NameNode),
+		},
+	); err != nil {
+		return err
+	}
+	slices.SortFunc(pods.Items, func(a, b corev1.Pod) int { return strings.Compare(a.Name, b.Name) })
+
+	o.observeFormats(instance, pods.Items)
+	return o.observeNameNodes(ctx, instance, state, pods.Items)
+}
+
+// observeRollouts emits an event when a StatefulSet of the cluster starts rolling out a new pod template
+func (o *ClusterObserver) observeRollouts(ctx context.Context, instance *hdfsv1alpha1.HdfsCluster, state *observedState) error {
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := o.client.List(ctx, statefulSets,
+		ctrlclient.InNamespace(instance.Namespace),
+		ctrlclient.MatchingLabels{opconstants.LabelKubernetesInstance: instance.Name},
+	); err != nil {
+		return err
+	}
+
+	revisions := make(map[string]string, len(statefulSets.Items))
+	for _, statefulSet := range statefulSets.Items {
+		revision := statefulSet.Status.UpdateRevision
+		if revision == "" {
+			continue
+		}
+		revisions[statefulSet.Name] = revision
+		previous, ok := state.revisions[statefulSet.Name]
+		if ok && previous != revision && statefulSet.Status.CurrentRevision != revision {
+			o.recorder.Normal(instance, EventReasonRolloutStarted, "Rollout",
+				"StatefulSet %s started rolling out revision %s", statefulSet.Name, revision)
+		}
+	}
+	state.revisions = revisions
+	return nil
+}
+
+// observeFormats emits an event for each recent format of a namenode or ZooKeeper. The format containers
+// report a format in their termination message, which is kept with the pod. The events of a pod are
+// deduplicated by the recorder, formats older than its interval are ignored.
+func (o *ClusterObserver) observeFormats(instance *hdfsv1alpha1.HdfsCluster, pods []corev1.Pod) {
+	reasons := map[string]string{
+		constant.FormatNameNodeContainer:  EventReasonNameNodeFormatted,
+		constant.FormatZookeeperContainer: EventReasonZooKeeperFormatted,
+	}
+	for _, pod := range pods {
+		for _, status := range pod.Status.InitContainerStatuses {
+			reason, ok := reasons[status.Name]
+			terminated := status.State.Terminated
+			if !ok || terminated == nil || terminated.ExitCode != 0 {
+				continue
+			}
+			message := strings.TrimSpace(terminated.Message)
+			if message == "" || time.Since(terminated.FinishedAt.Time) > o.recorder.interval {
+				continue
+			}
+			o.recorder.Normal(instance, reason, "Format", "Pod %s %s", pod.Name, message)
+		}
+	}
+}
+
+// observeNameNodes reads the HA state of the namenodes and the datanodes in decommission from the active namenode
+func (o *ClusterObserver) observeNameNodes(
+	ctx context.Context,
+	instance *hdfsv1alpha1.HdfsCluster,
+	state *observedState,
+	pods []corev1.Pod,
+) error {
+	states := make(map[string]string, len(pods))
+	var active *corev1.Pod
+	var namesystem *FSNamesystem
+	var probeErr error
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		podNamesystem := &FSNamesystem{}
+		if err := o.jmx.NameNodeBean(ctx, instance.Spec.ClusterConfig, pod, fsNamesystemBean, podNamesystem); err != nil {
+			observerLog.V(1).Info("failed to read namenode state", "pod", pod.Name, "reason", err.Error())
+			probeErr = err
+			continue
+		}
+		states[pod.Name] = podNamesystem.HAState
+		if podNamesystem.HAState == "active" && active == nil {
+			active = pod
+			namesystem = podNamesystem
+		}
+	}
+
+	// the info of the active namenode reports its safe mode and the datanodes in decommission
+	var info *NameNodeInfo
+	var infoErr error
+	if active != nil {
+		info = &NameNodeInfo{}
+		if infoErr = o.jmx.NameNodeBean(ctx, instance.Spec.ClusterConfig, active, nameNodeInfoBean, info); infoErr != nil {
+			info = nil
+		}
+	}
+
+	if o.health != nil {
+		if err := o.health.record(ctx, instance, states, active, namesystem, info, probeErr); err != nil {
+			return err
+		}
+	}
+	if active == nil {
+		return nil
+	}
+
+	if state.activeNameNode != "" && state.activeNameNode != active.Name {
+		o.recorder.Warning(instance, EventReasonNameNodeFailover, "Failover",
+			"Active namenode changed from %s to %s", state.activeNameNode, active.Name)
+	}
+	state.activeNameNode = active.Name
+
+	if infoErr != nil {
+		return infoErr
+	}
+	return o.observeDecommissions(instance, state, info)
+}
+
+// observeDecommissions emits events when datanodes enter or finish their decommission
+func (o *ClusterObserver) observeDecommissions(
+	instance *hdfsv1alpha1.HdfsCluster,
+	state *observedState,
+	info *NameNodeInfo,
+) error {
+	decomNodes, err := parseDataNodes(info.DecomNodes)
+	if err != nil {
+		return err
+	}
+	liveNodes, err := parseDataNodes(info.LiveNodes)
+	if err != nil {
+		return err
+	}
+	deadNodes, err := parseDataNodes(info.DeadNodes)
+	if err != nil {
+		return err
+	}
+
+	decommissioning := make(map[string]bool, len(decomNodes))
+	for node := range decomNodes {
+		decommissioning[node] = true
+		if state.decommissioning != nil && !state.decommissioning[node] {
+			o.recorder.Normal(instance, EventReasonDataNodeDecommissionStarted, "Decommission",
+				"Datanode %s started decommission", node)
+		}
+	}
+	for node := range state.decommissioning {
+		if decommissioning[node] {
+			continue
+		}
+		if liveNodes[node].AdminState == decommissionedAdminState || deadNodes[node].Decommissioned {
+			o.recorder.Normal(instance, EventReasonDataNodeDecommissionFinished, "Decommission",
+				"Datanode %s finished decommission", node)
+		} else {
+			o.recorder.Normal(instance, EventReasonDataNodeDecommissionFinished, "Decommission",
+				"Datanode %s left decommission before it was finished", node)
+		}
+	}
+	state.decommissioning = decommissioning
+	return nil
+}
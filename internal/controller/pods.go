@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"context"
+
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/constant"
+	opconstants "github.com/zncdatadev/operator-go/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// rolePods returns the pods of all role groups of the role
+func rolePods(ctx context.Context, client ctrlclient.Reader, instance *hdfsv1alpha1.HdfsCluster, role constant.Role) ([]corev1.Pod, error) {
+	pods := &corev1.PodList{}
+	if err := client.List(ctx, pods,
+		ctrlclient.InNamespace(instance.Namespace),
+		ctrlclient.MatchingLabels{
+			opconstants.LabelKubernetesInstance:  instance.Name,
+			opconstants.LabelKubernetesComponent: string(role),
+		},
+	); err != nil {
+		return nil, err
+	}
+	return pods.Items, nil
+}
+
+// podRunning reports whether the pod is running and not deleted
+func podRunning(pod *corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodRunning && pod.DeletionTimestamp == nil
+}
+
+// containerStarted reports whether the container of the pod passed its startup probe, the pod is not deleted
+func containerStarted(pod *corev1.Pod, container string) bool {
+	if pod.DeletionTimestamp != nil {
+		return false
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == container {
+			return ptr.Deref(status.Started, false)
+		}
+	}
+	return false
+}
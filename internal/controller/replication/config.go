@@ -0,0 +1,171 @@
+package replication
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"emperror.dev/errors"
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/util"
+	"github.com/zncdatadev/operator-go/pkg/client"
+	corev1 "k8s.io/api/core/v1"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The nameservices of both clusters are renamed, so that clusters with the same name,
+// e.g. a production and a DR cluster in different namespaces, can be used together.
+const (
+	SourceNameservice = "source"
+	TargetNameservice = "target"
+)
+
+// clusterConfig is the client configuration of one side of the replication
+type clusterConfig struct {
+	nameservice string
+	coreSite    []util.XmlNameValuePair
+	hdfsSite    []util.XmlNameValuePair
+}
+
+// loadClusterConfig reads the discovery ConfigMap of a replication cluster
+func loadClusterConfig(
+	ctx context.Context,
+	client *client.Client,
+	namespace string,
+	spec *hdfsv1alpha1.ReplicationClusterSpec,
+) (*clusterConfig, error) {
+	configMapName := spec.ConfigMap
+	if spec.HdfsCluster != "" {
+		cluster := &hdfsv1alpha1.HdfsCluster{}
+		if err := client.Get(ctx, ctrlclient.ObjectKey{Namespace: namespace, Name: spec.HdfsCluster}, cluster); err != nil {
+			return nil, errors.WrapIfWithDetails(err, "failed to get HdfsCluster", "name", spec.HdfsCluster)
+		}
+		// the discovery ConfigMap is named after the cluster
+		configMapName = cluster.Name
+	}
+	if configMapName == "" {
+		return nil, errors.New("either hdfsCluster or configMap must be set")
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := client.Get(ctx, ctrlclient.ObjectKey{Namespace: namespace, Name: configMapName}, configMap); err != nil {
+		return nil, errors.WrapIfWithDetails(err, "failed to get discovery ConfigMap", "name", configMapName)
+	}
+
+	config := &clusterConfig{}
+	for fileName, properties := range map[string]*[]util.XmlNameValuePair{
+		hdfsv1alpha1.CoreSiteFileName: &config.coreSite,
+		hdfsv1alpha1.HdfsSiteFileName: &config.hdfsSite,
+	} {
+		content, ok := configMap.Data[fileName]
+		if !ok {
+			return nil, errors.Errorf("discovery ConfigMap %s has no %s", configMapName, fileName)
+		}
+		dom, err := util.Parse(content)
+		if err != nil {
+			return nil, errors.WrapIfWithDetails(err, "failed to parse discovery config", "name", configMapName, "file", fileName)
+		}
+		*properties = dom.Properties
+	}
+
+	config.nameservice = nameservice(config)
+	if config.nameservice == "" {
+		return nil, errors.Errorf("discovery ConfigMap %s has no nameservice", configMapName)
+	}
+
+	// principals of the discovery config use the realm of the client
+	if spec.KerberosRealm != "" {
+		for _, properties := range []*[]util.XmlNameValuePair{&config.coreSite, &config.hdfsSite} {
+			for i := range *properties {
+				(*properties)[i].Value = strings.ReplaceAll((*properties)[i].Value, "${env.KERBEROS_REALM}", spec.KerberosRealm)
+			}
+		}
+	}
+	return config, nil
+}
+
+// nameservice returns the first nameservice of the cluster, or the host of fs.defaultFS
+func nameservice(config *clusterConfig) string {
+	for _, property := range config.hdfsSite {
+		if property.Name == "dfs.nameservices" {
+			return strings.TrimSpace(strings.Split(property.Value, ",")[0])
+		}
+	}
+	for _, property := range config.coreSite {
+		if property.Name == "fs.defaultFS" {
+			if u, err := url.Parse(property.Value); err == nil {
+				return u.Hostname()
+			}
+		}
+	}
+	return ""
+}
+
+// nameserviceProperties are the prefixes of the properties which are suffixed by a nameservice,
+// the nameservice may be followed by a namenode
+var nameserviceProperties = []string{
+	"dfs.ha.namenodes",
+	"dfs.ha.automatic-failover.enabled",
+	"dfs.client.failover.proxy.provider",
+	"dfs.namenode.rpc-address",
+	"dfs.namenode.servicerpc-address",
+	"dfs.namenode.lifeline.rpc-address",
+	"dfs.namenode.http-address",
+	"dfs.namenode.https-address",
+	"dfs.namenode.shared.edits.dir",
+}
+
+// renameNameservice replaces the nameservice segment of a property name,
+// e.g. `dfs.ha.namenodes.<nameservice>` or `dfs.namenode.rpc-address.<nameservice>.<namenode>`.
+// The segment is only replaced after a known prefix, so nameservices named like a segment
+// of another property, e.g. `namenode` or `http`, keep the other segments.
+func renameNameservice(name, nameservice, alias string) string {
+	for _, prefix := range nameserviceProperties {
+		key := prefix + "." + nameservice
+		if name == key || strings.HasPrefix(name, key+".") {
+			return prefix + "." + alias + strings.TrimPrefix(name, key)
+		}
+	}
+	return name
+}
+
+// mergeProperties merges the properties of source and target, renaming their nameservices.
+// Properties which are not specific to a nameservice are taken from the source.
+func mergeProperties(source []util.XmlNameValuePair, sourceNameservice string, target []util.XmlNameValuePair, targetNameservice string) []util.XmlNameValuePair {
+	merged := make([]util.XmlNameValuePair, 0, len(source)+len(target))
+	index := make(map[string]struct{})
+	add := func(properties []util.XmlNameValuePair, nameservice, alias string) {
+		for _, property := range properties {
+			name := renameNameservice(property.Name, nameservice, alias)
+			if _, ok := index[name]; ok {
+				continue
+			}
+			index[name] = struct{}{}
+			merged = append(merged, util.XmlNameValuePair{Name: name, Value: property.Value})
+		}
+	}
+	add(source, sourceNameservice, SourceNameservice)
+	add(target, targetNameservice, TargetNameservice)
+	return merged
+}
+
+// mergeClientConfigs returns the core-site.xml and hdfs-site.xml used to access both clusters
+func mergeClientConfigs(source, target *clusterConfig, kerberosEnabled bool) (string, string) {
+	coreSite := mergeProperties(source.coreSite, source.nameservice, target.coreSite, target.nameservice)
+	coreSiteOverrides := []util.XmlNameValuePair{
+		{Name: "fs.defaultFS", Value: fmt.Sprintf("hdfs://%s", TargetNameservice)},
+	}
+	if kerberosEnabled {
+		// the namenodes of both clusters use different principals, possibly in different realms
+		coreSiteOverrides = append(coreSiteOverrides, util.XmlNameValuePair{Name: "dfs.namenode.kerberos.principal.pattern", Value: "*"})
+	}
+
+	hdfsSite := mergeProperties(source.hdfsSite, source.nameservice, target.hdfsSite, target.nameservice)
+	hdfsSiteOverrides := []util.XmlNameValuePair{
+		{Name: "dfs.nameservices", Value: SourceNameservice + "," + TargetNameservice},
+	}
+
+	return util.NewXmlConfiguration(coreSite).String(coreSiteOverrides),
+		util.NewXmlConfiguration(hdfsSite).String(hdfsSiteOverrides)
+}
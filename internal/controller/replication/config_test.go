@@ -0,0 +1,74 @@
+package replication
+
+import "testing"
+
+func TestRenameNameservice(t *testing.T) {
+	tests := []struct {
+		name        string
+		property    string
+		nameservice string
+		want        string
+	}{
+		{
+			name:        "namenodes",
+			property:    "dfs.ha.namenodes.hdfs",
+			nameservice: "hdfs",
+			want:        "dfs.ha.namenodes.source",
+		},
+		{
+			name:        "namenode address",
+			property:    "dfs.namenode.rpc-address.hdfs.hdfs-namenode-default-0",
+			nameservice: "hdfs",
+			want:        "dfs.namenode.rpc-address.source.hdfs-namenode-default-0",
+		},
+		{
+			name:        "nameservice named like a segment of the prefix",
+			property:    "dfs.namenode.rpc-address.namenode.namenode-namenode-default-0",
+			nameservice: "namenode",
+			want:        "dfs.namenode.rpc-address.source.namenode-namenode-default-0",
+		},
+		{
+			name:        "nameservice named ha",
+			property:    "dfs.ha.namenodes.ha",
+			nameservice: "ha",
+			want:        "dfs.ha.namenodes.source",
+		},
+		{
+			name:        "nameservice named ha with a namenode",
+			property:    "dfs.namenode.http-address.ha.ha-namenode-default-0",
+			nameservice: "ha",
+			want:        "dfs.namenode.http-address.source.ha-namenode-default-0",
+		},
+		{
+			name:        "failover proxy provider of nameservice named http",
+			property:    "dfs.client.failover.proxy.provider.http",
+			nameservice: "http",
+			want:        "dfs.client.failover.proxy.provider.source",
+		},
+		{
+			name:        "property containing the nameservice",
+			property:    "dfs.http.policy",
+			nameservice: "http",
+			want:        "dfs.http.policy",
+		},
+		{
+			name:        "property ending with the nameservice",
+			property:    "dfs.ha.automatic-failover.enabled",
+			nameservice: "enabled",
+			want:        "dfs.ha.automatic-failover.enabled",
+		},
+		{
+			name:        "property of another nameservice",
+			property:    "dfs.ha.namenodes.hdfs-dr",
+			nameservice: "hdfs",
+			want:        "dfs.ha.namenodes.hdfs-dr",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renameNameservice(tt.property, tt.nameservice, SourceNameservice); got != tt.want {
+				t.Errorf("renameNameservice(%q, %q) = %q, want %q", tt.property, tt.nameservice, got, tt.want)
+			}
+		})
+	}
+}
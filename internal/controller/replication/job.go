@@ -0,0 +1,273 @@
+package replication
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"strings"
+
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/common"
+	"github.com/zncdatadev/operator-go/pkg/constants"
+	"github.com/zncdatadev/operator-go/pkg/util"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	LabelReplication = "hdfs.kubedoop.dev/replication"
+
+	distcpContainerName   = "distcp"
+	distcpKerberosService = "distcp"
+
+	configVolumeName       = "config"
+	krb5ConfigVolumeName   = "krb5-config"
+	krb5ConfigDir          = constants.KubedoopRoot + "krb5-config"
+	distcpSnapshotPrefix   = "distcp-"
+	distcpConfigDir        = constants.KubedoopConfigDir + "distcp"
+	distcpMountedConfigDir = constants.KubedoopConfigDirMount + "distcp"
+)
+
+func configMapName(instance *hdfsv1alpha1.HdfsReplication) string {
+	return instance.Name + "-replication"
+}
+
+func labels(instance *hdfsv1alpha1.HdfsReplication) map[string]string {
+	return map[string]string{
+		common.LabelCrName:    instance.Name,
+		common.LabelManagedBy: "hdfs-operator",
+		common.LabelComponent: "replication",
+		LabelReplication:      instance.Name,
+	}
+}
+
+func isKerberosEnabled(instance *hdfsv1alpha1.HdfsReplication) bool {
+	return instance.Spec.Kerberos != nil
+}
+
+func buildConfigMap(instance *hdfsv1alpha1.HdfsReplication, coreSite, hdfsSite string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      configMapName(instance),
+			Namespace: instance.Namespace,
+			Labels:    labels(instance),
+		},
+		Data: map[string]string{
+			hdfsv1alpha1.CoreSiteFileName: coreSite,
+			hdfsv1alpha1.HdfsSiteFileName: hdfsSite,
+		},
+	}
+}
+
+func buildCronJob(instance *hdfsv1alpha1.HdfsReplication, image *util.Image) *batchv1.CronJob {
+	labels := labels(instance)
+
+	var pullSecrets []corev1.LocalObjectReference
+	if image.PullSecretName != "" {
+		pullSecrets = []corev1.LocalObjectReference{{Name: image.PullSecretName}}
+	}
+
+	return &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      instance.Name,
+			Namespace: instance.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule:                   instance.Spec.Schedule,
+			Suspend:                    ptr.To(instance.Spec.Suspend),
+			ConcurrencyPolicy:          batchv1.ForbidConcurrent,
+			SuccessfulJobsHistoryLimit: ptr.To[int32](3),
+			FailedJobsHistoryLimit:     ptr.To[int32](3),
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: batchv1.JobSpec{
+					BackoffLimit: ptr.To[int32](0),
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: labels},
+						Spec: corev1.PodSpec{
+							RestartPolicy:    corev1.RestartPolicyNever,
+							ImagePullSecrets: pullSecrets,
+							SecurityContext:  &corev1.PodSecurityContext{FSGroup: ptr.To[int64](1000)},
+							Containers:       []corev1.Container{buildContainer(instance, image)},
+							Volumes:          volumes(instance),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func buildContainer(instance *hdfsv1alpha1.HdfsReplication, image *util.Image) corev1.Container {
+	envs := []corev1.EnvVar{
+		{Name: "HADOOP_CONF_DIR", Value: distcpConfigDir},
+		{Name: "HADOOP_HOME", Value: hdfsv1alpha1.HadoopHome},
+	}
+	envs = append(envs, pathEnvs(instance)...)
+	mounts := []corev1.VolumeMount{
+		{Name: configVolumeName, MountPath: distcpMountedConfigDir},
+	}
+	if isKerberosEnabled(instance) {
+		krb5Conf := path.Join(constants.KubedoopKerberosDir, "krb5.conf")
+		if instance.Spec.Kerberos.Krb5ConfigMap != "" {
+			krb5Conf = path.Join(krb5ConfigDir, "krb5.conf")
+			mounts = append(mounts, corev1.VolumeMount{Name: krb5ConfigVolumeName, MountPath: krb5ConfigDir})
+		}
+		envs = append(envs,
+			corev1.EnvVar{Name: "HADOOP_OPTS", Value: fmt.Sprintf("-Djava.security.krb5.conf=%s", krb5Conf)},
+			corev1.EnvVar{Name: "KRB5_CONFIG", Value: krb5Conf},
+			corev1.EnvVar{Name: "KRB5_CLIENT_KTNAME", Value: path.Join(constants.KubedoopKerberosDir, "keytab")},
+		)
+		mounts = append(mounts, common.SecurityVolumeMounts()...)
+	}
+
+	return corev1.Container{
+		Name:            distcpContainerName,
+		Image:           image.String(),
+		ImagePullPolicy: image.GetPullPolicy(),
+		Command:         []string{"/bin/bash", "-x", "-euo", "pipefail", "-c"},
+		Args:            args(instance),
+		Env:             envs,
+		VolumeMounts:    mounts,
+	}
+}
+
+func volumes(instance *hdfsv1alpha1.HdfsReplication) []corev1.Volume {
+	volumes := []corev1.Volume{
+		{
+			Name: configVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: configMapName(instance)},
+				},
+			},
+		},
+	}
+	if isKerberosEnabled(instance) {
+		volumes = append(volumes, common.CreateKerberosSecretVolume(instance.Spec.Kerberos.SecretClass, instance.Name, distcpKerberosService))
+		if instance.Spec.Kerberos.Krb5ConfigMap != "" {
+			volumes = append(volumes, corev1.Volume{
+				Name: krb5ConfigVolumeName,
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{Name: instance.Spec.Kerberos.Krb5ConfigMap},
+					},
+				},
+			})
+		}
+	}
+	return volumes
+}
+
+const distcpScriptTemplate = `mkdir -p {{ .configDir }}
+cp {{ .mountConfigDir }}/*.xml {{ .configDir }}
+
+{{ if .kerberosEnabled }}
+export KERBEROS_REALM=$(grep -oP 'default_realm = \K.*' $KRB5_CONFIG)
+{{- .kinitScript }}
+{{- end }}
+
+HDFS=/kubedoop/hadoop/bin/hdfs
+DISTCP="/kubedoop/hadoop/bin/hadoop distcp -Dmapreduce.framework.name=local {{ .distcpOptions }}"
+SNAPSHOT={{ .snapshotPrefix }}$(date -u +%Y%m%d%H%M%S)
+
+{{- range .paths }}
+
+# the paths are passed as environment variables, they are not parsed by the shell
+SOURCE="${{ .SourceEnv }}"
+TARGET="${{ .TargetEnv }}"
+echo "Replicating $SOURCE to $TARGET"
+{{- if $.diff }}
+PREVIOUS=$($HDFS dfs -ls -C "$TARGET/.snapshot" 2>/dev/null | xargs -r -n1 basename | grep '^{{ $.snapshotPrefix }}' | sort | tail -n1 || true)
+$HDFS dfs -createSnapshot "$SOURCE" "$SNAPSHOT"
+if [ -n "$PREVIOUS" ] && $HDFS dfs -test -d "$SOURCE/.snapshot/$PREVIOUS"
+then
+    $DISTCP -update -diff "$PREVIOUS" "$SNAPSHOT" "$SOURCE" "$TARGET"
+else
+    echo "No common snapshot of $SOURCE and $TARGET, copying all files"
+    $DISTCP -update "$SOURCE/.snapshot/$SNAPSHOT" "$TARGET"
+fi
+$HDFS dfs -createSnapshot "$TARGET" "$SNAPSHOT"
+
+# only the latest snapshot is required for the next run
+for snapshot_root in "$SOURCE" "$TARGET"
+do
+    for expired in $($HDFS dfs -ls -C "$snapshot_root/.snapshot" | xargs -r -n1 basename | grep '^{{ $.snapshotPrefix }}' | grep -v "^$SNAPSHOT$" || true)
+    do
+        $HDFS dfs -deleteSnapshot "$snapshot_root" "$expired"
+    done
+done
+{{- else }}
+$DISTCP -update{{ if $.delete }} -delete{{ end }} "$SOURCE" "$TARGET"
+{{- end }}
+{{- end }}
+`
+
+// distcpPath is a replicated path, the script reads the source and the target from the environment variables
+type distcpPath struct {
+	SourceEnv string
+	TargetEnv string
+}
+
+// pathEnvs returns the environment variables with the source and target URIs of the replicated paths
+func pathEnvs(instance *hdfsv1alpha1.HdfsReplication) []corev1.EnvVar {
+	paths := distcpPaths(instance)
+	envs := make([]corev1.EnvVar, 0, 2*len(paths))
+	for i, p := range instance.Spec.Paths {
+		target := p.Target
+		if target == "" {
+			target = p.Source
+		}
+		envs = append(envs,
+			corev1.EnvVar{Name: paths[i].SourceEnv, Value: fmt.Sprintf("hdfs://%s%s", SourceNameservice, p.Source)},
+			corev1.EnvVar{Name: paths[i].TargetEnv, Value: fmt.Sprintf("hdfs://%s%s", TargetNameservice, target)},
+		)
+	}
+	return envs
+}
+
+func distcpPaths(instance *hdfsv1alpha1.HdfsReplication) []distcpPath {
+	paths := make([]distcpPath, 0, len(instance.Spec.Paths))
+	for i := range instance.Spec.Paths {
+		paths = append(paths, distcpPath{
+			SourceEnv: fmt.Sprintf("DISTCP_SOURCE_%d", i),
+			TargetEnv: fmt.Sprintf("DISTCP_TARGET_%d", i),
+		})
+	}
+	return paths
+}
+
+func args(instance *hdfsv1alpha1.HdfsReplication) []string {
+	spec := instance.Spec
+	paths := distcpPaths(instance)
+
+	mapCount := spec.Maps
+	if mapCount == 0 {
+		mapCount = 20
+	}
+	distcpOptions := []string{fmt.Sprintf("-m %d", mapCount)}
+	if spec.Bandwidth != nil {
+		distcpOptions = append(distcpOptions, fmt.Sprintf("-bandwidth %d", *spec.Bandwidth))
+	}
+
+	data := map[string]interface{}{
+		"configDir":       distcpConfigDir,
+		"mountConfigDir":  distcpMountedConfigDir,
+		"kerberosEnabled": isKerberosEnabled(instance),
+		"distcpOptions":   strings.Join(distcpOptions, " "),
+		"snapshotPrefix":  distcpSnapshotPrefix,
+		"paths":           paths,
+		"diff":            spec.Mode == hdfsv1alpha1.ReplicationModeDiff,
+		"delete":          spec.Delete,
+	}
+	var clusterDomain string
+	if isKerberosEnabled(instance) {
+		clusterDomain = instance.Spec.Kerberos.ClusterDomain
+	}
+	principal := common.CreateServiceKerberosPrincipal(distcpKerberosService, instance.Name, instance.Namespace, clusterDomain)
+	maps.Copy(data, common.CreateGetKerberosTicketData(principal))
+	return common.ParseTemplate(distcpScriptTemplate, data)
+}
@@ -0,0 +1,178 @@
+package replication
+
+import (
+	"context"
+	"time"
+
+	"emperror.dev/errors"
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/operator-go/pkg/client"
+	"github.com/zncdatadev/operator-go/pkg/util"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var logger = ctrl.Log.WithName("replication")
+
+// statusRefreshInterval is the interval used to refresh the lag of the replication
+const statusRefreshInterval = time.Minute
+
+// Reconciler reconciles the merged client config and the DistCp CronJob of a HdfsReplication
+type Reconciler struct {
+	client   *client.Client
+	instance *hdfsv1alpha1.HdfsReplication
+	image    *util.Image
+}
+
+func NewReconciler(client *client.Client, instance *hdfsv1alpha1.HdfsReplication, image *util.Image) *Reconciler {
+	return &Reconciler{
+		client:   client,
+		instance: instance,
+		image:    image,
+	}
+}
+
+func (r *Reconciler) Reconcile(ctx context.Context) (ctrl.Result, error) {
+	status := r.instance.Status.DeepCopy()
+
+	if err := r.reconcileResources(ctx); err != nil {
+		logger.Error(err, "failed to reconcile replication resources", "namespace", r.instance.Namespace, "name", r.instance.Name)
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               hdfsv1alpha1.ReplicationConditionReady,
+			Status:             metav1.ConditionFalse,
+			Reason:             "ReconcileFailed",
+			Message:            err.Error(),
+			ObservedGeneration: r.instance.Generation,
+		})
+		if updateErr := r.updateStatus(ctx, status); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
+		// the referenced clusters may not exist yet
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               hdfsv1alpha1.ReplicationConditionReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Reconciled",
+		Message:            "replication CronJob is up to date",
+		ObservedGeneration: r.instance.Generation,
+	})
+
+	if err := r.observeJobs(ctx, status); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.updateStatus(ctx, status); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: statusRefreshInterval}, nil
+}
+
+func (r *Reconciler) reconcileResources(ctx context.Context) error {
+	namespace := r.instance.Namespace
+	source, err := loadClusterConfig(ctx, r.client, namespace, &r.instance.Spec.Source)
+	if err != nil {
+		return errors.WrapIf(err, "failed to load source cluster config")
+	}
+	target, err := loadClusterConfig(ctx, r.client, namespace, &r.instance.Spec.Target)
+	if err != nil {
+		return errors.WrapIf(err, "failed to load target cluster config")
+	}
+
+	coreSite, hdfsSite := mergeClientConfigs(source, target, isKerberosEnabled(r.instance))
+	if _, err := r.client.CreateOrUpdate(ctx, buildConfigMap(r.instance, coreSite, hdfsSite)); err != nil {
+		return err
+	}
+	if _, err := r.client.CreateOrUpdate(ctx, buildCronJob(r.instance, r.image)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// observeJobs updates the status from the jobs created by the CronJob
+func (r *Reconciler) observeJobs(ctx context.Context, status *hdfsv1alpha1.HdfsReplicationStatus) error {
+	cronJob := &batchv1.CronJob{}
+	if err := r.client.GetWithOwnerNamespace(ctx, r.instance.Name, cronJob); err != nil {
+		return ctrlclient.IgnoreNotFound(err)
+	}
+	status.LastScheduleTime = cronJob.Status.LastScheduleTime
+
+	jobs := &batchv1.JobList{}
+	if err := r.client.Client.List(
+		ctx,
+		jobs,
+		ctrlclient.InNamespace(r.instance.Namespace),
+		ctrlclient.MatchingLabels{LabelReplication: r.instance.Name},
+	); err != nil {
+		return err
+	}
+
+	var lastFinished, lastSucceeded *batchv1.Job
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		finishedType, finished := jobFinished(job)
+		if !finished {
+			continue
+		}
+		if lastFinished == nil || job.CreationTimestamp.After(lastFinished.CreationTimestamp.Time) {
+			lastFinished = job
+		}
+		if finishedType == batchv1.JobComplete && job.Status.StartTime != nil && job.Status.CompletionTime != nil &&
+			(lastSucceeded == nil || job.Status.StartTime.After(lastSucceeded.Status.StartTime.Time)) {
+			lastSucceeded = job
+		}
+	}
+
+	// jobs are removed by the history limit, so the last success is only moved forward
+	if lastSucceeded != nil && (status.LastSuccessTime == nil || lastSucceeded.Status.CompletionTime.After(status.LastSuccessTime.Time)) {
+		status.LastSuccessTime = lastSucceeded.Status.CompletionTime
+		status.LastSyncedTime = lastSucceeded.Status.StartTime
+	}
+	if status.LastSyncedTime != nil {
+		status.LagSeconds = ptr.To(int64(time.Since(status.LastSyncedTime.Time).Seconds()))
+	}
+
+	if lastFinished != nil {
+		status.LastJobName = lastFinished.Name
+		if finishedType, _ := jobFinished(lastFinished); finishedType == batchv1.JobComplete {
+			meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+				Type:               hdfsv1alpha1.ReplicationConditionSucceeded,
+				Status:             metav1.ConditionTrue,
+				Reason:             "JobSucceeded",
+				Message:            "job " + lastFinished.Name + " succeeded",
+				ObservedGeneration: r.instance.Generation,
+			})
+		} else {
+			meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+				Type:               hdfsv1alpha1.ReplicationConditionSucceeded,
+				Status:             metav1.ConditionFalse,
+				Reason:             "JobFailed",
+				Message:            "job " + lastFinished.Name + " failed",
+				ObservedGeneration: r.instance.Generation,
+			})
+		}
+	}
+	return nil
+}
+
+func jobFinished(job *batchv1.Job) (batchv1.JobConditionType, bool) {
+	for _, condition := range job.Status.Conditions {
+		if (condition.Type == batchv1.JobComplete || condition.Type == batchv1.JobFailed) && condition.Status == corev1.ConditionTrue {
+			return condition.Type, true
+		}
+	}
+	return "", false
+}
+
+func (r *Reconciler) updateStatus(ctx context.Context, status *hdfsv1alpha1.HdfsReplicationStatus) error {
+	if equality.Semantic.DeepEqual(&r.instance.Status, status) {
+		return nil
+	}
+	r.instance.Status = *status
+	return r.client.Client.Status().Update(ctx, r.instance)
+}
@@ -0,0 +1,511 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/common"
+	"github.com/zncdatadev/hdfs-operator/internal/constant"
+	pkgclient "github.com/zncdatadev/operator-go/pkg/client"
+	opconstants "github.com/zncdatadev/operator-go/pkg/constants"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	"github.com/zncdatadev/operator-go/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var rollingRestartLog = ctrl.Log.WithName("rolling-restart")
+
+const (
+	failoverContainerName = "failover"
+
+	rollingRestartRequeueAfter = 10 * time.Second
+
+	// a failed failover is retried with a new job after a backoff, doubled after every failed attempt
+	rollingRestartFailoverMaxAttempts    = 5
+	rollingRestartFailoverInitialBackoff = 30 * time.Second
+	// rollingRestartFailoverTimeout is the time the active namenode has to become standby after the
+	// failover job completed, the attempt failed if it is still active
+	rollingRestartFailoverTimeout = 2 * time.Minute
+)
+
+const failoverScriptTemplate = `
+# with automatic failover, the failover is coordinated by the ZKFCs of the namenodes
+/kubedoop/hadoop/bin/hdfs haadmin -failover {{ .from }} {{ .to }}
+`
+
+// restartJmx reads the HA state of the namenodes and the block reports of the datanodes, it is shared
+// by the restarts of all clusters
+var restartJmx = NewJmxClient()
+
+var _ reconciler.Reconciler = &RollingRestartReconciler{}
+
+// RollingRestartReconciler restarts the pods of the role groups with a `restartRequestedAt` request. The pods
+// created before the request are deleted one after another, the StatefulSet recreates them. The next pod is
+// restarted once all pods of the role group are ready again:
+//   - the journalnodes are restarted one at a time
+//   - the standby namenodes are restarted first, then the active namenode fails over to a standby with
+//     `haadmin -failover` and is restarted as a standby
+//   - the datanodes are restarted one at a time, the restarted datanode has sent its block reports to all
+//     namenodes before the next one is restarted
+//
+// The role groups are restarted one after another: the journalnodes, the namenodes and the datanodes.
+// The restart is not started before the bring-up is completed and not while the cluster is stopped.
+type RollingRestartReconciler struct {
+	client      *pkgclient.Client
+	instance    *hdfsv1alpha1.HdfsCluster
+	clusterInfo reconciler.ClusterInfo
+	image       *util.Image
+	recorder    *EventRecorder
+}
+
+func NewRollingRestartReconciler(
+	client *pkgclient.Client,
+	instance *hdfsv1alpha1.HdfsCluster,
+	clusterInfo reconciler.ClusterInfo,
+	image *util.Image,
+	recorder *EventRecorder,
+) *RollingRestartReconciler {
+	return &RollingRestartReconciler{
+		client:      client,
+		instance:    instance,
+		clusterInfo: clusterInfo,
+		image:       image,
+		recorder:    recorder,
+	}
+}
+
+func (r *RollingRestartReconciler) GetName() string {
+	return r.instance.Name + "-rolling-restart"
+}
+
+func (r *RollingRestartReconciler) GetNamespace() string {
+	return r.instance.Namespace
+}
+
+func (r *RollingRestartReconciler) GetClient() *pkgclient.Client {
+	return r.client
+}
+
+// restartTarget is a role group with a restart request
+type restartTarget struct {
+	role        constant.Role
+	roleGroup   string
+	replicas    int32
+	requestedAt metav1.Time
+}
+
+func (t restartTarget) key() string {
+	return string(t.role) + "/" + t.roleGroup
+}
+
+func (t restartTarget) scheduled() bool {
+	return t.requestedAt.After(time.Now())
+}
+
+// targets returns the role groups with a restart request in the order they are restarted. The request of a
+// role group is the latest of the requests of the cluster, the role and the role group.
+func (r *RollingRestartReconciler) targets() []restartTarget {
+	spec := &r.instance.Spec
+	roles := []struct {
+		role constant.Role
+		spec *hdfsv1alpha1.RoleSpec
+	}{
+		{role: constant.JournalNode, spec: spec.JournalNode},
+		{role: constant.NameNode},
+		{role: constant.DataNode},
+	}
+	if spec.NameNode != nil {
+		roles[1].spec = &spec.NameNode.RoleSpec
+	}
+	if spec.DataNode != nil {
+		roles[2].spec = &spec.DataNode.RoleSpec
+	}
+
+	var targets []restartTarget
+	for _, role := range roles {
+		if role.spec == nil {
+			continue
+		}
+		groups := make([]string, 0, len(role.spec.RoleGroups))
+		for name := range role.spec.RoleGroups {
+			groups = append(groups, name)
+		}
+		slices.Sort(groups)
+		for _, name := range groups {
+			group := role.spec.RoleGroups[name]
+			requestedAt := latestTime(spec.RestartRequestedAt, role.spec.RestartRequestedAt, group.RestartRequestedAt)
+			if requestedAt == nil {
+				continue
+			}
+			targets = append(targets, restartTarget{
+				role:        role.role,
+				roleGroup:   name,
+				replicas:    ptr.Deref(group.Replicas, 1),
+				requestedAt: *requestedAt,
+			})
+		}
+	}
+	return targets
+}
+
+func latestTime(times ...*metav1.Time) *metav1.Time {
+	var latest *metav1.Time
+	for _, t := range times {
+		if t != nil && (latest == nil || latest.Before(t)) {
+			latest = t
+		}
+	}
+	return latest
+}
+
+func (r *RollingRestartReconciler) paused() bool {
+	if r.instance.Spec.ClusterOperationSpec != nil && r.instance.Spec.ClusterOperationSpec.Stopped {
+		return true
+	}
+	bringUp := r.instance.Status.BringUp
+	return r.instance.Status.Stop != nil || bringUp == nil || bringUp.Phase != hdfsv1alpha1.BringUpPhaseCompleted
+}
+
+// Reconcile restarts the next pod of the first role group with a pending restart, it requeues until
+// the restart of the role group is finished
+func (r *RollingRestartReconciler) Reconcile(ctx context.Context) (ctrl.Result, error) {
+	if r.paused() {
+		return ctrl.Result{}, nil
+	}
+	for _, target := range r.targets() {
+		if target.scheduled() {
+			continue
+		}
+		// a failed restart holds back the following role groups until the restart is requested again
+		if status := r.instance.Status.Restarts[target.key()]; status.Phase == hdfsv1alpha1.RestartPhaseFailed &&
+			status.RequestedAt.Equal(&target.requestedAt) {
+			return ctrl.Result{}, nil
+		}
+		if result, err := r.restart(ctx, target); !result.IsZero() || err != nil {
+			return result, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// Ready requeues at the time of the next scheduled restart
+func (r *RollingRestartReconciler) Ready(ctx context.Context) (ctrl.Result, error) {
+	if r.paused() {
+		return ctrl.Result{}, nil
+	}
+	var requeueAfter time.Duration
+	for _, target := range r.targets() {
+		if wait := time.Until(target.requestedAt.Time); wait > 0 && (requeueAfter == 0 || wait < requeueAfter) {
+			requeueAfter = wait
+		}
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+func (r *RollingRestartReconciler) restart(ctx context.Context, target restartTarget) (ctrl.Result, error) {
+	pods, err := r.pods(ctx, target)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	var stale []corev1.Pod
+	for _, pod := range pods {
+		if pod.CreationTimestamp.Before(&target.requestedAt) {
+			stale = append(stale, pod)
+		}
+	}
+
+	status := r.instance.Status.Restarts[target.key()]
+	handled := status.RequestedAt.Equal(&target.requestedAt)
+	if handled && status.Phase == hdfsv1alpha1.RestartPhaseRestarted {
+		return ctrl.Result{}, nil
+	}
+	if !handled {
+		if len(stale) == 0 {
+			// the pods were created after the request
+			return ctrl.Result{}, r.setStatus(ctx, target, hdfsv1alpha1.RestartPhaseRestarted, "", status.LastRestartTime)
+		}
+		r.recorder.Normal(r.instance, EventReasonRestartStarted, "Restart", "Restarting role group %s", target.key())
+	}
+
+	message, err := r.unsettled(ctx, target, pods)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if message != "" {
+		return ctrl.Result{RequeueAfter: rollingRestartRequeueAfter},
+			r.setStatus(ctx, target, hdfsv1alpha1.RestartPhaseRestarting, message, status.LastRestartTime)
+	}
+	if len(stale) == 0 {
+		rollingRestartLog.Info("Restarted role group", "cluster", r.instance.Name, "roleGroup", target.key())
+		r.recorder.Normal(r.instance, EventReasonRestartFinished, "Restart", "Restarted role group %s", target.key())
+		return ctrl.Result{}, r.setStatus(ctx, target, hdfsv1alpha1.RestartPhaseRestarted, "", ptr.To(metav1.Now()))
+	}
+
+	if target.role == constant.NameNode {
+		return r.restartNameNode(ctx, target, stale, status)
+	}
+	return r.restartPod(ctx, target, &stale[0], status)
+}
+
+// unsettled describes why the role group is not ready for the restart of the next pod, it is empty if it is
+func (r *RollingRestartReconciler) unsettled(ctx context.Context, target restartTarget, pods []corev1.Pod) (string, error) {
+	if int32(len(pods)) != target.replicas {
+		return fmt.Sprintf("%d of %d pods exist", len(pods), target.replicas), nil
+	}
+	var newest *corev1.Pod
+	for i := range pods {
+		pod := &pods[i]
+		if pod.DeletionTimestamp != nil {
+			return fmt.Sprintf("pod %s is terminating", pod.Name), nil
+		}
+		if !podReady(pod) {
+			return fmt.Sprintf("pod %s is not ready", pod.Name), nil
+		}
+		if newest == nil || newest.CreationTimestamp.Before(&pod.CreationTimestamp) {
+			newest = pod
+		}
+	}
+	// the datanode restarted last has re-registered with all namenodes
+	if target.role == constant.DataNode && newest != nil && !newest.CreationTimestamp.Before(&target.requestedAt) {
+		return r.dataNodeUnregistered(ctx, newest)
+	}
+	return "", nil
+}
+
+// dataNodeUnregistered describes why the datanode has not re-registered, it is empty if it has sent a block report
+// to all namenodes
+func (r *RollingRestartReconciler) dataNodeUnregistered(ctx context.Context, pod *corev1.Pod) (string, error) {
+	bean := &DataNodeActors{}
+	if err := restartJmx.Bean(ctx, r.instance.Spec.ClusterConfig, constant.DataNode, pod, dataNodeInfoBean, bean); err != nil {
+		return fmt.Sprintf("failed to read datanode %s: %s", pod.Name, err), nil
+	}
+	actors, err := parseActors(bean.BPServiceActorInfo)
+	if err != nil {
+		return "", err
+	}
+	if len(actors) == 0 {
+		return fmt.Sprintf("datanode %s is not connected to the namenodes", pod.Name), nil
+	}
+	for _, actor := range actors {
+		if actor.ActorState != "RUNNING" || actor.MaxBlockReportSize == "" || actor.MaxBlockReportSize == "0" {
+			return fmt.Sprintf("datanode %s has not sent its block report to %s", pod.Name, actor.NamenodeAddress), nil
+		}
+	}
+	return "", nil
+}
+
+// restartNameNode restarts the standby namenodes first. The active namenode is restarted once it failed over
+// to a standby namenode, unless there is none.
+func (r *RollingRestartReconciler) restartNameNode(
+	ctx context.Context,
+	target restartTarget,
+	stale []corev1.Pod,
+	status hdfsv1alpha1.RestartStatus,
+) (ctrl.Result, error) {
+	states, message, err := r.nameNodeStates(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if message != "" {
+		return ctrl.Result{RequeueAfter: rollingRestartRequeueAfter},
+			r.setStatus(ctx, target, hdfsv1alpha1.RestartPhaseRestarting, message, status.LastRestartTime)
+	}
+	for i := range stale {
+		if states[stale[i].Name] != "active" {
+			return r.restartPod(ctx, target, &stale[i], status)
+		}
+	}
+
+	active := &stale[0]
+	var standbys []string
+	for name, state := range states {
+		if state == "standby" {
+			standbys = append(standbys, name)
+		}
+	}
+	if len(standbys) == 0 {
+		rollingRestartLog.Info("No standby namenode to fail over to, restarting the active namenode",
+			"cluster", r.instance.Name, "pod", active.Name)
+		return r.restartPod(ctx, target, active, status)
+	}
+	slices.Sort(standbys)
+	return r.failover(ctx, target, active.Name, standbys[0], status)
+}
+
+// failover runs a job failing the active namenode over to the standby, the active namenode is restarted
+// once it is standby. The failover is retried with a new job after a backoff if the job failed or the
+// namenode is still active after the job completed, the restart fails after the last attempt.
+func (r *RollingRestartReconciler) failover(
+	ctx context.Context,
+	target restartTarget,
+	active string,
+	standby string,
+	status hdfsv1alpha1.RestartStatus,
+) (ctrl.Result, error) {
+	sum := sha256.Sum256([]byte(active + "/" + target.requestedAt.UTC().Format(time.RFC3339)))
+	name := fmt.Sprintf("%s-failover-%s", r.instance.Name, hex.EncodeToString(sum[:])[:10])
+	jobName := func(attempt int) string { return attemptJobName(name, attempt) }
+
+	job, attempt, err := lastAttemptJob(ctx, r.client.Client, r.GetNamespace(), rollingRestartFailoverMaxAttempts, jobName)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if job != nil {
+		finished, failure := jobFinished(job)
+		if !finished {
+			return ctrl.Result{RequeueAfter: rollingRestartRequeueAfter}, r.setStatus(ctx, target, hdfsv1alpha1.RestartPhaseRestarting,
+				fmt.Sprintf("waiting for job %s", job.Name), status.LastRestartTime)
+		}
+		failedAt := jobFailedTime(job)
+		if failedAt == nil {
+			// the job completed, but the namenode has not become standby yet
+			if job.Status.CompletionTime == nil || time.Since(job.Status.CompletionTime.Time) < rollingRestartFailoverTimeout {
+				return ctrl.Result{RequeueAfter: rollingRestartRequeueAfter}, r.setStatus(ctx, target, hdfsv1alpha1.RestartPhaseRestarting,
+					fmt.Sprintf("waiting for namenode %s to become standby", active), status.LastRestartTime)
+			}
+			failedAt = ptr.To(job.Status.CompletionTime.Add(rollingRestartFailoverTimeout))
+			failure = fmt.Sprintf("namenode %s is still active", active)
+		}
+		if attempt+1 >= rollingRestartFailoverMaxAttempts {
+			rollingRestartLog.Info("Failed to fail over the active namenode, giving up", "cluster", r.instance.Name,
+				"from", active, "job", job.Name, "attempts", attempt+1, "reason", failure)
+			r.recorder.Warning(r.instance, EventReasonRestartFailed, "Restart",
+				"Failed to fail over from %s after %d attempts, request the restart again to retry: %s", active, attempt+1, failure)
+			return ctrl.Result{}, r.setStatus(ctx, target, hdfsv1alpha1.RestartPhaseFailed,
+				fmt.Sprintf("failed to fail over from %s after %d attempts: %s", active, attempt+1, failure), status.LastRestartTime)
+		}
+		if wait := time.Until(failedAt.Add(failoverBackoff(attempt))); wait > 0 {
+			return ctrl.Result{RequeueAfter: wait}, r.setStatus(ctx, target, hdfsv1alpha1.RestartPhaseRestarting,
+				fmt.Sprintf("job %s failed to fail over, retrying: %s", job.Name, failure), status.LastRestartTime)
+		}
+		attempt++
+	}
+
+	ldapProvider, err := common.ResolveLdapGroupMapping(ctx, r.client, r.instance.Spec.ClusterConfig)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	adminJob := &nameNodeAdminJob{
+		instance:    r.instance,
+		clusterInfo: r.clusterInfo,
+		image:       r.image,
+		container:   failoverContainerName,
+	}
+	data := map[string]any{"from": active, "to": standby}
+	job, err = adminJob.build(jobName(attempt), nil, failoverScriptTemplate, data, ldapProvider)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.client.CreateDoesNotExist(ctx, job); err != nil {
+		return ctrl.Result{}, err
+	}
+	rollingRestartLog.Info("Failing over the active namenode", "cluster", r.instance.Name, "from", active, "to", standby,
+		"job", job.Name, "attempt", attempt+1)
+	return ctrl.Result{RequeueAfter: rollingRestartRequeueAfter}, r.setStatus(ctx, target, hdfsv1alpha1.RestartPhaseRestarting,
+		fmt.Sprintf("failing over from %s to %s", active, standby), status.LastRestartTime)
+}
+
+// failoverBackoff returns the time to wait before the failover attempt after the given failed attempt
+func failoverBackoff(attempt int) time.Duration {
+	return rollingRestartFailoverInitialBackoff << attempt
+}
+
+// nameNodeStates reads the HA states of the namenodes of all role groups. The message describes a namenode
+// whose state could not be read, so no namenode is restarted while the active one is unknown.
+func (r *RollingRestartReconciler) nameNodeStates(ctx context.Context) (map[string]string, string, error) {
+	pods := &corev1.PodList{}
+	if err := r.client.Client.List(ctx, pods,
+		ctrlclient.InNamespace(r.instance.Namespace),
+		ctrlclient.MatchingLabels{
+			opconstants.LabelKubernetesInstance:  r.instance.Name,
+			opconstants.LabelKubernetesComponent: string(constant.NameNode),
+		},
+	); err != nil {
+		return nil, "", err
+	}
+	states := make(map[string]string, len(pods.Items))
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		namesystem := &FSNamesystem{}
+		if err := restartJmx.NameNodeBean(ctx, r.instance.Spec.ClusterConfig, pod, fsNamesystemBean, namesystem); err != nil {
+			return nil, fmt.Sprintf("failed to read the HA state of namenode %s: %s", pod.Name, err), nil
+		}
+		states[pod.Name] = namesystem.HAState
+	}
+	return states, "", nil
+}
+
+// restartPod deletes the pod, the StatefulSet recreates it
+func (r *RollingRestartReconciler) restartPod(
+	ctx context.Context,
+	target restartTarget,
+	pod *corev1.Pod,
+	status hdfsv1alpha1.RestartStatus,
+) (ctrl.Result, error) {
+	rollingRestartLog.Info("Restarting pod", "cluster", r.instance.Name, "roleGroup", target.key(), "pod", pod.Name)
+	if err := r.client.Client.Delete(ctx, pod, ctrlclient.Preconditions{UID: &pod.UID}); ctrlclient.IgnoreNotFound(err) != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: rollingRestartRequeueAfter}, r.setStatus(ctx, target, hdfsv1alpha1.RestartPhaseRestarting,
+		fmt.Sprintf("restarting pod %s", pod.Name), status.LastRestartTime)
+}
+
+func (r *RollingRestartReconciler) pods(ctx context.Context, target restartTarget) ([]corev1.Pod, error) {
+	pods := &corev1.PodList{}
+	if err := r.client.Client.List(ctx, pods,
+		ctrlclient.InNamespace(r.instance.Namespace),
+		ctrlclient.MatchingLabels{
+			opconstants.LabelKubernetesInstance:  r.instance.Name,
+			opconstants.LabelKubernetesComponent: string(target.role),
+			opconstants.LabelKubernetesRoleGroup: target.roleGroup,
+		},
+	); err != nil {
+		return nil, err
+	}
+	slices.SortFunc(pods.Items, func(a, b corev1.Pod) int { return strings.Compare(a.Name, b.Name) })
+	return pods.Items, nil
+}
+
+func podReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// setStatus records the restart of the role group in the status if it changed
+func (r *RollingRestartReconciler) setStatus(
+	ctx context.Context,
+	target restartTarget,
+	phase hdfsv1alpha1.RestartPhase,
+	message string,
+	lastRestartTime *metav1.Time,
+) error {
+	current, ok := r.instance.Status.Restarts[target.key()]
+	if ok && current.RequestedAt.Equal(&target.requestedAt) && current.Phase == phase && current.Message == message &&
+		current.LastRestartTime.Equal(lastRestartTime) {
+		return nil
+	}
+	if r.instance.Status.Restarts == nil {
+		r.instance.Status.Restarts = make(map[string]hdfsv1alpha1.RestartStatus)
+	}
+	r.instance.Status.Restarts[target.key()] = hdfsv1alpha1.RestartStatus{
+		RequestedAt:     target.requestedAt.DeepCopy(),
+		Phase:           phase,
+		Message:         message,
+		LastRestartTime: lastRestartTime,
+	}
+	return r.client.Client.Status().Update(ctx, r.instance)
+}
@@ -0,0 +1,247 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"testing"
+	"time"
+
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/constant"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// testFailoverJobName returns the name of the job of the attempt to fail over from the active namenode
+func testFailoverJobName(active string, requestedAt time.Time, attempt int) string {
+	sum := sha256.Sum256([]byte(active + "/" + requestedAt.UTC().Format(time.RFC3339)))
+	return attemptJobName("hdfs-failover-"+hex.EncodeToString(sum[:])[:10], attempt)
+}
+
+func TestRollingRestartReconciler(t *testing.T) {
+	// the times are stored with a precision of seconds
+	requestedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	stale := requestedAt.Add(-time.Hour)
+	restarted := requestedAt.Add(time.Minute)
+
+	nameNode0 := "hdfs-namenode-default-0"
+	nameNode1 := "hdfs-namenode-default-1"
+	failoverJob := func(attempt int) string { return testFailoverJobName(nameNode0, requestedAt, attempt) }
+	failedJobs := func(attempts int, lastFailedAt time.Time) []ctrlclient.Object {
+		var jobs []ctrlclient.Object
+		for attempt := 0; attempt < attempts-1; attempt++ {
+			jobs = append(jobs, testJob(failoverJob(attempt), true, requestedAt))
+		}
+		return append(jobs, testJob(failoverJob(attempts-1), true, lastFailedAt))
+	}
+
+	tests := []struct {
+		name      string
+		nameNodes int32
+		bringUp   hdfsv1alpha1.BringUpPhase
+		// phase is the phase of the restart of the namenodes, the restart is not handled yet if it is empty
+		phase hdfsv1alpha1.RestartPhase
+		// created are the creation times of the namenode pods
+		created []time.Time
+		// states are the HA states of the namenode pods
+		states map[string]string
+		// notReady is a namenode pod which is not ready
+		notReady    string
+		objects     []ctrlclient.Object
+		wantPhase   hdfsv1alpha1.RestartPhase
+		wantMessage string
+		wantDeleted string
+		wantJob     string
+		wantEvents  []string
+	}{
+		{
+			name:        "standby restarted before the active namenode",
+			created:     []time.Time{stale, stale},
+			states:      map[string]string{nameNode0: "active", nameNode1: "standby"},
+			wantPhase:   hdfsv1alpha1.RestartPhaseRestarting,
+			wantMessage: "restarting pod " + nameNode1,
+			wantDeleted: nameNode1,
+			wantEvents:  []string{EventReasonRestartStarted},
+		},
+		{
+			name:        "active namenode fails over to the standby",
+			phase:       hdfsv1alpha1.RestartPhaseRestarting,
+			created:     []time.Time{stale, restarted},
+			states:      map[string]string{nameNode0: "active", nameNode1: "standby"},
+			wantPhase:   hdfsv1alpha1.RestartPhaseRestarting,
+			wantMessage: "failing over from " + nameNode0 + " to " + nameNode1,
+			wantJob:     failoverJob(0),
+		},
+		{
+			name:        "active namenode restarted without a standby",
+			nameNodes:   1,
+			created:     []time.Time{stale},
+			states:      map[string]string{nameNode0: "active"},
+			wantPhase:   hdfsv1alpha1.RestartPhaseRestarting,
+			wantMessage: "restarting pod " + nameNode0,
+			wantDeleted: nameNode0,
+			wantEvents:  []string{EventReasonRestartStarted},
+		},
+		{
+			name:        "waiting for the failover",
+			phase:       hdfsv1alpha1.RestartPhaseRestarting,
+			created:     []time.Time{stale, restarted},
+			states:      map[string]string{nameNode0: "active", nameNode1: "standby"},
+			objects:     []ctrlclient.Object{testJob(failoverJob(0), false, time.Time{})},
+			wantPhase:   hdfsv1alpha1.RestartPhaseRestarting,
+			wantMessage: "waiting for job " + failoverJob(0),
+			wantJob:     failoverJob(0),
+		},
+		{
+			name:        "waiting for the active namenode to become standby",
+			phase:       hdfsv1alpha1.RestartPhaseRestarting,
+			created:     []time.Time{stale, restarted},
+			states:      map[string]string{nameNode0: "active", nameNode1: "standby"},
+			objects:     []ctrlclient.Object{testJob(failoverJob(0), false, time.Now())},
+			wantPhase:   hdfsv1alpha1.RestartPhaseRestarting,
+			wantMessage: "waiting for namenode " + nameNode0 + " to become standby",
+			wantJob:     failoverJob(0),
+		},
+		{
+			name:        "active namenode restarted after the failover",
+			phase:       hdfsv1alpha1.RestartPhaseRestarting,
+			created:     []time.Time{stale, restarted},
+			states:      map[string]string{nameNode0: "standby", nameNode1: "active"},
+			objects:     []ctrlclient.Object{testJob(failoverJob(0), false, time.Now())},
+			wantPhase:   hdfsv1alpha1.RestartPhaseRestarting,
+			wantMessage: "restarting pod " + nameNode0,
+			wantDeleted: nameNode0,
+			wantJob:     failoverJob(0),
+		},
+		{
+			name:        "failover retried after the backoff",
+			phase:       hdfsv1alpha1.RestartPhaseRestarting,
+			created:     []time.Time{stale, restarted},
+			states:      map[string]string{nameNode0: "active", nameNode1: "standby"},
+			objects:     failedJobs(1, requestedAt),
+			wantPhase:   hdfsv1alpha1.RestartPhaseRestarting,
+			wantMessage: "failing over from " + nameNode0 + " to " + nameNode1,
+			wantJob:     failoverJob(1),
+		},
+		{
+			name:        "failover waiting for the backoff",
+			phase:       hdfsv1alpha1.RestartPhaseRestarting,
+			created:     []time.Time{stale, restarted},
+			states:      map[string]string{nameNode0: "active", nameNode1: "standby"},
+			objects:     failedJobs(2, time.Now()),
+			wantPhase:   hdfsv1alpha1.RestartPhaseRestarting,
+			wantMessage: "job " + failoverJob(1) + " failed to fail over, retrying: BackoffLimitExceeded",
+			wantJob:     failoverJob(1),
+		},
+		{
+			name:        "failover retries exhausted",
+			phase:       hdfsv1alpha1.RestartPhaseRestarting,
+			created:     []time.Time{stale, restarted},
+			states:      map[string]string{nameNode0: "active", nameNode1: "standby"},
+			objects:     failedJobs(rollingRestartFailoverMaxAttempts, time.Now()),
+			wantPhase:   hdfsv1alpha1.RestartPhaseFailed,
+			wantMessage: "failed to fail over from " + nameNode0 + " after 5 attempts: BackoffLimitExceeded",
+			wantJob:     failoverJob(rollingRestartFailoverMaxAttempts - 1),
+			wantEvents:  []string{EventReasonRestartFailed},
+		},
+		{
+			name:      "failed restart is not retried",
+			phase:     hdfsv1alpha1.RestartPhaseFailed,
+			created:   []time.Time{stale, stale},
+			states:    map[string]string{nameNode0: "active", nameNode1: "standby"},
+			wantPhase: hdfsv1alpha1.RestartPhaseFailed,
+		},
+		{
+			name:        "namenode not ready",
+			phase:       hdfsv1alpha1.RestartPhaseRestarting,
+			created:     []time.Time{stale, restarted},
+			states:      map[string]string{nameNode0: "active", nameNode1: "standby"},
+			notReady:    nameNode1,
+			wantPhase:   hdfsv1alpha1.RestartPhaseRestarting,
+			wantMessage: "pod " + nameNode1 + " is not ready",
+		},
+		{
+			name:       "restarted",
+			phase:      hdfsv1alpha1.RestartPhaseRestarting,
+			created:    []time.Time{restarted, restarted},
+			states:     map[string]string{nameNode0: "active", nameNode1: "standby"},
+			wantPhase:  hdfsv1alpha1.RestartPhaseRestarted,
+			wantEvents: []string{EventReasonRestartFinished},
+		},
+		{
+			name:    "paused during the bring-up",
+			bringUp: hdfsv1alpha1.BringUpPhaseDataNodes,
+			created: []time.Time{stale, stale},
+			states:  map[string]string{nameNode0: "active", nameNode1: "standby"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nameNodes := tt.nameNodes
+			if nameNodes == 0 {
+				nameNodes = 2
+			}
+			instance := newTestCluster(nameNodes, 1, 3)
+			instance.Spec.NameNode.RestartRequestedAt = ptr.To(metav1.NewTime(requestedAt))
+			bringUp := tt.bringUp
+			if bringUp == "" {
+				bringUp = hdfsv1alpha1.BringUpPhaseCompleted
+			}
+			instance.Status.BringUp = &hdfsv1alpha1.BringUpStatus{Phase: bringUp}
+			if tt.phase != "" {
+				instance.Status.Restarts = map[string]hdfsv1alpha1.RestartStatus{
+					"namenode/default": {RequestedAt: ptr.To(metav1.NewTime(requestedAt)), Phase: tt.phase},
+				}
+			}
+
+			objects := slices.Clone(tt.objects)
+			var pods []string
+			for i, created := range tt.created {
+				pod := testPod(constant.NameNode, i, created)
+				if pod.Name == tt.notReady {
+					pod.Status.Conditions[0].Status = corev1.ConditionFalse
+				}
+				pods = append(pods, pod.Name)
+				objects = append(objects, pod)
+			}
+			client := newFakeClient(t, instance, objects...)
+			setFakeJmx(t, &restartJmx, tt.states)
+			recorder, fakeRecorder := newTestRecorder()
+
+			restart := NewRollingRestartReconciler(client, instance, testClusterInfo(instance), testImage, recorder)
+			if _, err := restart.Reconcile(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			status := getCluster(t, client).Status.Restarts["namenode/default"]
+			if status.Phase != tt.wantPhase || status.Message != tt.wantMessage {
+				t.Errorf("got phase %q with message %q, want %q with %q", status.Phase, status.Message, tt.wantPhase, tt.wantMessage)
+			}
+
+			for _, pod := range pods {
+				if exists := podExists(t, client, pod); exists == (pod == tt.wantDeleted) {
+					t.Errorf("pod %s exists %v, want %v", pod, exists, pod != tt.wantDeleted)
+				}
+			}
+
+			for attempt := 0; attempt < rollingRestartFailoverMaxAttempts; attempt++ {
+				name := failoverJob(attempt)
+				// the jobs of the failed attempts are kept
+				want := name == tt.wantJob || slices.ContainsFunc(tt.objects, func(object ctrlclient.Object) bool {
+					return object.GetName() == name
+				})
+				if exists := jobExists(t, client, name); exists != want {
+					t.Errorf("job %s exists %v, want %v", name, exists, want)
+				}
+			}
+
+			if reasons := eventReasons(fakeRecorder); !slices.Equal(reasons, tt.wantEvents) {
+				t.Errorf("got events %v, want %v", reasons, tt.wantEvents)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/common"
+	"github.com/zncdatadev/hdfs-operator/internal/constant"
+	pkgclient "github.com/zncdatadev/operator-go/pkg/client"
+	opconstants "github.com/zncdatadev/operator-go/pkg/constants"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var statefulSetRecreateLog = ctrl.Log.WithName("statefulset-recreate")
+
+const statefulSetRecreateRequeueAfter = time.Second
+
+var _ reconciler.Reconciler = &StatefulSetRecreateReconciler{}
+
+// StatefulSetRecreateReconciler recreates the StatefulSets of a role whose immutable fields differ from the
+// ones the role builds. A StatefulSet is deleted with its pods orphaned and the role adopts them with the new
+// StatefulSet, which rolls them out one after another. It is registered before the role and holds it back
+// until the outdated StatefulSets are gone.
+type StatefulSetRecreateReconciler struct {
+	client   *pkgclient.Client
+	instance *hdfsv1alpha1.HdfsCluster
+	recorder *EventRecorder
+	role     constant.Role
+	// outdated describes why the StatefulSet is recreated, it is empty if the StatefulSet is up to date
+	outdated func(statefulSet *appsv1.StatefulSet) string
+}
+
+// NewJournalNodeRecreateReconciler recreates the journalnode StatefulSets created with the OrderedReady pod
+// management, the journalnodes are started in parallel since they are ready only once a namenode wrote to
+// their journals
+func NewJournalNodeRecreateReconciler(
+	client *pkgclient.Client,
+	instance *hdfsv1alpha1.HdfsCluster,
+	recorder *EventRecorder,
+) *StatefulSetRecreateReconciler {
+	return &StatefulSetRecreateReconciler{
+		client:   client,
+		instance: instance,
+		recorder: recorder,
+		role:     constant.JournalNode,
+		outdated: func(statefulSet *appsv1.StatefulSet) string {
+			if statefulSet.Spec.PodManagementPolicy == appsv1.ParallelPodManagement {
+				return ""
+			}
+			return "the journalnodes are started in parallel"
+		},
+	}
+}
+
+// NewDataNodeRecreateReconciler recreates the datanode StatefulSets whose PersistentVolumeClaim templates
+// differ from the data volumes of the datanodes, the volume claim templates of a StatefulSet are immutable
+func NewDataNodeRecreateReconciler(
+	client *pkgclient.Client,
+	instance *hdfsv1alpha1.HdfsCluster,
+	recorder *EventRecorder,
+) *StatefulSetRecreateReconciler {
+	return &StatefulSetRecreateReconciler{
+		client:   client,
+		instance: instance,
+		recorder: recorder,
+		role:     constant.DataNode,
+		outdated: func(statefulSet *appsv1.StatefulSet) string {
+			if dataVolumesChanged(statefulSet.Spec.VolumeClaimTemplates, common.DataNodeDataVolumes(instance)) {
+				return "the data volumes changed"
+			}
+			return ""
+		},
+	}
+}
+
+// dataVolumesChanged reports whether the PersistentVolumeClaim templates differ from the data volumes. The
+// capacity of a volume without a capacity is the storage capacity of the role group, which is not compared.
+func dataVolumesChanged(templates []corev1.PersistentVolumeClaim, volumes []hdfsv1alpha1.DataVolumeSpec) bool {
+	if len(templates) != len(volumes) {
+		return true
+	}
+	for i, volume := range volumes {
+		template := templates[i]
+		if template.Name != volume.Name || ptr.Deref(template.Spec.StorageClassName, "") != volume.StorageClass {
+			return true
+		}
+		if volume.Capacity != nil && template.Spec.Resources.Requests.Storage().Cmp(*volume.Capacity) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *StatefulSetRecreateReconciler) GetName() string {
+	return r.instance.Name + "-" + string(r.role) + "-recreate"
+}
+
+func (r *StatefulSetRecreateReconciler) GetNamespace() string {
+	return r.instance.Namespace
+}
+
+func (r *StatefulSetRecreateReconciler) GetClient() *pkgclient.Client {
+	return r.client
+}
+
+// Reconcile deletes the outdated StatefulSets of the role and requeues until they are gone
+func (r *StatefulSetRecreateReconciler) Reconcile(ctx context.Context) (ctrl.Result, error) {
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := r.client.Client.List(ctx, statefulSets,
+		ctrlclient.InNamespace(r.instance.Namespace),
+		ctrlclient.MatchingLabels{
+			opconstants.LabelKubernetesInstance:  r.instance.Name,
+			opconstants.LabelKubernetesComponent: string(r.role),
+		},
+	); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	pending := false
+	for i := range statefulSets.Items {
+		statefulSet := &statefulSets.Items[i]
+		reason := r.outdated(statefulSet)
+		if reason == "" {
+			continue
+		}
+		pending = true
+		if statefulSet.DeletionTimestamp != nil {
+			continue
+		}
+		statefulSetRecreateLog.Info("Recreating statefulset, the pods are kept",
+			"cluster", r.instance.Name, "statefulset", statefulSet.Name, "reason", reason)
+		if err := r.client.Client.Delete(ctx, statefulSet,
+			ctrlclient.PropagationPolicy(metav1.DeletePropagationOrphan)); err != nil && !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		r.recorder.Normal(r.instance, EventReasonStatefulSetRecreated, "Recreate",
+			"Recreating StatefulSet %s, its pods are kept: %s", statefulSet.Name, reason)
+	}
+	if pending {
+		return ctrl.Result{RequeueAfter: statefulSetRecreateRequeueAfter}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *StatefulSetRecreateReconciler) Ready(ctx context.Context) (ctrl.Result, error) {
+	return ctrl.Result{}, nil
+}
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"testing"
+
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// TestDataVolumesChanged compares the PersistentVolumeClaim templates of a datanode StatefulSet with the
+// data volumes, the templates are immutable
+func TestDataVolumesChanged(t *testing.T) {
+	template := func(name string, capacity string, storageClass string) corev1.PersistentVolumeClaim {
+		claim := corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		claim.Spec.Resources.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(capacity)}
+		if storageClass != "" {
+			claim.Spec.StorageClassName = ptr.To(storageClass)
+		}
+		return claim
+	}
+	data := template("data", "10Gi", "")
+	ssd := template("data-1", "100Gi", "local-ssd")
+
+	tests := []struct {
+		name      string
+		templates []corev1.PersistentVolumeClaim
+		volumes   []hdfsv1alpha1.DataVolumeSpec
+		want      bool
+	}{
+		{
+			name:      "default volume",
+			templates: []corev1.PersistentVolumeClaim{data},
+			volumes:   []hdfsv1alpha1.DataVolumeSpec{{Name: "data"}},
+		},
+		{
+			name:      "added volume",
+			templates: []corev1.PersistentVolumeClaim{data},
+			volumes:   []hdfsv1alpha1.DataVolumeSpec{{Name: "data"}, {Name: "data-1"}},
+			want:      true,
+		},
+		{
+			name:      "removed volume",
+			templates: []corev1.PersistentVolumeClaim{data, ssd},
+			volumes:   []hdfsv1alpha1.DataVolumeSpec{{Name: "data"}},
+			want:      true,
+		},
+		{
+			name:      "unchanged volumes",
+			templates: []corev1.PersistentVolumeClaim{data, ssd},
+			volumes: []hdfsv1alpha1.DataVolumeSpec{
+				{Name: "data"},
+				{Name: "data-1", Capacity: ptr.To(resource.MustParse("100Gi")), StorageClass: "local-ssd"},
+			},
+		},
+		{
+			name:      "changed capacity",
+			templates: []corev1.PersistentVolumeClaim{data, ssd},
+			volumes: []hdfsv1alpha1.DataVolumeSpec{
+				{Name: "data"},
+				{Name: "data-1", Capacity: ptr.To(resource.MustParse("200Gi")), StorageClass: "local-ssd"},
+			},
+			want: true,
+		},
+		{
+			name:      "changed storage class",
+			templates: []corev1.PersistentVolumeClaim{data},
+			volumes:   []hdfsv1alpha1.DataVolumeSpec{{Name: "data", StorageClass: "local-ssd"}},
+			want:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dataVolumesChanged(tt.templates, tt.volumes); got != tt.want {
+				t.Errorf("dataVolumesChanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/common"
+	"github.com/zncdatadev/hdfs-operator/internal/constant"
+	pkgclient "github.com/zncdatadev/operator-go/pkg/client"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	"github.com/zncdatadev/operator-go/pkg/util"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var clusterStopLog = ctrl.Log.WithName("cluster-stop")
+
+const (
+	saveNamespaceContainerName = "save-namespace"
+	leaveSafeModeContainerName = "leave-safe-mode"
+
+	// clusterStopPhaseRequeueAfter rebuilds the roles with the stopped roles of the new phase
+	clusterStopPhaseRequeueAfter = time.Second
+	clusterStopRequeueAfter      = 5 * time.Second
+)
+
+const saveNamespaceScriptTemplate = `
+# the commands apply to all namenodes of the nameservice, saving the namespace requires the safe mode
+/kubedoop/hadoop/bin/hdfs dfsadmin -safemode enter
+/kubedoop/hadoop/bin/hdfs dfsadmin -saveNamespace
+`
+
+const leaveSafeModeScriptTemplate = `
+/kubedoop/hadoop/bin/hdfs dfsadmin -safemode leave
+`
+
+var _ reconciler.Reconciler = &ClusterStopReconciler{}
+
+// ClusterStopReconciler stops the cluster gracefully when `clusterOperation.stopped` is set: the namenodes enter
+// safe mode and save the namespace, then the datanodes, the namenodes and the journalnodes are stopped one after
+// another. The phase is recorded in the status, the roles read the stopped roles from it, see common.IsRoleStopped.
+// It is registered before the roles, a new phase requeues the reconcile so the roles are rebuilt for it.
+// On start, the roles are started in the reverse order by the bring-up gates.
+type ClusterStopReconciler struct {
+	client      *pkgclient.Client
+	instance    *hdfsv1alpha1.HdfsCluster
+	clusterInfo reconciler.ClusterInfo
+	image       *util.Image
+	recorder    *EventRecorder
+}
+
+func NewClusterStopReconciler(
+	client *pkgclient.Client,
+	instance *hdfsv1alpha1.HdfsCluster,
+	clusterInfo reconciler.ClusterInfo,
+	image *util.Image,
+	recorder *EventRecorder,
+) *ClusterStopReconciler {
+	return &ClusterStopReconciler{
+		client:      client,
+		instance:    instance,
+		clusterInfo: clusterInfo,
+		image:       image,
+		recorder:    recorder,
+	}
+}
+
+func (r *ClusterStopReconciler) GetName() string {
+	return r.instance.Name + "-stop"
+}
+
+func (r *ClusterStopReconciler) GetNamespace() string {
+	return r.instance.Namespace
+}
+
+func (r *ClusterStopReconciler) GetClient() *pkgclient.Client {
+	return r.client
+}
+
+func (r *ClusterStopReconciler) stopped() bool {
+	return r.instance.Spec.ClusterOperationSpec != nil && r.instance.Spec.ClusterOperationSpec.Stopped
+}
+
+func (r *ClusterStopReconciler) Reconcile(ctx context.Context) (ctrl.Result, error) {
+	if r.stopped() {
+		return r.stop(ctx)
+	}
+	return r.start(ctx)
+}
+
+// Ready requeues while a role is stopping, the phase moves on once its pods are gone
+func (r *ClusterStopReconciler) Ready(ctx context.Context) (ctrl.Result, error) {
+	current := r.instance.Status.Stop
+	if !r.stopped() || current == nil {
+		return ctrl.Result{}, nil
+	}
+	switch current.Phase {
+	case hdfsv1alpha1.StopPhaseDataNodes, hdfsv1alpha1.StopPhaseNameNodes, hdfsv1alpha1.StopPhaseJournalNodes:
+		return ctrl.Result{RequeueAfter: clusterStopRequeueAfter}, nil
+	default:
+		return ctrl.Result{}, nil
+	}
+}
+
+func (r *ClusterStopReconciler) stop(ctx context.Context) (ctrl.Result, error) {
+	current := r.instance.Status.Stop
+	if current == nil || current.Phase == hdfsv1alpha1.StopPhaseLeavingSafeMode {
+		r.recorder.Normal(r.instance, EventReasonClusterStopping, "Stop", "Stopping the cluster")
+		return r.transition(ctx, hdfsv1alpha1.StopPhaseSavingNamespace)
+	}
+	switch current.Phase {
+	case hdfsv1alpha1.StopPhaseSavingNamespace:
+		return r.saveNamespace(ctx)
+	case hdfsv1alpha1.StopPhaseDataNodes:
+		return r.stopRole(ctx, constant.DataNode, hdfsv1alpha1.StopPhaseNameNodes)
+	case hdfsv1alpha1.StopPhaseNameNodes:
+		return r.stopRole(ctx, constant.NameNode, hdfsv1alpha1.StopPhaseJournalNodes)
+	case hdfsv1alpha1.StopPhaseJournalNodes:
+		return r.stopRole(ctx, constant.JournalNode, hdfsv1alpha1.StopPhaseStopped)
+	default:
+		return ctrl.Result{}, nil
+	}
+}
+
+// saveNamespace runs a job saving the namespace, so the namenodes do not replay the edit log on start.
+// The edits are kept by the journalnodes, a failed save does not hold back the stop.
+func (r *ClusterStopReconciler) saveNamespace(ctx context.Context) (ctrl.Result, error) {
+	running, err := r.runningPods(ctx, constant.NameNode)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if running == 0 {
+		clusterStopLog.Info("No namenode is running, the namespace is not saved", "cluster", r.instance.Name)
+		return r.transition(ctx, hdfsv1alpha1.StopPhaseDataNodes)
+	}
+
+	job, err := r.runJob(ctx, saveNamespaceContainerName, saveNamespaceScriptTemplate)
+	if err != nil || job == nil {
+		return ctrl.Result{RequeueAfter: clusterStopRequeueAfter}, err
+	}
+	if finished, failure := jobFinished(job); !finished {
+		return ctrl.Result{RequeueAfter: clusterStopRequeueAfter}, r.setPhase(ctx, hdfsv1alpha1.StopPhaseSavingNamespace,
+			fmt.Sprintf("waiting for job %s", job.Name))
+	} else if failure != "" {
+		r.recorder.Warning(r.instance, EventReasonSaveNamespaceFailed, "Stop",
+			"Job %s failed to save the namespace, the namenodes replay the edit log on start: %s", job.Name, failure)
+	} else {
+		clusterStopLog.Info("Saved the namespace", "cluster", r.instance.Name, "job", job.Name)
+	}
+	return r.transition(ctx, hdfsv1alpha1.StopPhaseDataNodes)
+}
+
+// stopRole moves on to the next phase once the pods of the role are gone. The role itself is scaled to zero
+// by its reconciler, which runs after this one.
+func (r *ClusterStopReconciler) stopRole(ctx context.Context, role constant.Role, next hdfsv1alpha1.StopPhase) (ctrl.Result, error) {
+	pods, err := rolePods(ctx, r.client.Client, r.instance, role)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(pods) > 0 {
+		return ctrl.Result{}, r.setPhase(ctx, r.instance.Status.Stop.Phase, fmt.Sprintf("%d %s pods running", len(pods), role))
+	}
+	if next == hdfsv1alpha1.StopPhaseStopped {
+		r.recorder.Normal(r.instance, EventReasonClusterStopped, "Stop", "Stopped the cluster")
+	}
+	return r.transition(ctx, next)
+}
+
+// start resumes a stopped or stopping cluster. While a namenode is running, the namenodes leave the safe mode
+// they may have entered for the stop. Otherwise the bring-up is restarted, so the roles start in order.
+func (r *ClusterStopReconciler) start(ctx context.Context) (ctrl.Result, error) {
+	current := r.instance.Status.Stop
+	if current == nil {
+		return ctrl.Result{}, nil
+	}
+	switch current.Phase {
+	case hdfsv1alpha1.StopPhaseSavingNamespace, hdfsv1alpha1.StopPhaseDataNodes:
+		return r.transition(ctx, hdfsv1alpha1.StopPhaseLeavingSafeMode)
+	case hdfsv1alpha1.StopPhaseLeavingSafeMode:
+		return r.leaveSafeMode(ctx)
+	case hdfsv1alpha1.StopPhaseNameNodes:
+		// the namenodes which are still running are in the safe mode entered for the stop
+		running, err := r.runningPods(ctx, constant.NameNode)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if running > 0 {
+			return r.transition(ctx, hdfsv1alpha1.StopPhaseLeavingSafeMode)
+		}
+		return r.restartBringUp(ctx)
+	default:
+		return r.restartBringUp(ctx)
+	}
+}
+
+// restartBringUp resets the stop and starts the roles in order with a new bring-up
+func (r *ClusterStopReconciler) restartBringUp(ctx context.Context) (ctrl.Result, error) {
+	r.recorder.Normal(r.instance, EventReasonClusterStarting, "Start", "Starting the cluster")
+	r.instance.Status.BringUp = nil
+	setBringUpPhase(r.instance, hdfsv1alpha1.BringUpPhaseJournalNodes, "")
+	r.instance.Status.Stop = nil
+	if err := r.client.Client.Status().Update(ctx, r.instance); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: clusterStopPhaseRequeueAfter}, nil
+}
+
+func (r *ClusterStopReconciler) leaveSafeMode(ctx context.Context) (ctrl.Result, error) {
+	running, err := r.runningPods(ctx, constant.NameNode)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if running > 0 {
+		job, err := r.runJob(ctx, leaveSafeModeContainerName, leaveSafeModeScriptTemplate)
+		if err != nil || job == nil {
+			return ctrl.Result{RequeueAfter: clusterStopRequeueAfter}, err
+		}
+		if finished, failure := jobFinished(job); !finished {
+			return ctrl.Result{RequeueAfter: clusterStopRequeueAfter}, r.setPhase(ctx, hdfsv1alpha1.StopPhaseLeavingSafeMode,
+				fmt.Sprintf("waiting for job %s", job.Name))
+		} else if failure != "" {
+			r.recorder.Warning(r.instance, EventReasonLeaveSafeModeFailed, "Start",
+				"Job %s failed to leave the safe mode, run `hdfs dfsadmin -safemode leave`: %s", job.Name, failure)
+		}
+	}
+
+	r.recorder.Normal(r.instance, EventReasonClusterStarting, "Start", "Cancelled the stop of the cluster")
+	r.instance.Status.Stop = nil
+	if err := r.client.Client.Status().Update(ctx, r.instance); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: clusterStopPhaseRequeueAfter}, nil
+}
+
+// runJob returns the job of the current phase, it is created if it does not exist and nil is returned
+func (r *ClusterStopReconciler) runJob(ctx context.Context, container string, script string) (*batchv1.Job, error) {
+	// the jobs are kept after they finished, the start of the phase tells the jobs of two stops apart
+	name := fmt.Sprintf("%s-%s-%d", r.instance.Name, container, r.instance.Status.Stop.LastTransitionTime.Unix())
+	job := &batchv1.Job{}
+	if err := r.client.Get(ctx, ctrlclient.ObjectKey{Namespace: r.GetNamespace(), Name: name}, job); err == nil {
+		return job, nil
+	} else if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	ldapProvider, err := common.ResolveLdapGroupMapping(ctx, r.client, r.instance.Spec.ClusterConfig)
+	if err != nil {
+		return nil, err
+	}
+	adminJob := &nameNodeAdminJob{
+		instance:    r.instance,
+		clusterInfo: r.clusterInfo,
+		image:       r.image,
+		container:   container,
+	}
+	clusterStopLog.Info("Creating job", "cluster", r.instance.Name, "job", name)
+	job, err = adminJob.build(name, nil, script, nil, ldapProvider)
+	if err != nil {
+		return nil, err
+	}
+	return nil, r.client.CreateDoesNotExist(ctx, job)
+}
+
+// jobFinished reports whether the job finished, the failure is the message of a failed job
+func jobFinished(job *batchv1.Job) (bool, string) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return true, ""
+		case batchv1.JobFailed:
+			return true, condition.Message
+		}
+	}
+	return false, ""
+}
+
+func (r *ClusterStopReconciler) runningPods(ctx context.Context, role constant.Role) (int, error) {
+	pods, err := rolePods(ctx, r.client.Client, r.instance, role)
+	if err != nil {
+		return 0, err
+	}
+	running := 0
+	for i := range pods {
+		if podRunning(&pods[i]) {
+			running++
+		}
+	}
+	return running, nil
+}
+
+// transition records the next phase and requeues, so the roles are rebuilt with the roles stopped in it
+func (r *ClusterStopReconciler) transition(ctx context.Context, phase hdfsv1alpha1.StopPhase) (ctrl.Result, error) {
+	clusterStopLog.Info("Stop phase", "cluster", r.instance.Name, "phase", phase)
+	if err := r.setPhase(ctx, phase, ""); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: clusterStopPhaseRequeueAfter}, nil
+}
+
+// setPhase records the phase in the status, the transition time is kept while the phase does not change
+func (r *ClusterStopReconciler) setPhase(ctx context.Context, phase hdfsv1alpha1.StopPhase, message string) error {
+	current := r.instance.Status.Stop
+	if current != nil && current.Phase == phase && current.Message == message {
+		return nil
+	}
+	transitionTime := metav1.Now()
+	if current != nil && current.Phase == phase && current.LastTransitionTime != nil {
+		transitionTime = *current.LastTransitionTime
+	}
+	r.instance.Status.Stop = &hdfsv1alpha1.StopStatus{
+		Phase:              phase,
+		Message:            message,
+		LastTransitionTime: &transitionTime,
+	}
+	return r.client.Client.Status().Update(ctx, r.instance)
+}
@@ -0,0 +1,243 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/constant"
+	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestClusterStopReconciler(t *testing.T) {
+	// the start of the phase names the jobs of the phase
+	phaseStart := time.Unix(1700000000, 0)
+	saveNamespaceJob := fmt.Sprintf("hdfs-save-namespace-%d", phaseStart.Unix())
+	leaveSafeModeJob := fmt.Sprintf("hdfs-leave-safe-mode-%d", phaseStart.Unix())
+
+	tests := []struct {
+		name    string
+		stopped bool
+		// phase is the phase of the stop, there is no stop in the status if it is empty
+		phase   hdfsv1alpha1.StopPhase
+		objects []ctrlclient.Object
+		// wantPhase is the phase after the reconcile, the stop is removed from the status if it is empty
+		wantPhase   hdfsv1alpha1.StopPhase
+		wantMessage string
+		wantJob     string
+		// wantBringUp reports whether the bring-up is restarted
+		wantBringUp bool
+		wantEvents  []string
+	}{
+		{
+			name:       "stop requested",
+			stopped:    true,
+			wantPhase:  hdfsv1alpha1.StopPhaseSavingNamespace,
+			wantEvents: []string{EventReasonClusterStopping},
+		},
+		{
+			name:      "saving the namespace",
+			stopped:   true,
+			phase:     hdfsv1alpha1.StopPhaseSavingNamespace,
+			objects:   []ctrlclient.Object{testPod(constant.NameNode, 0, phaseStart)},
+			wantPhase: hdfsv1alpha1.StopPhaseSavingNamespace,
+			wantJob:   saveNamespaceJob,
+		},
+		{
+			name:    "waiting for the namespace to be saved",
+			stopped: true,
+			phase:   hdfsv1alpha1.StopPhaseSavingNamespace,
+			objects: []ctrlclient.Object{
+				testPod(constant.NameNode, 0, phaseStart),
+				testJob(saveNamespaceJob, false, time.Time{}),
+			},
+			wantPhase:   hdfsv1alpha1.StopPhaseSavingNamespace,
+			wantMessage: "waiting for job " + saveNamespaceJob,
+			wantJob:     saveNamespaceJob,
+		},
+		{
+			name:    "namespace saved",
+			stopped: true,
+			phase:   hdfsv1alpha1.StopPhaseSavingNamespace,
+			objects: []ctrlclient.Object{
+				testPod(constant.NameNode, 0, phaseStart),
+				testJob(saveNamespaceJob, false, phaseStart.Add(time.Minute)),
+			},
+			wantPhase: hdfsv1alpha1.StopPhaseDataNodes,
+			wantJob:   saveNamespaceJob,
+		},
+		{
+			name:    "failed save does not hold back the stop",
+			stopped: true,
+			phase:   hdfsv1alpha1.StopPhaseSavingNamespace,
+			objects: []ctrlclient.Object{
+				testPod(constant.NameNode, 0, phaseStart),
+				testJob(saveNamespaceJob, true, phaseStart.Add(time.Minute)),
+			},
+			wantPhase:  hdfsv1alpha1.StopPhaseDataNodes,
+			wantJob:    saveNamespaceJob,
+			wantEvents: []string{EventReasonSaveNamespaceFailed},
+		},
+		{
+			name:      "no running namenode to save the namespace",
+			stopped:   true,
+			phase:     hdfsv1alpha1.StopPhaseSavingNamespace,
+			wantPhase: hdfsv1alpha1.StopPhaseDataNodes,
+		},
+		{
+			name:        "stopping the datanodes",
+			stopped:     true,
+			phase:       hdfsv1alpha1.StopPhaseDataNodes,
+			objects:     []ctrlclient.Object{testPod(constant.DataNode, 0, phaseStart)},
+			wantPhase:   hdfsv1alpha1.StopPhaseDataNodes,
+			wantMessage: "1 datanode pods running",
+		},
+		{
+			name:      "datanodes stopped",
+			stopped:   true,
+			phase:     hdfsv1alpha1.StopPhaseDataNodes,
+			objects:   []ctrlclient.Object{testPod(constant.NameNode, 0, phaseStart)},
+			wantPhase: hdfsv1alpha1.StopPhaseNameNodes,
+		},
+		{
+			name:       "journalnodes stopped",
+			stopped:    true,
+			phase:      hdfsv1alpha1.StopPhaseJournalNodes,
+			wantPhase:  hdfsv1alpha1.StopPhaseStopped,
+			wantEvents: []string{EventReasonClusterStopped},
+		},
+		{
+			name:      "stopped",
+			stopped:   true,
+			phase:     hdfsv1alpha1.StopPhaseStopped,
+			wantPhase: hdfsv1alpha1.StopPhaseStopped,
+		},
+		{
+			name:      "stop cancelled while saving the namespace",
+			phase:     hdfsv1alpha1.StopPhaseSavingNamespace,
+			objects:   []ctrlclient.Object{testPod(constant.NameNode, 0, phaseStart)},
+			wantPhase: hdfsv1alpha1.StopPhaseLeavingSafeMode,
+		},
+		{
+			name:      "stop cancelled while stopping the datanodes",
+			phase:     hdfsv1alpha1.StopPhaseDataNodes,
+			objects:   []ctrlclient.Object{testPod(constant.NameNode, 0, phaseStart)},
+			wantPhase: hdfsv1alpha1.StopPhaseLeavingSafeMode,
+		},
+		{
+			name:      "stop cancelled while stopping the namenodes",
+			phase:     hdfsv1alpha1.StopPhaseNameNodes,
+			objects:   []ctrlclient.Object{testPod(constant.NameNode, 0, phaseStart)},
+			wantPhase: hdfsv1alpha1.StopPhaseLeavingSafeMode,
+		},
+		{
+			name:        "stop cancelled after the namenodes stopped",
+			phase:       hdfsv1alpha1.StopPhaseNameNodes,
+			wantBringUp: true,
+			wantEvents:  []string{EventReasonClusterStarting},
+		},
+		{
+			name:      "leaving the safe mode",
+			phase:     hdfsv1alpha1.StopPhaseLeavingSafeMode,
+			objects:   []ctrlclient.Object{testPod(constant.NameNode, 0, phaseStart)},
+			wantPhase: hdfsv1alpha1.StopPhaseLeavingSafeMode,
+			wantJob:   leaveSafeModeJob,
+		},
+		{
+			name:  "waiting for the safe mode to be left",
+			phase: hdfsv1alpha1.StopPhaseLeavingSafeMode,
+			objects: []ctrlclient.Object{
+				testPod(constant.NameNode, 0, phaseStart),
+				testJob(leaveSafeModeJob, false, time.Time{}),
+			},
+			wantPhase:   hdfsv1alpha1.StopPhaseLeavingSafeMode,
+			wantMessage: "waiting for job " + leaveSafeModeJob,
+			wantJob:     leaveSafeModeJob,
+		},
+		{
+			name:  "safe mode left",
+			phase: hdfsv1alpha1.StopPhaseLeavingSafeMode,
+			objects: []ctrlclient.Object{
+				testPod(constant.NameNode, 0, phaseStart),
+				testJob(leaveSafeModeJob, false, phaseStart.Add(time.Minute)),
+			},
+			wantJob:    leaveSafeModeJob,
+			wantEvents: []string{EventReasonClusterStarting},
+		},
+		{
+			name:  "failed to leave the safe mode",
+			phase: hdfsv1alpha1.StopPhaseLeavingSafeMode,
+			objects: []ctrlclient.Object{
+				testPod(constant.NameNode, 0, phaseStart),
+				testJob(leaveSafeModeJob, true, phaseStart.Add(time.Minute)),
+			},
+			wantJob:    leaveSafeModeJob,
+			wantEvents: []string{EventReasonLeaveSafeModeFailed, EventReasonClusterStarting},
+		},
+		{
+			name:        "started after the stop",
+			phase:       hdfsv1alpha1.StopPhaseStopped,
+			wantBringUp: true,
+			wantEvents:  []string{EventReasonClusterStarting},
+		},
+		{
+			name: "not stopped",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := newTestCluster(2, 1, 3)
+			instance.Status.BringUp = &hdfsv1alpha1.BringUpStatus{Phase: hdfsv1alpha1.BringUpPhaseCompleted}
+			if tt.phase != "" {
+				instance.Status.Stop = &hdfsv1alpha1.StopStatus{
+					Phase:              tt.phase,
+					LastTransitionTime: ptr.To(metav1.NewTime(phaseStart)),
+				}
+			}
+			if tt.stopped {
+				instance.Spec.ClusterOperationSpec = &commonsv1alpha1.ClusterOperationSpec{Stopped: true}
+			}
+			client := newFakeClient(t, instance, tt.objects...)
+			recorder, fakeRecorder := newTestRecorder()
+
+			stop := NewClusterStopReconciler(client, instance, testClusterInfo(instance), testImage, recorder)
+			if _, err := stop.Reconcile(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			status := getCluster(t, client).Status
+			var phase hdfsv1alpha1.StopPhase
+			var message string
+			if status.Stop != nil {
+				phase, message = status.Stop.Phase, status.Stop.Message
+			}
+			if phase != tt.wantPhase || message != tt.wantMessage {
+				t.Errorf("got phase %q with message %q, want %q with %q", phase, message, tt.wantPhase, tt.wantMessage)
+			}
+
+			wantBringUp := hdfsv1alpha1.BringUpPhaseCompleted
+			if tt.wantBringUp {
+				wantBringUp = hdfsv1alpha1.BringUpPhaseJournalNodes
+			}
+			if status.BringUp == nil || status.BringUp.Phase != wantBringUp {
+				t.Errorf("got bring-up %v, want phase %q", status.BringUp, wantBringUp)
+			}
+
+			for _, name := range []string{saveNamespaceJob, leaveSafeModeJob} {
+				if exists := jobExists(t, client, name); exists != (name == tt.wantJob) {
+					t.Errorf("job %s exists %v, want %v", name, exists, name == tt.wantJob)
+				}
+			}
+
+			if reasons := eventReasons(fakeRecorder); !slices.Equal(reasons, tt.wantEvents) {
+				t.Errorf("got events %v, want %v", reasons, tt.wantEvents)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package render
+
+import (
+	"io/fs"
+	"sync"
+
+	"emperror.dev/errors"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/defaulting"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	"github.com/zncdatadev/hdfs-operator/config/crd"
+)
+
+var (
+	schemasOnce sync.Once
+	schemas     map[schema.GroupVersionKind]*structuralschema.Structural
+	schemasErr  error
+)
+
+// applyDefaults applies the defaults of the CRD schema of the object, like the API server does when the
+// object is created. Objects of other kinds than the CRDs of the operator are left as they are.
+func applyDefaults(object *unstructured.Unstructured) error {
+	schemasOnce.Do(func() { schemas, schemasErr = loadSchemas() })
+	if schemasErr != nil {
+		return schemasErr
+	}
+	if structural, ok := schemas[object.GroupVersionKind()]; ok {
+		defaulting.Default(object.Object, structural)
+	}
+	return nil
+}
+
+// loadSchemas reads the structural schemas of the versions of the embedded CRDs
+func loadSchemas() (map[schema.GroupVersionKind]*structuralschema.Structural, error) {
+	files, err := fs.Glob(crd.Bases, "bases/*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[schema.GroupVersionKind]*structuralschema.Structural)
+	for _, file := range files {
+		data, err := crd.Bases.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		definition := &apiextensionsv1.CustomResourceDefinition{}
+		if err := yaml.Unmarshal(data, definition); err != nil {
+			return nil, errors.WrapIfWithDetails(err, "failed to decode CRD", "file", file)
+		}
+		for _, version := range definition.Spec.Versions {
+			if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+				continue
+			}
+			props := &apiextensions.JSONSchemaProps{}
+			if err := apiextensionsv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(
+				version.Schema.OpenAPIV3Schema, props, nil); err != nil {
+				return nil, errors.WrapIfWithDetails(err, "failed to convert CRD schema", "file", file, "version", version.Name)
+			}
+			structural, err := structuralschema.NewStructural(props)
+			if err != nil {
+				return nil, errors.WrapIfWithDetails(err, "failed to read CRD schema", "file", file, "version", version.Name)
+			}
+			gvk := schema.GroupVersionKind{
+				Group:   definition.Spec.Group,
+				Version: version.Name,
+				Kind:    definition.Spec.Names.Kind,
+			}
+			result[gvk] = structural
+		}
+	}
+	return result, nil
+}
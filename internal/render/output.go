@@ -0,0 +1,158 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"emperror.dev/errors"
+	"github.com/cisco-open/k8s-objectmatcher/patch"
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// diffContext is the number of unchanged lines around a change in a diff
+const diffContext = 3
+
+// Normalize removes the fields set by the API server and the annotation with the last applied object,
+// so rendered and live objects compare equal when the operator would not change them
+func Normalize(object *unstructured.Unstructured) {
+	for _, field := range [][]string{
+		{"metadata", "resourceVersion"},
+		{"metadata", "uid"},
+		{"metadata", "generation"},
+		{"metadata", "creationTimestamp"},
+		{"metadata", "managedFields"},
+		{"metadata", "annotations", patch.LastAppliedConfig},
+		{"status"},
+	} {
+		unstructured.RemoveNestedField(object.Object, field...)
+	}
+	if len(object.GetAnnotations()) == 0 {
+		unstructured.RemoveNestedField(object.Object, "metadata", "annotations")
+	}
+	// the cluster IP is allocated by the API server and kept by the operator
+	if object.GetKind() == "Service" {
+		unstructured.RemoveNestedField(object.Object, "spec", "clusterIP")
+		unstructured.RemoveNestedField(object.Object, "spec", "clusterIPs")
+	}
+}
+
+// Write writes the objects as YAML documents
+func Write(w io.Writer, objects []*unstructured.Unstructured) error {
+	for _, object := range objects {
+		document, err := yaml.Marshal(object.Object)
+		if err != nil {
+			return errors.WrapIfWithDetails(err, "failed to marshal object", "object", Key(object))
+		}
+		if _, err := fmt.Fprintf(w, "---\n%s", document); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Diff writes a unified diff of each rendered object against the live object with the same kind and name,
+// it reports whether an object differs. A live object is compared as the operator last applied it, which
+// is kept in an annotation, so the fields defaulted by the API server do not show up in the diff. A live
+// object which is not rendered is diffed against an empty object.
+func Diff(w io.Writer, rendered []*unstructured.Unstructured, live []*unstructured.Unstructured) (bool, error) {
+	liveObjects := make(map[string]*unstructured.Unstructured, len(live))
+	for _, object := range live {
+		liveObjects[Key(object)] = object
+	}
+	renderedKeys := make(map[string]bool, len(rendered))
+
+	changed := false
+	for _, object := range rendered {
+		key := Key(object)
+		renderedKeys[key] = true
+		var from *unstructured.Unstructured
+		if liveObject, ok := liveObjects[key]; ok {
+			applied, err := lastApplied(liveObject)
+			if err != nil {
+				return false, err
+			}
+			from = applied
+		}
+		differs, err := writeDiff(w, key, from, object)
+		if err != nil {
+			return false, err
+		}
+		changed = changed || differs
+	}
+	for _, object := range live {
+		key := Key(object)
+		if renderedKeys[key] {
+			continue
+		}
+		applied, err := lastApplied(object)
+		if err != nil {
+			return false, err
+		}
+		differs, err := writeDiff(w, key, applied, nil)
+		if err != nil {
+			return false, err
+		}
+		changed = changed || differs
+	}
+	return changed, nil
+}
+
+// writeDiff writes the unified diff of the live and the rendered object with the key, a missing object is
+// nil. It reports whether the objects differ.
+func writeDiff(w io.Writer, key string, live *unstructured.Unstructured, rendered *unstructured.Unstructured) (bool, error) {
+	from, err := marshal(key, live)
+	if err != nil {
+		return false, err
+	}
+	to, err := marshal(key, rendered)
+	if err != nil {
+		return false, err
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(from)),
+		B:        difflib.SplitLines(string(to)),
+		FromFile: "live/" + key,
+		ToFile:   "rendered/" + key,
+		Context:  diffContext,
+	})
+	if err != nil || diff == "" {
+		return false, err
+	}
+	if _, err := io.WriteString(w, diff); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// marshal returns the YAML of the object, it is empty if the object is nil
+func marshal(key string, object *unstructured.Unstructured) ([]byte, error) {
+	if object == nil {
+		return nil, nil
+	}
+	data, err := yaml.Marshal(object.Object)
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "failed to marshal object", "object", key)
+	}
+	return data, nil
+}
+
+// lastApplied returns the normalized object the operator last applied, or the normalized live object
+// when it has no last applied annotation
+func lastApplied(object *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	original, err := patch.DefaultAnnotator.GetOriginalConfiguration(object)
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "failed to read the last applied object", "object", Key(object))
+	}
+	applied := object.DeepCopy()
+	if original != nil {
+		applied = &unstructured.Unstructured{}
+		if err := json.Unmarshal(original, &applied.Object); err != nil {
+			return nil, errors.WrapIfWithDetails(err, "failed to decode the last applied object", "object", Key(object))
+		}
+	}
+	Normalize(applied)
+	return applied, nil
+}
@@ -0,0 +1,334 @@
+// Package render renders the objects the operator manages for a HdfsCluster without a Kubernetes cluster.
+// The cluster reconciler runs with all its builders against a fake client, the objects it creates are the
+// rendered objects. The gates of the bring-up and the jobs which wait for running pods do not progress,
+// so the cluster is rendered as if its bring-up was completed.
+package render
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"emperror.dev/errors"
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/common"
+	"github.com/zncdatadev/hdfs-operator/internal/controller"
+	authv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/authentication/v1alpha1"
+	listenerv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/listeners/v1alpha1"
+	s3v1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/s3/v1alpha1"
+	resourceClient "github.com/zncdatadev/operator-go/pkg/client"
+	opconstants "github.com/zncdatadev/operator-go/pkg/constants"
+	"github.com/zncdatadev/operator-go/pkg/reconciler"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/yaml"
+)
+
+// maxReconciles bounds the reconciles of a render. A reconcile requeues after each object it creates or updates,
+// the disk balancer and the rolling restart requeue until jobs and pods progress, which never happens here.
+const maxReconciles = 200
+
+// renderUID is the UID of a cluster without one, the owner references of the objects are merged by UID
+const renderUID = types.UID("00000000-0000-0000-0000-000000000000")
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(authv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(hdfsv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(listenerv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(s3v1alpha1.AddToScheme(scheme))
+}
+
+// Kinds are the kinds of the objects the operator creates for a cluster, in the order they are rendered
+var Kinds = []schema.GroupVersionKind{
+	{Version: "v1", Kind: "ServiceAccount"},
+	{Version: "v1", Kind: "ConfigMap"},
+	{Version: "v1", Kind: "Service"},
+	{Group: "listeners.kubedoop.dev", Version: "v1alpha1", Kind: "Listener"},
+	{Group: "apps", Version: "v1", Kind: "StatefulSet"},
+	{Group: "policy", Version: "v1", Kind: "PodDisruptionBudget"},
+	{Group: "batch", Version: "v1", Kind: "CronJob"},
+	{Group: "batch", Version: "v1", Kind: "Job"},
+	{Group: "monitoring.coreos.com", Version: "v1", Kind: common.ServiceMonitorKind},
+	{Group: "monitoring.coreos.com", Version: "v1", Kind: common.PrometheusRuleKind},
+}
+
+// Load reads the input of a render: a HdfsCluster and the objects it references, like the ConfigMap of
+// ZooKeeper, AuthenticationClasses or S3 connections, as YAML documents. Objects without a namespace
+// are put in the namespace of the cluster, which defaults to namespace.
+func Load(reader io.Reader, namespace string) (*hdfsv1alpha1.HdfsCluster, []ctrlclient.Object, error) {
+	documents := utilyaml.NewYAMLReader(bufio.NewReader(reader))
+
+	var cluster *hdfsv1alpha1.HdfsCluster
+	var objects []ctrlclient.Object
+	for {
+		document, err := documents.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, errors.WrapIf(err, "failed to read the input")
+		}
+		if strings.TrimSpace(string(document)) == "" {
+			continue
+		}
+		decoded, err := decode(document)
+		if err != nil {
+			return nil, nil, err
+		}
+		if instance, ok := decoded.(*hdfsv1alpha1.HdfsCluster); ok {
+			if cluster != nil {
+				return nil, nil, errors.NewWithDetails("the input has more than one HdfsCluster",
+					"name", cluster.Name, "other", instance.Name)
+			}
+			cluster = instance
+			continue
+		}
+		object, ok := decoded.(ctrlclient.Object)
+		if !ok {
+			return nil, nil, errors.Errorf("unsupported object %T in the input", decoded)
+		}
+		objects = append(objects, object)
+	}
+	if cluster == nil {
+		return nil, nil, errors.New("the input has no HdfsCluster")
+	}
+
+	if cluster.Namespace == "" {
+		cluster.Namespace = namespace
+	}
+	for _, object := range objects {
+		if object.GetNamespace() == "" {
+			object.SetNamespace(cluster.Namespace)
+		}
+	}
+	return cluster, objects, nil
+}
+
+// decode decodes a YAML document to a typed object with the defaults of its CRD applied
+func decode(document []byte) (runtime.Object, error) {
+	object := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal(document, &object.Object); err != nil {
+		return nil, errors.WrapIf(err, "failed to decode the input")
+	}
+	if err := applyDefaults(object); err != nil {
+		return nil, err
+	}
+	typed, err := scheme.New(object.GroupVersionKind())
+	if err != nil {
+		return nil, errors.WrapIfWithDetails(err, "unsupported object in the input", "kind", object.GetKind(), "name", object.GetName())
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, typed); err != nil {
+		return nil, errors.WrapIfWithDetails(err, "failed to decode the input", "kind", object.GetKind(), "name", object.GetName())
+	}
+	typed.GetObjectKind().SetGroupVersionKind(object.GroupVersionKind())
+	return typed, nil
+}
+
+// Render reconciles the cluster against a fake client seeded with the objects and returns the objects the
+// operator creates for it. The objects referenced by the cluster which are not in objects are read with
+// live, if it is not nil. The rendered objects are normalized, see Normalize.
+func Render(
+	ctx context.Context,
+	cluster *hdfsv1alpha1.HdfsCluster,
+	objects []ctrlclient.Object,
+	live ctrlclient.Reader,
+) ([]*unstructured.Unstructured, error) {
+	cluster = cluster.DeepCopy()
+	if cluster.UID == "" {
+		cluster.UID = renderUID
+	}
+	if cluster.Status.BringUp == nil {
+		cluster.Status.BringUp = &hdfsv1alpha1.BringUpStatus{Phase: hdfsv1alpha1.BringUpPhaseCompleted}
+	}
+
+	client := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(cluster).
+		WithObjects(objects...).
+		WithStatusSubresource(&hdfsv1alpha1.HdfsCluster{}).
+		WithInterceptorFuncs(interceptor.Funcs{Get: get(cluster, live)}).
+		Build()
+
+	var previous map[string]string
+	for range maxReconciles {
+		requeue, err := reconcile(ctx, client, ctrlclient.ObjectKeyFromObject(cluster))
+		if err != nil {
+			return nil, err
+		}
+		all, err := list(ctx, client, cluster.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		current := versions(all)
+		if requeue && (previous == nil || !maps.Equal(previous, current)) {
+			previous = current
+			continue
+		}
+		rendered, err := Objects(ctx, client, cluster)
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range rendered {
+			Normalize(object)
+		}
+		return rendered, nil
+	}
+	return nil, errors.NewWithDetails("the cluster did not settle", "reconciles", maxReconciles)
+}
+
+// reconcile runs a reconcile of the cluster like the HdfsClusterReconciler, it reports whether it requeued.
+// A paused cluster is rendered like a reconciled one.
+func reconcile(ctx context.Context, client ctrlclient.Client, key ctrlclient.ObjectKey) (bool, error) {
+	instance := &hdfsv1alpha1.HdfsCluster{}
+	if err := client.Get(ctx, key, instance); err != nil {
+		return false, err
+	}
+	gvk, err := apiutil.GVKForObject(instance, scheme)
+	if err != nil {
+		return false, err
+	}
+
+	clusterReconciler := controller.NewClusterReconciler(
+		&resourceClient.Client{Client: client, OwnerReference: instance},
+		reconciler.ClusterInfo{
+			GVK: &metav1.GroupVersionKind{
+				Group:   gvk.Group,
+				Version: gvk.Version,
+				Kind:    gvk.Kind,
+			},
+			ClusterName: instance.Name,
+		},
+		instance,
+		nil,
+	)
+	if err := clusterReconciler.RegisterResources(ctx); err != nil {
+		return false, errors.WrapIf(err, "failed to register the resources")
+	}
+	result, err := clusterReconciler.Reconcile(ctx)
+	if err != nil {
+		return false, errors.WrapIf(err, "failed to reconcile the resources")
+	}
+	return !result.IsZero(), nil
+}
+
+// get reads the objects missing in the fake client with live, if it is not nil, the objects managed for the
+// cluster are not read, they are rendered. The pods and listeners still missing are simulated, see simulate.
+func get(cluster *hdfsv1alpha1.HdfsCluster, live ctrlclient.Reader) func(
+	context.Context, ctrlclient.WithWatch, ctrlclient.ObjectKey, ctrlclient.Object, ...ctrlclient.GetOption) error {
+	return func(ctx context.Context, client ctrlclient.WithWatch, key ctrlclient.ObjectKey, obj ctrlclient.Object,
+		opts ...ctrlclient.GetOption) error {
+		err := client.Get(ctx, key, obj, opts...)
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		if live != nil {
+			if found, liveErr := liveGet(ctx, cluster, live, client, key, obj); liveErr != nil || found {
+				return liveErr
+			}
+		}
+		if simulated, simulateErr := simulate(ctx, client, cluster.Spec.ClusterConfig.ClusterDomain, key, obj); simulateErr != nil || simulated {
+			return simulateErr
+		}
+		return err
+	}
+}
+
+// liveGet reads the object with live into obj and seeds the fake client with it, it reports whether it was found
+func liveGet(
+	ctx context.Context,
+	cluster *hdfsv1alpha1.HdfsCluster,
+	live ctrlclient.Reader,
+	client ctrlclient.Client,
+	key ctrlclient.ObjectKey,
+	obj ctrlclient.Object,
+) (bool, error) {
+	liveObj := obj.DeepCopyObject().(ctrlclient.Object)
+	if err := live.Get(ctx, key, liveObj); err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if managedFor(liveObj, cluster) {
+		return false, nil
+	}
+	liveObj.SetResourceVersion("")
+	if err := client.Create(ctx, liveObj); err != nil {
+		return false, err
+	}
+	return true, client.Get(ctx, key, obj)
+}
+
+// Objects lists the objects of the Kinds managed for the cluster, sorted by kind and name
+func Objects(ctx context.Context, reader ctrlclient.Reader, cluster *hdfsv1alpha1.HdfsCluster) ([]*unstructured.Unstructured, error) {
+	objects, err := list(ctx, reader, cluster.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(objects, func(object *unstructured.Unstructured) bool {
+		return !managedFor(object, cluster)
+	}), nil
+}
+
+// list lists the objects of the Kinds in the namespace, sorted by kind and name
+func list(ctx context.Context, reader ctrlclient.Reader, namespace string) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	for _, gvk := range Kinds {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := reader.List(ctx, list, ctrlclient.InNamespace(namespace)); err != nil {
+			// the resources of the Prometheus operator are only installed with it
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, errors.WrapIfWithDetails(err, "failed to list objects", "kind", gvk.Kind)
+		}
+		slices.SortFunc(list.Items, func(a, b unstructured.Unstructured) int { return strings.Compare(a.GetName(), b.GetName()) })
+		for i := range list.Items {
+			list.Items[i].SetGroupVersionKind(gvk)
+			objects = append(objects, &list.Items[i])
+		}
+	}
+	return objects, nil
+}
+
+// managedFor reports whether the operator manages the object for the cluster: the object is controlled by the
+// cluster, or it has no owner and the instance label of the cluster, like the PodDisruptionBudgets
+func managedFor(obj metav1.Object, cluster *hdfsv1alpha1.HdfsCluster) bool {
+	if owner := metav1.GetControllerOf(obj); owner != nil {
+		return owner.Kind == "HdfsCluster" && owner.Name == cluster.Name
+	}
+	return len(obj.GetOwnerReferences()) == 0 && obj.GetLabels()[opconstants.LabelKubernetesInstance] == cluster.Name
+}
+
+// versions are the resource versions of the objects by kind and name
+func versions(objects []*unstructured.Unstructured) map[string]string {
+	result := make(map[string]string, len(objects))
+	for _, object := range objects {
+		result[Key(object)] = object.GetResourceVersion()
+	}
+	return result
+}
+
+// Key identifies an object of a cluster by kind and name
+func Key(object *unstructured.Unstructured) string {
+	return object.GetKind() + "/" + object.GetName()
+}
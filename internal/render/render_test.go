@@ -0,0 +1,269 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cisco-open/k8s-objectmatcher/patch"
+	"github.com/go-logr/logr"
+	"github.com/pmezard/go-difflib/difflib"
+	hdfsv1alpha1 "github.com/zncdatadev/hdfs-operator/api/v1alpha1"
+	"github.com/zncdatadev/hdfs-operator/internal/common"
+	commonsv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/commons/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var update = flag.Bool("update", false, "update the golden files of the render tests")
+
+// TestRender renders each input of testdata and compares the objects with the golden file of the input.
+// Run with -update after a change of the rendered objects and review the changes of the golden files.
+func TestRender(t *testing.T) {
+	ctrl.SetLogger(logr.Discard())
+
+	inputs, err := filepath.Glob(filepath.Join("testdata", "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), ".yaml")
+		t.Run(name, func(t *testing.T) {
+			got := render(t, input)
+
+			golden := filepath.Join("testdata", name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+					A:        difflib.SplitLines(string(want)),
+					B:        difflib.SplitLines(string(got)),
+					FromFile: golden,
+					ToFile:   "rendered",
+					Context:  diffContext,
+				})
+				t.Errorf("rendered objects differ from %s, run the tests with -update if the change is expected:\n%s", golden, diff)
+			}
+		})
+	}
+}
+
+// TestDiff compares rendered objects with live objects the operator applied
+func TestDiff(t *testing.T) {
+	ctrl.SetLogger(logr.Discard())
+
+	file, err := os.Open(filepath.Join("testdata", "simple.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = file.Close() }()
+	cluster, objects, err := Load(file, "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered, err := Render(context.Background(), cluster, objects, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the live objects have fields set by the API server, the operator applied the rendered objects
+	live := make([]*unstructured.Unstructured, 0, len(rendered))
+	for _, object := range rendered {
+		liveObject := object.DeepCopy()
+		if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(liveObject); err != nil {
+			t.Fatal(err)
+		}
+		liveObject.SetResourceVersion("42")
+		if err := unstructured.SetNestedField(liveObject.Object, "Always", "spec", "defaulted"); err != nil {
+			t.Fatal(err)
+		}
+		live = append(live, liveObject)
+	}
+
+	out := &bytes.Buffer{}
+	differs, err := Diff(out, rendered, live)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if differs {
+		t.Fatalf("unchanged objects differ:\n%s", out)
+	}
+
+	changed := rendered[len(rendered)-1].DeepCopy()
+	changed.SetLabels(map[string]string{"changed": "true"})
+	differs, err = Diff(out, []*unstructured.Unstructured{changed}, live)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !differs || !strings.Contains(out.String(), "+    changed: \"true\"") {
+		t.Fatalf("changed object does not differ:\n%s", out)
+	}
+
+	// a live object which is no longer rendered is removed
+	out.Reset()
+	removed := Key(live[0])
+	differs, err = Diff(out, rendered[1:], live)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !differs || !strings.Contains(out.String(), "--- live/"+removed) {
+		t.Fatalf("live object %s is not reported:\n%s", removed, out)
+	}
+}
+
+// TestRoleConfig reconciles a role with and without a role config. The PodDisruptionBudget of the role
+// is only created when it is enabled, the JMX exporter agent reads the rules of the role from the role
+// group ConfigMap and is removed when it is disabled.
+func TestRoleConfig(t *testing.T) {
+	ctrl.SetLogger(logr.Discard())
+
+	rules := &hdfsv1alpha1.JmxExporterRulesSpec{Inline: "lowercaseOutputName: true\nrules: []\n"}
+	tests := []struct {
+		name       string
+		roleConfig *hdfsv1alpha1.RoleConfigSpec
+		wantPdb    bool
+		// wantJmxConfig is the JMX exporter config of the role group ConfigMap, it has none if it is empty
+		wantJmxConfig string
+		// wantJmxAgent is the config of the JMX exporter agent, there is no agent if it is empty
+		wantJmxAgent string
+	}{
+		{
+			name:         "without role config",
+			wantJmxAgent: "/kubedoop/jmx/namenode.yaml",
+		},
+		{
+			name:       "without pod disruption budget",
+			roleConfig: &hdfsv1alpha1.RoleConfigSpec{Metrics: &hdfsv1alpha1.MetricsSpec{}},
+			// the config of the image is used without rules
+			wantJmxAgent: "/kubedoop/jmx/namenode.yaml",
+		},
+		{
+			name: "with pod disruption budget",
+			roleConfig: &hdfsv1alpha1.RoleConfigSpec{
+				RoleConfigSpec: commonsv1alpha1.RoleConfigSpec{
+					PodDisruptionBudget: &commonsv1alpha1.PodDisruptionBudgetSpec{Enabled: true, MaxUnavailable: ptr.To[int32](1)},
+				},
+			},
+			wantPdb:      true,
+			wantJmxAgent: "/kubedoop/jmx/namenode.yaml",
+		},
+		{
+			name:          "with jmx exporter rules",
+			roleConfig:    &hdfsv1alpha1.RoleConfigSpec{Metrics: &hdfsv1alpha1.MetricsSpec{JmxExporterRules: rules}},
+			wantJmxConfig: rules.Inline,
+			wantJmxAgent:  "/kubedoop/mount/config/namenode/jmx-exporter.yaml",
+		},
+		{
+			name:       "without jmx exporter",
+			roleConfig: &hdfsv1alpha1.RoleConfigSpec{Metrics: &hdfsv1alpha1.MetricsSpec{JmxExporterEnabled: ptr.To(false)}},
+		},
+		{
+			name: "without jmx exporter with rules",
+			roleConfig: &hdfsv1alpha1.RoleConfigSpec{
+				Metrics: &hdfsv1alpha1.MetricsSpec{JmxExporterEnabled: ptr.To(false), JmxExporterRules: rules},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := os.Open(filepath.Join("testdata", "simple.yaml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = file.Close() }()
+			cluster, objects, err := Load(file, "default")
+			if err != nil {
+				t.Fatal(err)
+			}
+			cluster.Spec.NameNode.RoleConfig = tt.roleConfig
+
+			rendered, err := Render(context.Background(), cluster, objects, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pdb := false
+			var jmxConfig, jmxAgent string
+			for _, object := range rendered {
+				switch {
+				case object.GetKind() == "PodDisruptionBudget" && object.GetName() == cluster.Name+"-namenode":
+					pdb = true
+				case object.GetKind() == "ConfigMap" && object.GetName() == cluster.Name+"-namenode-default":
+					jmxConfig, _, _ = unstructured.NestedString(object.Object, "data", common.JmxExporterConfigFileName)
+				case object.GetKind() == "StatefulSet" && object.GetName() == cluster.Name+"-namenode-default":
+					jmxAgent = jmxExporterAgentConfig(t, object, "HDFS_NAMENODE_OPTS")
+				}
+			}
+			if pdb != tt.wantPdb {
+				t.Errorf("PodDisruptionBudget of the namenodes rendered: %t, want %t", pdb, tt.wantPdb)
+			}
+			if jmxConfig != tt.wantJmxConfig {
+				t.Errorf("got JMX exporter config %q in the ConfigMap of the namenodes, want %q", jmxConfig, tt.wantJmxConfig)
+			}
+			if jmxAgent != tt.wantJmxAgent {
+				t.Errorf("got JMX exporter agent config %q, want %q", jmxAgent, tt.wantJmxAgent)
+			}
+		})
+	}
+}
+
+// jmxExporterAgentConfig returns the config of the JMX exporter javaagent in the JVM options of the containers
+// of the StatefulSet, it is empty if there is no agent
+func jmxExporterAgentConfig(t *testing.T, statefulSet *unstructured.Unstructured, env string) string {
+	t.Helper()
+	containers, _, err := unstructured.NestedSlice(statefulSet.Object, "spec", "template", "spec", "containers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, container := range containers {
+		envs, _, _ := unstructured.NestedSlice(container.(map[string]interface{}), "env")
+		for _, envVar := range envs {
+			envVar := envVar.(map[string]interface{})
+			if envVar["name"] != env {
+				continue
+			}
+			for _, option := range strings.Fields(envVar["value"].(string)) {
+				if strings.HasPrefix(option, "-javaagent:") {
+					// -javaagent:<jar>=<port>:<config>
+					return option[strings.LastIndex(option, ":")+1:]
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func render(t *testing.T, input string) []byte {
+	t.Helper()
+	file, err := os.Open(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = file.Close() }()
+
+	cluster, objects, err := Load(file, "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered, err := Render(context.Background(), cluster, objects, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &bytes.Buffer{}
+	if err := Write(out, rendered); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
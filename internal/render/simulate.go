@@ -0,0 +1,92 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	listenerv1alpha1 "github.com/zncdatadev/operator-go/pkg/apis/listeners/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// simulatedListenerLabel is the label of a simulated pod with the name of its listener, like the label of
+// the listener volume the listener operator adds to the pods
+const simulatedListenerLabel = "listeners.kubedoop.dev/mnt.render"
+
+// simulate returns a pod or the listener of a pod of a rendered StatefulSet, which are created by Kubernetes and
+// the listener operator and do not exist in a render. The discovery reads the addresses of the namenodes from
+// them. The listener of a pod has the name of the pod and reports the address of the pod in the headless
+// Service of the StatefulSet in the cluster domain with the ports of its containers. It reports whether the
+// object was simulated.
+func simulate(
+	ctx context.Context,
+	client ctrlclient.Client,
+	clusterDomain string,
+	key ctrlclient.ObjectKey,
+	obj ctrlclient.Object,
+) (bool, error) {
+	switch obj.(type) {
+	case *corev1.Pod, *listenerv1alpha1.Listener:
+	default:
+		return false, nil
+	}
+	statefulSet, err := podStatefulSet(ctx, client, key)
+	if err != nil || statefulSet == nil {
+		return false, err
+	}
+
+	switch o := obj.(type) {
+	case *corev1.Pod:
+		*o = corev1.Pod{}
+		o.Name = key.Name
+		o.Namespace = key.Namespace
+		o.Labels = map[string]string{simulatedListenerLabel: key.Name}
+		for label, value := range statefulSet.Spec.Template.Labels {
+			o.Labels[label] = value
+		}
+		o.Spec = statefulSet.Spec.Template.Spec
+	case *listenerv1alpha1.Listener:
+		ports := make(map[string]int32)
+		for _, container := range statefulSet.Spec.Template.Spec.Containers {
+			for _, port := range container.Ports {
+				ports[port.Name] = port.ContainerPort
+			}
+		}
+		*o = listenerv1alpha1.Listener{}
+		o.Name = key.Name
+		o.Namespace = key.Namespace
+		o.Status.IngressAddresses = []listenerv1alpha1.IngressAddressSpec{{
+			Address:     fmt.Sprintf("%s.%s.%s.svc.%s", key.Name, statefulSet.Spec.ServiceName, key.Namespace, clusterDomain),
+			AddressType: listenerv1alpha1.AddressTypeHostname,
+			Ports:       ports,
+		}}
+	}
+	return true, nil
+}
+
+// podStatefulSet returns the StatefulSet the pod with the key belongs to by its name, nil if there is none
+func podStatefulSet(ctx context.Context, client ctrlclient.Client, key ctrlclient.ObjectKey) (*appsv1.StatefulSet, error) {
+	separator := strings.LastIndex(key.Name, "-")
+	if separator < 0 {
+		return nil, nil
+	}
+	ordinal, err := strconv.Atoi(key.Name[separator+1:])
+	if err != nil {
+		return nil, nil
+	}
+	statefulSet := &appsv1.StatefulSet{}
+	if err := client.Get(ctx, ctrlclient.ObjectKey{Namespace: key.Namespace, Name: key.Name[:separator]}, statefulSet); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if statefulSet.Spec.Replicas != nil && int32(ordinal) >= *statefulSet.Spec.Replicas {
+		return nil, nil
+	}
+	return statefulSet, nil
+}
@@ -3,6 +3,8 @@ package util
 import (
 	"bytes"
 	"encoding/xml"
+	"fmt"
+	"strings"
 )
 
 type XmlNameValuePair struct {
@@ -86,3 +88,30 @@ func Append(originXml string, properties []XmlNameValuePair) string {
 	}
 	return xmlDom.String(properties)
 }
+
+// Parse string to xml dom
+func Parse(content string) (*XmlConfiguration, error) {
+	var xmlDom XmlConfiguration
+	if err := xml.Unmarshal([]byte(content), &xmlDom); err != nil {
+		return nil, err
+	}
+	return &xmlDom, nil
+}
+
+// Include adds an `xi:include` of each href to the configuration, which hadoop resolves relative to the
+// configuration directory. A missing file is ignored. The includes are dropped by Append, so they are added last.
+func Include(content string, hrefs ...string) string {
+	if len(hrefs) == 0 {
+		return content
+	}
+	end := strings.LastIndex(content, "</configuration>")
+	if end < 0 {
+		panic("no configuration element in xml document")
+	}
+	var includes strings.Builder
+	for _, href := range hrefs {
+		fmt.Fprintf(&includes, "  <xi:include href=%q>\n    <xi:fallback/>\n  </xi:include>\n", href)
+	}
+	content = content[:end] + includes.String() + content[end:]
+	return strings.Replace(content, "<configuration>", `<configuration xmlns:xi="http://www.w3.org/2001/XInclude">`, 1)
+}
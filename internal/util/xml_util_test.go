@@ -71,3 +71,39 @@ func TestXmlConfiguration_Append(t *testing.T) {
 		})
 	}
 }
+
+func TestInclude(t *testing.T) {
+	const origin = `<?xml version="1.0" encoding="UTF-8"?>
+<configuration>
+  <property>
+    <name>key1</name>
+    <value>value1</value>
+  </property>
+</configuration>`
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<configuration xmlns:xi="http://www.w3.org/2001/XInclude">
+  <property>
+    <name>key1</name>
+    <value>value1</value>
+  </property>
+  <xi:include href="included.xml">
+    <xi:fallback/>
+  </xi:include>
+</configuration>`
+	if got := Include(origin, "included.xml"); got != want {
+		t.Errorf("Include() = %v, want %v", got, want)
+	}
+	if got := Include(origin); got != origin {
+		t.Errorf("Include() without hrefs = %v, want %v", got, origin)
+	}
+
+	// the properties are still parsed
+	dom, err := Parse(Include(origin, "included.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dom.Properties) != 1 || dom.Properties[0].Value != "value1" {
+		t.Errorf("Parse() = %v, want key1", dom.Properties)
+	}
+}